@@ -2,18 +2,23 @@ package cmd
 
 import (
 	"context"
+	"strings"
 
+	"github.com/freshly/tuber/graph"
 	"github.com/freshly/tuber/pkg/adminserver"
 	"github.com/freshly/tuber/pkg/core"
 	"github.com/freshly/tuber/pkg/events"
 	"github.com/freshly/tuber/pkg/oauth"
+	"github.com/freshly/tuber/pkg/pubsub"
+	"github.com/freshly/tuber/pkg/slack"
+	"github.com/freshly/tuber/pkg/webhook"
 	"github.com/gorilla/securecookie"
 	"go.uber.org/zap"
 
 	"github.com/spf13/viper"
 )
 
-func startAdminServer(ctx context.Context, db *core.DB, processor *events.Processor, logger *zap.Logger, creds []byte) {
+func startAdminServer(ctx context.Context, db *core.DB, processor *events.Processor, inbox *events.Inbox, localSources map[string]*pubsub.LocalSource, logger *zap.Logger, creds []byte) {
 	reviewAppsEnabled := viper.GetBool("TUBER_REVIEWAPPS_ENABLED")
 
 	triggersProjectName := viper.GetString("TUBER_REVIEW_APPS_TRIGGERS_PROJECT_NAME")
@@ -28,13 +33,17 @@ func startAdminServer(ctx context.Context, db *core.DB, processor *events.Proces
 		viper.GetString("TUBER_OAUTH_WEB_CLIENT_ID"),
 		viper.GetString("TUBER_OAUTH_STATE_KEY"),
 		viper.GetString("TUBER_ADMINSERVER_PREFIX"),
+		viper.GetString("TUBER_IAP_AUDIENCE"),
 	)
 	if len(viper.GetString("TUBER_COOKIE_BLOCK_KEY")) < 32 {
 		logger.Warn("starting admin server with TUBER_COOKIE_BLOCK_KEY set to a value under 32 characters. Use a 32 character value for aes-256.")
 	}
+	slackClient := slack.New(viper.GetString("TUBER_SLACK_TOKEN"), viper.GetBool("TUBER_SLACK_ENABLED"), viper.GetString("TUBER_SLACK_CATCHALL_CHANNEL"))
+	auditor := graph.NewAuditor(db, logger, slackClient, viper.GetString("TUBER_AUDIT_SLACK_CHANNEL"), auditRetention())
+
 	secureCookie := securecookie.New([]byte(viper.GetString("TUBER_COOKIE_HASH_KEY")), []byte(viper.GetString("TUBER_COOKIE_BLOCK_KEY")))
 
-	err := adminserver.Start(ctx, logger, db, processor, triggersProjectName, creds,
+	err := adminserver.Start(ctx, logger, db, processor, inbox, triggersProjectName, creds,
 		reviewAppsEnabled,
 		viper.GetString("TUBER_CLUSTER_DEFAULT_HOST"),
 		viper.GetString("TUBER_ADMINSERVER_PORT"),
@@ -44,9 +53,41 @@ func startAdminServer(ctx context.Context, db *core.DB, processor *events.Proces
 		viper.GetBool("TUBER_USE_DEVSERVER"),
 		auth,
 		secureCookie,
+		webhookSources(),
+		localSources,
+		viper.GetString("TUBER_SLACK_SIGNING_SECRET"),
+		slack.NewUsers(slackClient, slackUsers()),
+		auditor,
+		webhookHosts(),
 	)
 
 	if err != nil {
 		panic(err)
 	}
 }
+
+// slackUsers reads slack user id to identity overrides, e.g. TUBER_SLACK_USERS=U0123ABC=someone@example.com,U0456DEF=other@example.com
+func slackUsers() map[string]string {
+	users := make(map[string]string)
+	for _, pair := range strings.Split(viper.GetString("TUBER_SLACK_USERS"), ",") {
+		split := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(split) == 2 {
+			users[split[0]] = split[1]
+		}
+	}
+	return users
+}
+
+// webhookSources reads per-source webhook auth, e.g. TUBER_WEBHOOK_HARBOR_TOKEN or TUBER_WEBHOOK_GITHUB_SECRET
+func webhookSources() map[string]webhook.Source {
+	sources := make(map[string]webhook.Source)
+	for _, name := range webhook.SourceNames {
+		key := "TUBER_WEBHOOK_" + strings.ToUpper(name)
+		sources[name] = webhook.Source{
+			Secret:          viper.GetString(key + "_SECRET"),
+			SignatureHeader: viper.GetString(key + "_SIGNATURE_HEADER"),
+			Token:           viper.GetString(key + "_TOKEN"),
+		}
+	}
+	return sources
+}
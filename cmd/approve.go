@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/spf13/cobra"
+)
+
+var approveRejectFlag bool
+var approveReasonFlag string
+
+var approveCmd = &cobra.Command{
+	SilenceUsage: true,
+	Use:          "approve -a [app name]",
+	Short:        "approve the release awaiting approval for an app, or discard it with --reject",
+	Args:         cobra.NoArgs,
+	PreRunE:      promptCurrentContext,
+	RunE:         runApproveCmd,
+}
+
+func runApproveCmd(cmd *cobra.Command, args []string) error {
+	if appNameFlag == "" {
+		return fmt.Errorf("app name required, specify with -a or --app")
+	}
+
+	graphql, err := gqlClient()
+	if err != nil {
+		return err
+	}
+
+	input := &model.ReleaseDecisionInput{
+		AppName: appNameFlag,
+	}
+	if approveReasonFlag != "" {
+		input.Reason = &approveReasonFlag
+	}
+
+	mutation := "approveRelease"
+	if approveRejectFlag {
+		mutation = "rejectRelease"
+	}
+
+	gql := fmt.Sprintf(`
+		mutation($input: ReleaseDecisionInput!) {
+			%s(input: $input) {
+				appName
+				tag
+				requestedBy
+			}
+		}
+	`, mutation)
+
+	var respData map[string]*model.PendingRelease
+
+	err = graphql.Mutation(context.Background(), gql, nil, input, &respData)
+	if err != nil {
+		return err
+	}
+
+	pending := respData[mutation]
+	if pending == nil {
+		return fmt.Errorf("no release was awaiting approval")
+	}
+
+	if approveRejectFlag {
+		fmt.Printf("rejected release of %s requested by %s\n", pending.Tag, pending.RequestedBy)
+	} else {
+		fmt.Printf("approved release of %s requested by %s\n", pending.Tag, pending.RequestedBy)
+	}
+	return nil
+}
+
+func init() {
+	approveCmd.Flags().StringVarP(&appNameFlag, "app", "a", "", "app name")
+	approveCmd.Flags().BoolVar(&approveRejectFlag, "reject", false, "discard the release instead of approving it")
+	approveCmd.Flags().StringVar(&approveReasonFlag, "reason", "", "why, shown in slack")
+	rootCmd.AddCommand(approveCmd)
+}
@@ -1,12 +1,15 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
 
+	"github.com/fatih/color"
+	"github.com/freshly/tuber/graph/model"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 )
@@ -30,8 +33,19 @@ func runAppsInfoCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// workloads come live from the cluster, so they can fail when the app itself loads fine
+	workloads, workloadsErr := getWorkloads(appName)
+
 	if appsInfoJsonFlag {
-		out, err := json.Marshal(app)
+		var workloadsError string
+		if workloadsErr != nil {
+			workloadsError = workloadsErr.Error()
+		}
+		out, err := json.Marshal(struct {
+			*model.TuberApp
+			Workloads      []*model.Workload `json:"workloads"`
+			WorkloadsError string            `json:"workloadsError,omitempty"`
+		}{app, workloads, workloadsError})
 		if err != nil {
 			return err
 		}
@@ -47,6 +61,7 @@ func runAppsInfoCmd(cmd *cobra.Command, args []string) error {
 	table.Append([]string{"Timestamps", "CreatedAt: " + app.CreatedAt + "\nUpdatedAt " + app.UpdatedAt})
 	table.Append([]string{"ImageTag", app.ImageTag})
 	table.Append([]string{"Current Tags", strings.Join(app.CurrentTags, "\n")})
+	table.Append([]string{"Current Revision", app.CurrentRevision})
 	var vars []string
 	for _, tuple := range app.Vars {
 		value := tuple.Value
@@ -58,6 +73,7 @@ func runAppsInfoCmd(cmd *cobra.Command, args []string) error {
 	}
 	table.Append([]string{"Vars", strings.Join(vars, "\n")})
 	table.Append([]string{"Paused", strconv.FormatBool(app.Paused)})
+	table.Append([]string{"Require Approval", strconv.FormatBool(app.RequireApproval)})
 	table.Append([]string{"Is Review App", strconv.FormatBool(app.ReviewApp)})
 	if !app.ReviewApp && app.ReviewAppsConfig != nil {
 		table.Append([]string{"Review Apps Enabled", strconv.FormatBool(app.ReviewAppsConfig.Enabled)})
@@ -84,6 +100,20 @@ func runAppsInfoCmd(cmd *cobra.Command, args []string) error {
 	table.Append([]string{"Excluded Resources", strings.Join(excludedResources, "\n")})
 
 	table.Append([]string{"Slack Channel", app.SlackChannel})
+	var notificationTargets []string
+	for _, target := range app.NotificationTargets {
+		notificationTargets = append(notificationTargets, target.Type+": "+target.Target)
+	}
+	table.Append([]string{"Notification Targets", strings.Join(notificationTargets, "\n")})
+	var webhooks []string
+	for _, webhook := range app.Webhooks {
+		events := "all events"
+		if len(webhook.Events) != 0 {
+			events = strings.Join(webhook.Events, ", ")
+		}
+		webhooks = append(webhooks, webhook.URL+" ("+events+")")
+	}
+	table.Append([]string{"Webhooks", strings.Join(webhooks, "\n")})
 	if app.ReviewApp {
 		table.Append([]string{"Name", app.SourceAppName})
 	}
@@ -96,6 +126,28 @@ func runAppsInfoCmd(cmd *cobra.Command, args []string) error {
 		table.Append([]string{"State", strings.Join(state, "\n")})
 	}
 
+	var workloadStatuses []string
+	if workloadsErr != nil {
+		workloadStatuses = append(workloadStatuses, color.YellowString("warning")+" workloads could not be loaded: "+workloadsErr.Error())
+	}
+	for _, workload := range workloads {
+		workloadStatuses = append(workloadStatuses, fmt.Sprintf("%s/%s: %d/%d ready, %d updated", strings.ToLower(workload.Kind), workload.Name, workload.ReadyReplicas, workload.DesiredReplicas, workload.UpdatedReplicas))
+		for _, image := range workload.Images {
+			workloadStatuses = append(workloadStatuses, "  "+image)
+		}
+		for _, pod := range workload.Pods {
+			ready := "not ready"
+			if pod.Ready {
+				ready = "ready"
+			}
+			workloadStatuses = append(workloadStatuses, fmt.Sprintf("  %s %s %s, %d restarts, %s old", pod.Name, pod.Phase, ready, pod.Restarts, pod.Age))
+		}
+		for _, warning := range workload.Warnings {
+			workloadStatuses = append(workloadStatuses, fmt.Sprintf("  %s %s %s: %s (x%d)", color.YellowString("warning"), warning.Object, warning.Reason, warning.Message, warning.Count))
+		}
+	}
+	table.Append([]string{"Workloads", strings.Join(workloadStatuses, "\n")})
+
 	table.Append([]string{"Cloud Source Repo (for triggers)", app.CloudSourceRepo})
 	table.Append([]string{"Trigger Id", app.TriggerID})
 
@@ -104,6 +156,57 @@ func runAppsInfoCmd(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// getWorkloads is the live status of an app's workloads, which getApp leaves out to keep from running kubectl on every lookup
+func getWorkloads(appName string) ([]*model.Workload, error) {
+	graphql, err := gqlClient()
+	if err != nil {
+		return nil, err
+	}
+
+	gql := fmt.Sprintf(`
+		query {
+			getApp(name: "%s") {
+				workloads {
+					kind
+					name
+					desiredReplicas
+					readyReplicas
+					updatedReplicas
+					images
+					pods {
+						name
+						phase
+						ready
+						restarts
+						age
+						createdAt
+					}
+					warnings {
+						reason
+						message
+						object
+						count
+						lastSeen
+					}
+				}
+			}
+		}
+	`, appName)
+
+	var respData struct {
+		GetApp struct {
+			Workloads []*model.Workload
+		}
+	}
+
+	err = graphql.Query(context.Background(), gql, &respData)
+	if err != nil {
+		return nil, err
+	}
+
+	return respData.GetApp.Workloads, nil
+}
+
 func init() {
 	appsInfoCmd.Flags().BoolVar(&appsInfoJsonFlag, "json", false, "output as json")
 	appsCmd.AddCommand(appsInfoCmd)
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/spf13/cobra"
+)
+
+var appsSetNotifyCmd = &cobra.Command{
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Use:           "notify [app name] [slack|webhook|teams|email] [channel, url or address]",
+	Short:         "send an app's release and build notifications to another target, alongside its slack channel",
+	Args:          cobra.ExactArgs(3),
+	PreRunE:       promptCurrentContext,
+	RunE:          runAppsSetNotify,
+}
+
+func runAppsSetNotify(cmd *cobra.Command, args []string) error {
+	graphql, err := gqlClient()
+	if err != nil {
+		return err
+	}
+
+	input := &model.SetNotificationTargetInput{
+		AppName: args[0],
+		Type:    args[1],
+		Target:  args[2],
+	}
+
+	var respData struct {
+		setNotificationTarget *model.TuberApp
+	}
+
+	gql := `
+			mutation($input: SetNotificationTargetInput!) {
+				setNotificationTarget(input: $input) {
+					name
+				}
+			}
+		`
+
+	return graphql.Mutation(context.Background(), gql, nil, input, &respData)
+}
+
+func init() {
+	appsSetCmd.AddCommand(appsSetNotifyCmd)
+}
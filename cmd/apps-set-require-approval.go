@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/spf13/cobra"
+)
+
+var appsSetRequireApprovalCmd = &cobra.Command{
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Use:           "require-approval [app name] [true or false]",
+	Short:         "hold releases of an app until they're approved with `tuber approve`",
+	Args:          cobra.ExactArgs(2),
+	PreRunE:       promptCurrentContext,
+	RunE:          runAppsSetRequireApprovalCmd,
+}
+
+func runAppsSetRequireApprovalCmd(cmd *cobra.Command, args []string) error {
+	graphql, err := gqlClient()
+	if err != nil {
+		return err
+	}
+
+	appName := args[0]
+	requireApproval, err := strconv.ParseBool(args[1])
+	if err != nil {
+		return err
+	}
+
+	input := &model.AppInput{
+		Name:            appName,
+		RequireApproval: &requireApproval,
+	}
+
+	var respData struct {
+		setRequireApproval *model.TuberApp
+	}
+
+	gql := `
+			mutation($input: AppInput!) {
+				setRequireApproval(input: $input) {
+					name
+				}
+			}
+		`
+
+	return graphql.Mutation(context.Background(), gql, nil, input, &respData)
+}
+
+func init() {
+	appsSetCmd.AddCommand(appsSetRequireApprovalCmd)
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/spf13/cobra"
+)
+
+var appsSetUnnotifyCmd = &cobra.Command{
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Use:           "unnotify [app name] [slack|webhook|teams|email] [channel, url or address]",
+	Short:         "stop sending an app's notifications to a target added with `apps set notify`",
+	Args:          cobra.ExactArgs(3),
+	PreRunE:       promptCurrentContext,
+	RunE:          runAppsSetUnnotify,
+}
+
+func runAppsSetUnnotify(cmd *cobra.Command, args []string) error {
+	graphql, err := gqlClient()
+	if err != nil {
+		return err
+	}
+
+	input := &model.SetNotificationTargetInput{
+		AppName: args[0],
+		Type:    args[1],
+		Target:  args[2],
+	}
+
+	var respData struct {
+		unsetNotificationTarget *model.TuberApp
+	}
+
+	gql := `
+			mutation($input: SetNotificationTargetInput!) {
+				unsetNotificationTarget(input: $input) {
+					name
+				}
+			}
+		`
+
+	return graphql.Mutation(context.Background(), gql, nil, input, &respData)
+}
+
+func init() {
+	appsSetCmd.AddCommand(appsSetUnnotifyCmd)
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/spf13/cobra"
+)
+
+var appsSetWebhookCmd = &cobra.Command{
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Use:           "webhook [app name] [url]",
+	Short:         "post an app's release lifecycle events to a url, or stop with --unset",
+	Long: `post an app's release lifecycle events to a url, or stop with --unset.
+Events are release.started, release.prerelease_finished, release.canary_started, release.canary_deployed,
+release.succeeded, release.failed, release.rolled_back and release.skipped - all of them unless --events is set.
+With --secret, each delivery has an X-Tuber-Signature-256 header of sha256=<hex hmac-sha256 of the body>.
+Setting an existing url again updates its events, keeping its secret unless a new one is given.`,
+	Args:    cobra.ExactArgs(2),
+	PreRunE: promptCurrentContext,
+	RunE:    runAppsSetWebhook,
+}
+
+func runAppsSetWebhook(cmd *cobra.Command, args []string) error {
+	graphql, err := gqlClient()
+	if err != nil {
+		return err
+	}
+
+	input := &model.SetWebhookInput{
+		AppName: args[0],
+		URL:     args[1],
+	}
+
+	if appsSetWebhookUnsetFlag {
+		var respData struct {
+			unsetWebhook *model.TuberApp
+		}
+
+		gql := `
+			mutation($input: SetWebhookInput!) {
+				unsetWebhook(input: $input) {
+					name
+				}
+			}
+		`
+
+		return graphql.Mutation(context.Background(), gql, nil, input, &respData)
+	}
+
+	if appsSetWebhookEventsFlag != "" {
+		input.Events = strings.Split(appsSetWebhookEventsFlag, ",")
+	}
+	if appsSetWebhookSecretFlag != "" {
+		input.Secret = &appsSetWebhookSecretFlag
+	}
+
+	var respData struct {
+		setWebhook *model.TuberApp
+	}
+
+	gql := `
+			mutation($input: SetWebhookInput!) {
+				setWebhook(input: $input) {
+					name
+				}
+			}
+		`
+
+	return graphql.Mutation(context.Background(), gql, nil, input, &respData)
+}
+
+var appsSetWebhookUnsetFlag bool
+var appsSetWebhookEventsFlag string
+var appsSetWebhookSecretFlag string
+
+func init() {
+	appsSetWebhookCmd.Flags().BoolVar(&appsSetWebhookUnsetFlag, "unset", false, "remove the webhook rather than set it")
+	appsSetWebhookCmd.Flags().StringVar(&appsSetWebhookEventsFlag, "events", "", "comma separated lifecycle events to send, defaults to all")
+	appsSetWebhookCmd.Flags().StringVar(&appsSetWebhookSecretFlag, "secret", "", "secret to sign deliveries with")
+	appsSetCmd.AddCommand(appsSetWebhookCmd)
+}
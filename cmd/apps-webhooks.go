@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var appsWebhooksJsonFlag bool
+
+var appsWebhooksCmd = &cobra.Command{
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Use:           "webhooks [app name]",
+	Short:         "list an app's recent webhook deliveries, newest first",
+	Args:          cobra.ExactArgs(1),
+	PreRunE:       displayCurrentContext,
+	RunE:          runAppsWebhooksCmd,
+}
+
+func runAppsWebhooksCmd(cmd *cobra.Command, args []string) error {
+	graphql, err := gqlClient()
+	if err != nil {
+		return err
+	}
+
+	gql := fmt.Sprintf(`
+			query {
+				getWebhookDeliveries(appName: "%s") {
+					id
+					url
+					event
+					releaseId
+					status
+					attempts
+					statusCode
+					error
+					createdAt
+					updatedAt
+				}
+			}
+		`, args[0])
+
+	var respData struct {
+		GetWebhookDeliveries []*model.WebhookDelivery
+	}
+
+	if err := graphql.Query(context.Background(), gql, &respData); err != nil {
+		return err
+	}
+
+	deliveries := respData.GetWebhookDeliveries
+
+	if appsWebhooksJsonFlag {
+		out, err := json.Marshal(deliveries)
+		if err != nil {
+			return err
+		}
+
+		os.Stdout.Write(out)
+		return nil
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Time", "Event", "Url", "Status", "Attempts", "Response", "Error"})
+	table.SetBorder(false)
+
+	for _, delivery := range deliveries {
+		response := ""
+		if delivery.StatusCode != 0 {
+			response = strconv.Itoa(delivery.StatusCode)
+		}
+		table.Append([]string{delivery.CreatedAt, delivery.Event, delivery.URL, delivery.Status, strconv.Itoa(delivery.Attempts), response, delivery.Error})
+	}
+
+	table.Render()
+	return nil
+}
+
+func init() {
+	appsWebhooksCmd.Flags().BoolVar(&appsWebhooksJsonFlag, "json", false, "output as json")
+	appsCmd.AddCommand(appsWebhooksCmd)
+}
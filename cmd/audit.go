@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var auditSinceFlag string
+var auditJsonFlag bool
+
+var auditCmd = &cobra.Command{
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Use:           "audit",
+	Short:         "list who ran which mutations, newest first",
+	Long: `list who ran which mutations, newest first. Without --app, lists every app's entries and cluster wide ones like pauses and freeze windows.
+--since takes a duration back from now, like 24h, or an RFC3339 timestamp.`,
+	Args:    cobra.NoArgs,
+	PreRunE: displayCurrentContext,
+	RunE:    runAuditCmd,
+}
+
+func runAuditCmd(cmd *cobra.Command, args []string) error {
+	since, err := auditSince(auditSinceFlag)
+	if err != nil {
+		return err
+	}
+
+	graphql, err := gqlClient()
+	if err != nil {
+		return err
+	}
+
+	var filters []string
+	if appNameFlag != "" {
+		filters = append(filters, fmt.Sprintf(`appName: "%s"`, appNameFlag))
+	}
+	if since != "" {
+		filters = append(filters, fmt.Sprintf(`since: "%s"`, since))
+	}
+	var arguments string
+	if len(filters) != 0 {
+		arguments = "(" + strings.Join(filters, ", ") + ")"
+	}
+
+	gql := fmt.Sprintf(`
+			query {
+				getAuditLog%s {
+					id
+					operation
+					appName
+					identity
+					input
+					succeeded
+					error
+					createdAt
+				}
+			}
+		`, arguments)
+
+	var respData struct {
+		GetAuditLog []*model.AuditEntry
+	}
+
+	if err := graphql.Query(context.Background(), gql, &respData); err != nil {
+		return err
+	}
+
+	entries := respData.GetAuditLog
+
+	if auditJsonFlag {
+		out, err := json.Marshal(entries)
+		if err != nil {
+			return err
+		}
+
+		os.Stdout.Write(out)
+		return nil
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Time", "Operation", "App", "Identity", "Result", "Input"})
+	table.SetBorder(false)
+	table.SetAutoWrapText(false)
+
+	for _, entry := range entries {
+		result := "ok"
+		if !entry.Succeeded {
+			result = "failed: " + entry.Error
+		}
+		table.Append([]string{entry.CreatedAt, entry.Operation, entry.AppName, entry.Identity, result, entry.Input})
+	}
+
+	table.Render()
+	return nil
+}
+
+// auditSince turns --since into the RFC3339 timestamp the api expects
+func auditSince(since string) (string, error) {
+	if since == "" {
+		return "", nil
+	}
+
+	duration, err := time.ParseDuration(since)
+	if err == nil {
+		return time.Now().Add(-duration).UTC().Format(time.RFC3339), nil
+	}
+
+	_, err = time.Parse(time.RFC3339, since)
+	if err != nil {
+		return "", fmt.Errorf("--since must be a duration like 24h or an RFC3339 timestamp")
+	}
+	return since, nil
+}
+
+func init() {
+	auditCmd.Flags().StringVarP(&appNameFlag, "app", "a", "", "app name")
+	auditCmd.Flags().StringVar(&auditSinceFlag, "since", "", "only entries since a duration ago, like 24h, or an RFC3339 timestamp")
+	auditCmd.Flags().BoolVar(&auditJsonFlag, "json", false, "output as json")
+	rootCmd.AddCommand(auditCmd)
+}
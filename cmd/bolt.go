@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -17,7 +18,7 @@ import (
 var bolterCmd = &cobra.Command{
 	SilenceUsage: true,
 	Use:          "bolter",
-	Short:        "pulls remote database to local (CURRENTLY FROM CONFIGMAPS)",
+	Short:        "pulls remote database to local from legacy configmaps - tuber db backup gets the current one",
 	RunE:         bolter,
 }
 
@@ -49,7 +50,7 @@ func pullLocalDB(db *core.DB) error {
 		return err
 	}
 
-	pauses, err := k8s.GetConfigResource("tuber-app-pauses", "tuber", "configmap")
+	pauses, err := k8s.GetConfigResource(context.Background(), "tuber-app-pauses", "tuber", "configmap")
 	if err != nil {
 		return err
 	}
@@ -58,12 +59,12 @@ func pullLocalDB(db *core.DB) error {
 	var repos *k8s.ConfigResource
 
 	if reviewAppsEnabled {
-		reviewAppTriggers, err = k8s.GetConfigResource("tuber-review-triggers", "tuber", "configmap")
+		reviewAppTriggers, err = k8s.GetConfigResource(context.Background(), "tuber-review-triggers", "tuber", "configmap")
 		if err != nil {
 			return err
 		}
 
-		repos, err = k8s.GetConfigResource("tuber-repos", "tuber", "configmap")
+		repos, err = k8s.GetConfigResource(context.Background(), "tuber-repos", "tuber", "configmap")
 		if err != nil {
 			return err
 		}
@@ -185,20 +186,20 @@ type managedResource struct {
 
 func currentState(app *model.TuberApp) (*model.State, error) {
 	stateName := "tuber-state-" + app.Name
-	exists, err := k8s.Exists("configMap", stateName, app.Name)
+	exists, err := k8s.Exists(context.Background(), "configMap", stateName, app.Name)
 
 	if err != nil {
 		return nil, err
 	}
 
 	if !exists {
-		createErr := k8s.Create(app.Name, "configmap", stateName, `--from-literal=state=`)
+		createErr := k8s.Create(context.Background(), app.Name, "configmap", stateName, `--from-literal=state=`)
 		if createErr != nil {
 			return nil, err
 		}
 	}
 
-	stateResource, err := k8s.GetConfigResource(stateName, app.Name, "ConfigMap")
+	stateResource, err := k8s.GetConfigResource(context.Background(), stateName, app.Name, "ConfigMap")
 	if err != nil {
 		return nil, err
 	}
@@ -243,7 +244,7 @@ func currentState(app *model.TuberApp) (*model.State, error) {
 // ^ mostly copied from releaser cus this is the most temporary nonsense ever
 
 func getallconfigapps(reviewappsenabled bool) ([]*model.TuberApp, error) {
-	sourceAppsConfig, err := k8s.GetConfigResource("tuber-apps", "tuber", "ConfigMap")
+	sourceAppsConfig, err := k8s.GetConfigResource(context.Background(), "tuber-apps", "tuber", "ConfigMap")
 	if err != nil {
 		return nil, err
 	}
@@ -257,7 +258,7 @@ func getallconfigapps(reviewappsenabled bool) ([]*model.TuberApp, error) {
 	configapps = append(configapps, sourceApps...)
 
 	if reviewappsenabled {
-		reviewAppsConfig, err := k8s.GetConfigResource("tuber-review-apps", "tuber", "ConfigMap")
+		reviewAppsConfig, err := k8s.GetConfigResource(context.Background(), "tuber-review-apps", "tuber", "ConfigMap")
 		if err != nil {
 			return nil, err
 		}
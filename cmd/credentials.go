@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"github.com/freshly/tuber/pkg/k8s"
 
 	"github.com/spf13/cobra"
@@ -12,7 +13,7 @@ var credentialsCmd = &cobra.Command{
 	Short:        "add tuber secrets from file",
 	PreRunE:      promptCurrentContext,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return k8s.CreateTuberCredentials(args[0], args[1])
+		return k8s.CreateTuberCredentials(context.Background(), args[0], args[1])
 	},
 }
 
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var dbBackupOutputFlag string
+
+var dbBackupCmd = &cobra.Command{
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Use:           "backup",
+	Short:         "download a consistent snapshot of tuber's database",
+	Long: `download a consistent snapshot of tuber's database, taken while tuber keeps running.
+The snapshot is a database file of its own - tuber db migrations --path reads it, and tuber db restore puts it back.`,
+	Args:    cobra.NoArgs,
+	PreRunE: displayCurrentContext,
+	RunE:    runDBBackupCmd,
+}
+
+func runDBBackupCmd(*cobra.Command, []string) error {
+	graphql, err := gqlClient()
+	if err != nil {
+		return err
+	}
+
+	output := dbBackupOutputFlag
+	if output == "" {
+		output = fmt.Sprintf("tuber-%s.db", time.Now().UTC().Format("20060102-150405"))
+	}
+
+	res, err := graphql.Request(context.Background(), http.MethodGet, "/db", nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	// written aside and renamed, so a failed download never looks like a backup
+	file, err := os.Create(output + ".tmp")
+	if err != nil {
+		return err
+	}
+	written, err := io.Copy(file, res.Body)
+	if err != nil {
+		file.Close()
+		os.Remove(output + ".tmp")
+		return err
+	}
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(output+".tmp", output)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("backed up %d bytes to %s\n", written, output)
+	return nil
+}
+
+func init() {
+	dbBackupCmd.Flags().StringVarP(&dbBackupOutputFlag, "output", "o", "", "file to write (defaults to tuber-<time>.db)")
+	dbCmd.AddCommand(dbBackupCmd)
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+
+	tuberbolt "github.com/freshly/tuber/pkg/db"
+	"github.com/spf13/cobra"
+)
+
+var dbMigrateDryRunFlag bool
+
+var dbMigrateCmd = &cobra.Command{
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Use:           "migrate",
+	Short:         "apply pending database migrations to a database file, which tuber does to its own on startup",
+	Long: `apply pending database migrations to a database file, like a backup from tuber db backup.
+tuber migrates its own database on startup, and holds it open while it runs, so this only works on copies -
+for checking a backup will migrate cleanly before restoring it, with --dry-run.`,
+	Args: cobra.NoArgs,
+	RunE: runDBMigrateCmd,
+}
+
+func runDBMigrateCmd(*cobra.Command, []string) error {
+	path, err := dbPath()
+	if err != nil {
+		return err
+	}
+
+	db, err := openUnmigratedDB(path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var applied []tuberbolt.Migration
+	if dbMigrateDryRunFlag {
+		applied, err = db.MigrateDryRun()
+	} else {
+		applied, err = db.Migrate()
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(applied) == 0 {
+		fmt.Println("database is up to date")
+		return nil
+	}
+
+	verb := "applied"
+	if dbMigrateDryRunFlag {
+		verb = "would apply"
+	}
+	for _, migration := range applied {
+		fmt.Printf("%s %d: %s\n", verb, migration.Version, migration.Description)
+	}
+	return nil
+}
+
+func init() {
+	dbMigrateCmd.Flags().BoolVar(&dbMigrateDryRunFlag, "dry-run", false, "run pending migrations, then roll them back")
+	dbMigrateCmd.Flags().StringVar(&dbPathFlag, "path", "", "database file, like a backup")
+	dbMigrateCmd.MarkFlagRequired("path")
+	dbCmd.AddCommand(dbMigrateCmd)
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/freshly/tuber/pkg/core"
+	tuberbolt "github.com/freshly/tuber/pkg/db"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var dbMigrationsCmd = &cobra.Command{
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Use:           "migrations",
+	Short:         "list database migrations, and when each was applied to a database file",
+	Long: `list database migrations, and when each was applied to a database file, without changing the file.
+tuber holds its live database open, so to see its migrations, back it up first:
+  tuber db backup -o tuber.db && tuber db migrations --path tuber.db`,
+	Args: cobra.NoArgs,
+	RunE: runDBMigrationsCmd,
+}
+
+func runDBMigrationsCmd(*cobra.Command, []string) error {
+	path, err := dbPath()
+	if err != nil {
+		return err
+	}
+
+	database, err := tuberbolt.NewReadOnlyDB(path)
+	if err != nil {
+		return err
+	}
+	db := core.NewDB(database)
+	defer db.Close()
+
+	migrations, err := db.Migrations()
+	if err != nil {
+		return err
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Version", "Description", "Applied"})
+	table.SetBorder(false)
+
+	for _, migration := range migrations {
+		applied := migration.AppliedAt
+		if applied == "" {
+			applied = "pending"
+		}
+		table.Append([]string{strconv.Itoa(migration.Version), migration.Description, applied})
+	}
+
+	table.Render()
+	return nil
+}
+
+func init() {
+	dbMigrationsCmd.Flags().StringVar(&dbPathFlag, "path", "", "database file, like a backup")
+	dbMigrationsCmd.MarkFlagRequired("path")
+	dbCmd.AddCommand(dbMigrationsCmd)
+}
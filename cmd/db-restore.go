@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var dbRestoreCmd = &cobra.Command{
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Use:           "restore [file]",
+	Short:         "replace tuber's database with a backup",
+	Long: `replace tuber's database with a backup, from tuber db backup or a scheduled backup (gzipped is fine).
+Everything in the database is replaced in one go, then migrated up to date. Backups from a newer tuber are refused.
+Api tokens and the audit log are kept as they are, not restored. Restoring needs access to everything in every namespace.`,
+	Args:    cobra.ExactArgs(1),
+	PreRunE: promptCurrentContext,
+	RunE:    runDBRestoreCmd,
+}
+
+func runDBRestoreCmd(cmd *cobra.Command, args []string) error {
+	graphql, err := gqlClient()
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	res, err := graphql.Request(context.Background(), http.MethodPost, "/db", file)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	message, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(message))
+	return nil
+}
+
+func init() {
+	dbCmd.AddCommand(dbRestoreCmd)
+}
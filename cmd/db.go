@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var dbPathFlag string
+
+var dbCmd = &cobra.Command{
+	Use:   "db [command]",
+	Short: "A root command for tuber's own database",
+}
+
+// dbPath is --path, which has to be an existing db file. The running server holds its own db open,
+// so commands that open a db file work on a copy, like one from tuber db backup.
+func dbPath() (string, error) {
+	_, err := os.Stat(dbPathFlag)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("no database at %s - --path takes a database file, like one from tuber db backup", dbPathFlag)
+	}
+	if err != nil {
+		return "", err
+	}
+	return dbPathFlag, nil
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+}
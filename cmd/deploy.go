@@ -6,7 +6,6 @@ import (
 	"github.com/freshly/tuber/graph/model"
 	"github.com/freshly/tuber/pkg/events"
 	"github.com/freshly/tuber/pkg/gcr"
-	"github.com/freshly/tuber/pkg/slack"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -44,21 +43,33 @@ func deploy(cmd *cobra.Command, args []string) error {
 	gql := `
 		mutation($input: AppInput!) {
 			deploy(input: $input) {
-				name
+				releaseId
 			}
 		}
 	`
 
 	input := &model.AppInput{
-		Name:     appName,
-		ImageTag: &tag,
+		Name:           appName,
+		ImageTag:       &tag,
+		OverrideFreeze: &deployOverrideFreezeFlag,
 	}
 
 	var respData struct {
-		deploy *model.TuberApp
+		Deploy *model.StartedRelease
 	}
 
-	return graphql.Mutation(context.Background(), gql, nil, input, &respData)
+	mutation := func() (string, error) {
+		err := graphql.Mutation(context.Background(), gql, nil, input, &respData)
+		if err != nil {
+			return "", err
+		}
+		return respData.Deploy.ReleaseID, nil
+	}
+	if deployFollowFlag {
+		return followRelease(appName, mutation)
+	}
+	_, err = mutation()
+	return err
 }
 
 func localDeploy(appName string, flagTag string) error {
@@ -101,8 +112,12 @@ func localDeploy(appName string, flagTag string) error {
 	var ctx, cancel = context.WithCancel(context.Background())
 	defer cancel()
 
-	slackClient := slack.New(viper.GetString("TUBER_SLACK_TOKEN"), viper.GetBool("TUBER_SLACK_ENABLED"), viper.GetString("TUBER_SLACK_CATCHALL_CHANNEL"))
-	processor := events.NewProcessor(ctx, logger, db, creds, data, viper.GetBool("TUBER_REVIEWAPPS_ENABLED"), slackClient, viper.GetString("TUBER_SENTRY_BEARER_TOKEN"), viper.GetString("TUBER_EVENTS_PROJECT"), viper.GetString("TUBER_EVENTS_TOPIC"))
+	publisher, err := lifecyclePublisher(ctx, logger, db, creds, false)
+	if err != nil {
+		return err
+	}
+
+	processor := events.NewProcessor(ctx, logger, db, creds, data, viper.GetBool("TUBER_REVIEWAPPS_ENABLED"), notifiers(), viper.GetString("TUBER_SENTRY_BEARER_TOKEN"), publisher, approvalTTL(), githubClient(), viper.GetString("TUBER_CLUSTER_NAME"), viper.GetBool("TUBER_GITHUB_PRODUCTION"))
 
 	tag := flagTag
 	if tag == "" {
@@ -114,15 +129,18 @@ func localDeploy(appName string, flagTag string) error {
 		return err
 	}
 
-	processor.StartRelease(events.NewEvent(logger, digest, tag), app)
-	return nil
+	return processor.StartRelease(events.NewEvent(logger, digest, tag), app)
 }
 
 var deployLocalFlag bool
 var deployTagFlag string
+var deployOverrideFreezeFlag bool
+var deployFollowFlag bool
 
 func init() {
 	deployCmd.Flags().BoolVar(&deployLocalFlag, "local", false, "run the full deploy process locally, including all monitoring.")
 	deployCmd.Flags().StringVarP(&deployTagFlag, "tag", "t", "", "deploy a specific tag")
+	deployCmd.Flags().BoolVar(&deployOverrideFreezeFlag, "override-freeze", false, "deploy even if a freeze window is active")
+	deployCmd.Flags().BoolVarP(&deployFollowFlag, "follow", "f", false, "follow the release until it finishes, exiting non-zero if it fails")
 	rootCmd.AddCommand(deployCmd)
 }
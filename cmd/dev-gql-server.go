@@ -5,6 +5,7 @@ import (
 
 	"github.com/freshly/tuber/pkg/config"
 	"github.com/freshly/tuber/pkg/events"
+	"github.com/freshly/tuber/pkg/notify"
 	"github.com/freshly/tuber/pkg/slack"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -61,7 +62,7 @@ func runDevGqlServer(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	slackClient := slack.New("", false, "")
+	notifiers := notify.New(notify.NewSlack(slack.New("", false, "")), nil, nil, nil)
 
 	viper.SetDefault("TUBER_USE_DEVSERVER", true)
 	viper.SetDefault("TUBER_ADMINSERVER_PORT", "3000")
@@ -74,8 +75,10 @@ func runDevGqlServer(cmd *cobra.Command, args []string) error {
 	viper.SetDefault("TUBER_CLUSTER_REGION", "us-central1")
 	viper.SetDefault("TUBER_CLUSTER_NAME", cc.Shorthand)
 	viper.SetDefault("TUBER_DEBUG", true)
-	processor := events.NewProcessor(ctx, logger, db, creds, data, true, slackClient, "", "", "")
-	startAdminServer(ctx, db, processor, logger, creds)
+	processor := events.NewProcessor(ctx, logger, db, creds, data, true, notifiers, "", events.Publishers{events.NewLocalPublisher(logger), events.NewWebhookPublisher(ctx, logger, db, webhookHosts(), events.DefaultWebhookAttempts, events.DefaultWebhookBackoff)}, approvalTTL(), nil, "", false)
+	inbox := events.NewInbox(ctx, logger, db, processor)
+	go inbox.Start()
+	startAdminServer(ctx, db, processor, inbox, nil, logger, creds)
 
 	return nil
 }
@@ -65,13 +65,13 @@ var fileCmd = &cobra.Command{
 			appName = appNameFlag
 			filePath = args[0]
 		}
-		err := k8s.CreateEnvFromFile(appName, filePath)
+		err := k8s.CreateEnvFromFile(context.Background(), appName, filePath)
 
 		if err != nil {
 			return err
 		}
 
-		return k8s.Restart("deployments", appName)
+		return k8s.Restart(context.Background(), "deployments", appName)
 	},
 }
 
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/freshly/tuber/pkg/gcr"
+	"github.com/freshly/tuber/pkg/pubsub"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var eventsPublishURLFlag string
+var eventsPublishDigestFlag string
+var eventsPublishStatusFlag string
+var eventsPublishRepoFlag string
+var eventsPublishBranchFlag string
+var eventsPublishLogURLFlag string
+
+var eventsPublishCmd = &cobra.Command{
+	Use:   "publish [command]",
+	Short: "publish messages to a tuber running with TUBER_EVENT_SOURCE=local",
+}
+
+var eventsPublishImageCmd = &cobra.Command{
+	SilenceUsage: true,
+	Use:          "image [tag]",
+	Short:        "publish an image push, resolving the digest from the registry unless --digest is set",
+	Args:         cobra.ExactArgs(1),
+	RunE:         runEventsPublishImageCmd,
+}
+
+var eventsPublishBuildCmd = &cobra.Command{
+	SilenceUsage: true,
+	Use:          "build",
+	Short:        "publish a cloud build status update",
+	Args:         cobra.NoArgs,
+	RunE:         runEventsPublishBuildCmd,
+}
+
+func runEventsPublishImageCmd(cmd *cobra.Command, args []string) error {
+	tag := args[0]
+	digest := eventsPublishDigestFlag
+	if digest == "" {
+		// no service account here, docker's own credential helpers cover local registries and gcr
+		resolved, err := gcr.DigestFromTag(tag, nil)
+		if err != nil {
+			return fmt.Errorf("could not resolve digest for %s, pass --digest: %v", tag, err)
+		}
+		digest = resolved
+	}
+
+	return publishLocalEvent(pubsub.LocalImages, pubsub.Message{Digest: digest, Tag: tag})
+}
+
+func runEventsPublishBuildCmd(cmd *cobra.Command, args []string) error {
+	if eventsPublishStatusFlag == "" || eventsPublishRepoFlag == "" || eventsPublishBranchFlag == "" {
+		return fmt.Errorf("--status, --repo and --branch are required")
+	}
+
+	message := pubsub.Message{Status: strings.ToUpper(eventsPublishStatusFlag), LogURL: eventsPublishLogURLFlag}
+	message.Substitutions.RepoName = eventsPublishRepoFlag
+	message.Substitutions.BranchName = eventsPublishBranchFlag
+
+	return publishLocalEvent(pubsub.LocalBuilds, message)
+}
+
+func publishLocalEvent(source string, message pubsub.Message) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	baseURL := eventsPublishURLFlag
+	if baseURL == "" {
+		viper.SetDefault("TUBER_ADMINSERVER_PREFIX", "/tuber")
+		baseURL = "http://localhost:3000" + viper.GetString("TUBER_ADMINSERVER_PREFIX")
+	}
+
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(baseURL, "/")+"/events/local/"+source, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token := viper.GetString("TUBER_LOCAL_EVENTS_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusAccepted {
+		resBody, _ := ioutil.ReadAll(res.Body)
+		return fmt.Errorf("publish failed with %s: %s", res.Status, strings.TrimSpace(string(resBody)))
+	}
+
+	fmt.Printf("published %s message\n", source)
+	return nil
+}
+
+func init() {
+	eventsPublishCmd.PersistentFlags().StringVar(&eventsPublishURLFlag, "url", "", "admin server url, including prefix (default http://localhost:3000/tuber)")
+	eventsPublishImageCmd.Flags().StringVar(&eventsPublishDigestFlag, "digest", "", "full digest reference, e.g. gcr.io/project/app@sha256:...")
+	eventsPublishBuildCmd.Flags().StringVar(&eventsPublishStatusFlag, "status", "", "cloud build status, e.g. WORKING, SUCCESS, FAILURE")
+	eventsPublishBuildCmd.Flags().StringVar(&eventsPublishRepoFlag, "repo", "", "cloud source repo name")
+	eventsPublishBuildCmd.Flags().StringVar(&eventsPublishBranchFlag, "branch", "", "branch name")
+	eventsPublishBuildCmd.Flags().StringVar(&eventsPublishLogURLFlag, "log-url", "", "build log url")
+	eventsPublishCmd.AddCommand(eventsPublishImageCmd)
+	eventsPublishCmd.AddCommand(eventsPublishBuildCmd)
+	eventsCmd.AddCommand(eventsPublishCmd)
+}
@@ -0,0 +1,14 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var eventsCmd = &cobra.Command{
+	Use:   "events [command]",
+	Short: "a root command for tuber's event pipeline",
+}
+
+func init() {
+	rootCmd.AddCommand(eventsCmd)
+}
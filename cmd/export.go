@@ -51,6 +51,10 @@ func runExportCmd(cmd *cobra.Command, args []string) error {
 					}
 				}
 				slackChannel
+				notificationTargets {
+					type
+					target
+				}
 				excludedResources {
 					kind
 					name
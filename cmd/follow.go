@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/notify"
+)
+
+// followRelease watches an app's release progress, runs start once the watch is in place so nothing is missed,
+// then prints the progress of the release or rollback start returns the id of until it finishes.
+// It errors unless that succeeded, so ci can fail on it. Anything else going on with the app is ignored.
+func followRelease(appName string, start func() (string, error)) error {
+	graphql, err := gqlClient()
+	if err != nil {
+		return err
+	}
+
+	gql := fmt.Sprintf(`
+		subscription {
+			releaseProgress(appName: "%s") {
+				type
+				releaseId
+				tag
+				phase
+				resource
+				severity
+				message
+				detail
+				finished
+				succeeded
+				time
+			}
+		}
+	`, appName)
+
+	var releaseID string
+	var finished *model.ReleaseProgress
+	err = graphql.Subscribe(context.Background(), gql, func(data json.RawMessage) (bool, error) {
+		var respData struct {
+			ReleaseProgress *model.ReleaseProgress
+		}
+		err := json.Unmarshal(data, &respData)
+		if err != nil {
+			return false, err
+		}
+		progress := respData.ReleaseProgress
+
+		if progress.Type == notify.WatchStarted {
+			releaseID, err = start()
+			return false, err
+		}
+
+		if progress.ReleaseID != releaseID {
+			return false, nil
+		}
+
+		printProgress(progress)
+		if progress.Finished {
+			finished = progress
+			return true, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if finished == nil {
+		return fmt.Errorf("stopped following %s before it finished", appName)
+	}
+	if !finished.Succeeded {
+		return fmt.Errorf("%s: %s", appName, finished.Message)
+	}
+	return nil
+}
+
+func printProgress(progress *model.ReleaseProgress) {
+	timestamp := progress.Time
+	if parsed, err := time.Parse(time.RFC3339, progress.Time); err == nil {
+		timestamp = parsed.Local().Format("15:04:05")
+	}
+
+	message := progress.Message
+	switch {
+	case progress.Severity == notify.SeverityError:
+		message = color.RedString(message)
+	case progress.Succeeded:
+		message = color.GreenString(message)
+	case progress.Type == notify.ReleasePhaseStarted:
+		message = color.CyanString(message)
+	}
+
+	fmt.Printf("%s %s\n", color.HiBlackString(timestamp), message)
+	if progress.Detail != "" {
+		fmt.Printf("         %s\n", progress.Detail)
+	}
+}
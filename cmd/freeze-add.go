@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/spf13/cobra"
+)
+
+var freezeAddReasonFlag string
+var freezeAddStartFlag string
+var freezeAddEndFlag string
+var freezeAddCronFlag string
+var freezeAddDurationFlag string
+var freezeAddTimezoneFlag string
+var freezeAddIncludeFlag []string
+var freezeAddExcludeFlag []string
+
+var freezeAddCmd = &cobra.Command{
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Use:           "add [name]",
+	Short:         "add a freeze window, either from --start to --end, or recurring with --cron and --duration",
+	Example: `  tuber freeze add holidays --start 2021-12-23 --end 2022-01-03 --timezone America/New_York --reason "holiday freeze"
+  tuber freeze add weekends --cron "0 17 * * FRI" --duration 63h --timezone America/New_York --exclude docs-site`,
+	Args:    cobra.ExactArgs(1),
+	PreRunE: promptCurrentContext,
+	RunE:    runFreezeAddCmd,
+}
+
+func runFreezeAddCmd(cmd *cobra.Command, args []string) error {
+	graphql, err := gqlClient()
+	if err != nil {
+		return err
+	}
+
+	input := &model.FreezeWindowInput{
+		Name:        args[0],
+		Reason:      &freezeAddReasonFlag,
+		Start:       &freezeAddStartFlag,
+		End:         &freezeAddEndFlag,
+		Cron:        &freezeAddCronFlag,
+		Duration:    &freezeAddDurationFlag,
+		Timezone:    &freezeAddTimezoneFlag,
+		IncludeApps: freezeAddIncludeFlag,
+		ExcludeApps: freezeAddExcludeFlag,
+	}
+
+	var respData struct {
+		createFreezeWindow *model.FreezeWindow
+	}
+
+	gql := `
+			mutation($input: FreezeWindowInput!) {
+				createFreezeWindow(input: $input) {
+					name
+				}
+			}
+		`
+
+	return graphql.Mutation(context.Background(), gql, nil, input, &respData)
+}
+
+func init() {
+	freezeAddCmd.Flags().StringVar(&freezeAddReasonFlag, "reason", "", "reason shown when a release is skipped")
+	freezeAddCmd.Flags().StringVar(&freezeAddStartFlag, "start", "", "start time, RFC3339 or YYYY-MM-DD[ HH:MM] in --timezone")
+	freezeAddCmd.Flags().StringVar(&freezeAddEndFlag, "end", "", "end time, RFC3339 or YYYY-MM-DD[ HH:MM] in --timezone")
+	freezeAddCmd.Flags().StringVar(&freezeAddCronFlag, "cron", "", "cron schedule the window opens on, in --timezone")
+	freezeAddCmd.Flags().StringVar(&freezeAddDurationFlag, "duration", "", "how long a --cron window stays open, like 48h")
+	freezeAddCmd.Flags().StringVar(&freezeAddTimezoneFlag, "timezone", "UTC", "IANA timezone, like America/New_York")
+	freezeAddCmd.Flags().StringSliceVar(&freezeAddIncludeFlag, "include", []string{}, "only freeze these apps")
+	freezeAddCmd.Flags().StringSliceVar(&freezeAddExcludeFlag, "exclude", []string{}, "never freeze these apps")
+	freezeCmd.AddCommand(freezeAddCmd)
+}
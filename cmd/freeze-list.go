@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var freezeListJsonFlag bool
+
+var freezeListCmd = &cobra.Command{
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Use:           "list",
+	Short:         "List freeze windows",
+	PreRunE:       displayCurrentContext,
+	RunE:          runFreezeListCmd,
+}
+
+func runFreezeListCmd(*cobra.Command, []string) error {
+	graphql, err := gqlClient()
+	if err != nil {
+		return err
+	}
+
+	gql := `
+			query {
+				getFreezeWindows {
+					name
+					reason
+					start
+					end
+					cron
+					duration
+					timezone
+					includeApps
+					excludeApps
+					active
+				}
+			}
+		`
+
+	var respData struct {
+		GetFreezeWindows []*model.FreezeWindow
+	}
+
+	if err := graphql.Query(context.Background(), gql, &respData); err != nil {
+		return err
+	}
+
+	windows := respData.GetFreezeWindows
+
+	if freezeListJsonFlag {
+		out, err := json.Marshal(windows)
+		if err != nil {
+			return err
+		}
+
+		os.Stdout.Write(out)
+		return nil
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Name", "Active", "When", "Timezone", "Apps", "Reason"})
+	table.SetBorder(false)
+
+	for _, window := range windows {
+		when := window.Start + " - " + window.End
+		if window.Cron != "" {
+			when = window.Cron + " for " + window.Duration
+		}
+
+		apps := "all"
+		if len(window.IncludeApps) != 0 {
+			apps = strings.Join(window.IncludeApps, ", ")
+		}
+		if len(window.ExcludeApps) != 0 {
+			apps += " except " + strings.Join(window.ExcludeApps, ", ")
+		}
+
+		table.Append([]string{window.Name, strconv.FormatBool(window.Active), when, window.Timezone, apps, window.Reason})
+	}
+
+	table.Render()
+	return nil
+}
+
+func init() {
+	freezeListCmd.Flags().BoolVar(&freezeListJsonFlag, "json", false, "output as json")
+	freezeCmd.AddCommand(freezeListCmd)
+}
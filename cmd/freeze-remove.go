@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/freshly/tuber/graph"
+	"github.com/freshly/tuber/graph/model"
+	"github.com/spf13/cobra"
+)
+
+var freezeRemoveCmd = &cobra.Command{
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Use:           "remove [name]",
+	Short:         "remove a freeze window",
+	Args:          cobra.ExactArgs(1),
+	PreRunE:       promptCurrentContext,
+	RunE:          runFreezeRemoveCmd,
+}
+
+func runFreezeRemoveCmd(cmd *cobra.Command, args []string) error {
+	graphql, err := gqlClient()
+	if err != nil {
+		return err
+	}
+
+	var respData struct {
+		RemoveFreezeWindow *model.FreezeWindow
+	}
+
+	gql := `
+			mutation($name: ID!) {
+				removeFreezeWindow(name: $name) {
+					name
+				}
+			}
+		`
+
+	return graphql.Query(context.Background(), gql, &respData, graph.WithVar("name", args[0]))
+}
+
+func init() {
+	freezeCmd.AddCommand(freezeRemoveCmd)
+}
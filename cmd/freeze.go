@@ -0,0 +1,14 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var freezeCmd = &cobra.Command{
+	Use:   "freeze [command]",
+	Short: "A root command for deploy freeze windows, which block automatic releases",
+}
+
+func init() {
+	rootCmd.AddCommand(freezeCmd)
+}
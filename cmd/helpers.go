@@ -12,17 +12,23 @@ import (
 	"github.com/fatih/color"
 	"github.com/freshly/tuber/graph"
 	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/backup"
 	"github.com/freshly/tuber/pkg/config"
 	"github.com/freshly/tuber/pkg/core"
 	tuberbolt "github.com/freshly/tuber/pkg/db"
+	"github.com/freshly/tuber/pkg/events"
+	"github.com/freshly/tuber/pkg/github"
 	"github.com/freshly/tuber/pkg/iap"
 	"github.com/freshly/tuber/pkg/k8s"
+	"github.com/freshly/tuber/pkg/notify"
 	"github.com/freshly/tuber/pkg/report"
+	"github.com/freshly/tuber/pkg/slack"
 
 	"github.com/briandowns/spinner"
 	"github.com/getsentry/sentry-go"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"go.uber.org/zap"
 )
 
 var address string
@@ -31,20 +37,41 @@ var pod string
 var podRunningTimeout string
 var workload string
 
+// openDB opens tuber's db, bringing it up to date with core.Migrations
 func openDB() (*core.DB, error) {
-	var path string
+	path, err := defaultDBPath()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := openUnmigratedDB(path)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Migrate()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// defaultDBPath is the db in tuber's volume when there is one, or ./localbolt
+func defaultDBPath() (string, error) {
 	if _, err := os.Stat("/etc/tuber-bolt"); os.IsNotExist(err) {
 		wd, err := os.Getwd()
 		if err != nil {
-			return nil, err
+			return "", err
 		}
-
-		path = wd + "/localbolt"
-	} else {
-		path = "/etc/tuber-bolt/db"
+		return wd + "/localbolt", nil
 	}
+	return "/etc/tuber-bolt/db", nil
+}
 
-	database, err := tuberbolt.NewDefaultDB(path, model.TuberApp{}.DBRoot())
+// openUnmigratedDB opens a db as it is, for looking at its migrations before running them
+func openUnmigratedDB(path string) (*core.DB, error) {
+	database, err := tuberbolt.NewDefaultDB(path, model.TuberApp{}.DBRoot(), model.InboxEvent{}.DBRoot(), model.FreezeWindow{}.DBRoot(), model.ClusterState{}.DBRoot(), model.PendingRelease{}.DBRoot(), model.WebhookDelivery{}.DBRoot(), model.AuditEntry{}.DBRoot(), model.APIToken{}.DBRoot())
 	if err != nil {
 		return nil, err
 	}
@@ -81,8 +108,10 @@ func getApp(appName string) (*model.TuberApp, error) {
 				imageTag
 				name
 				paused
+				requireApproval
 				reviewApp
 				currentTags
+				currentRevision
 				githubRepo
 				reviewAppsConfig{
 					enabled
@@ -96,6 +125,14 @@ func getApp(appName string) (*model.TuberApp, error) {
 					}
 				}
 				slackChannel
+				notificationTargets {
+					type
+					target
+				}
+				webhooks {
+					url
+					events
+				}
 				sourceAppName
 				state {
 					Current {
@@ -145,7 +182,7 @@ func clusterData() (*core.ClusterData, error) {
 	adminHost := viper.GetString("TUBER_CLUSTER_ADMIN_HOST")
 
 	if defaultGateway == "" || defaultHost == "" || adminGateway == "" || adminHost == "" {
-		config, err := k8s.GetSecret("tuber", "tuber-env")
+		config, err := k8s.GetSecret(context.Background(), "tuber", "tuber-env")
 		if err != nil {
 			return nil, err
 		}
@@ -179,7 +216,7 @@ func credentials() ([]byte, error) {
 	creds, err := ioutil.ReadFile(credentialsPath)
 
 	if err != nil {
-		config, err := k8s.GetSecret("tuber", "tuber-credentials.json")
+		config, err := k8s.GetSecret(context.Background(), "tuber", "tuber-credentials.json")
 		if err != nil {
 			return nil, fmt.Errorf("error while running k8s.GetSecret: %v", err)
 		}
@@ -189,6 +226,105 @@ func credentials() ([]byte, error) {
 	return creds, nil
 }
 
+// auditRetention is how long audit entries are kept
+func auditRetention() time.Duration {
+	viper.SetDefault("TUBER_AUDIT_RETENTION", "2160h")
+	return viper.GetDuration("TUBER_AUDIT_RETENTION")
+}
+
+// approvalTTL is how long a release awaiting approval can be approved for
+func approvalTTL() time.Duration {
+	viper.SetDefault("TUBER_APPROVAL_TTL", "24h")
+	return viper.GetDuration("TUBER_APPROVAL_TTL")
+}
+
+// backupScheduler backs the db up every TUBER_BACKUP_INTERVAL, to TUBER_BACKUP_DIR or TUBER_BACKUP_SECRET,
+// keeping the last TUBER_BACKUP_RETENTION backups. It's nil when no interval is set.
+func backupScheduler(ctx context.Context, logger *zap.Logger, db *core.DB) (*backup.Scheduler, error) {
+	interval := viper.GetDuration("TUBER_BACKUP_INTERVAL")
+	if interval == 0 {
+		return nil, nil
+	}
+
+	viper.SetDefault("TUBER_BACKUP_RETENTION", 7)
+	viper.SetDefault("TUBER_BACKUP_NAMESPACE", "tuber")
+	retention := viper.GetInt("TUBER_BACKUP_RETENTION")
+	if retention < 1 {
+		return nil, fmt.Errorf("TUBER_BACKUP_RETENTION must keep at least one backup")
+	}
+
+	var target backup.Target
+	switch {
+	case viper.GetString("TUBER_BACKUP_DIR") != "":
+		target = backup.NewDir(viper.GetString("TUBER_BACKUP_DIR"))
+	case viper.GetString("TUBER_BACKUP_SECRET") != "":
+		target = backup.NewSecret(viper.GetString("TUBER_BACKUP_SECRET"), viper.GetString("TUBER_BACKUP_NAMESPACE"))
+	default:
+		return nil, fmt.Errorf("TUBER_BACKUP_INTERVAL is set, but neither TUBER_BACKUP_DIR nor TUBER_BACKUP_SECRET are")
+	}
+
+	return backup.NewScheduler(ctx, logger, db, target, interval, retention), nil
+}
+
+// notifiers sends to slack, webhooks and teams, and to email if TUBER_SMTP_ADDR is set
+func notifiers() *notify.Notifiers {
+	var email notify.Notifier
+	if viper.GetString("TUBER_SMTP_ADDR") != "" {
+		email = notify.NewEmail(viper.GetString("TUBER_SMTP_ADDR"), viper.GetString("TUBER_SMTP_FROM"), viper.GetString("TUBER_SMTP_USERNAME"), viper.GetString("TUBER_SMTP_PASSWORD"))
+	}
+
+	slackClient := slack.New(viper.GetString("TUBER_SLACK_TOKEN"), viper.GetBool("TUBER_SLACK_ENABLED"), viper.GetString("TUBER_SLACK_CATCHALL_CHANNEL"))
+	hosts := webhookHosts()
+	return notify.New(notify.NewSlack(slackClient), notify.NewWebhook(hosts), notify.NewTeams(hosts), email)
+}
+
+// githubClient is nil unless TUBER_GITHUB_TOKEN is set, which turns off github deployments.
+// TUBER_GITHUB_API_URL points it at github enterprise, or anything else speaking github's api.
+// Deployments are named for TUBER_CLUSTER_NAME, and marked as production ones when TUBER_GITHUB_PRODUCTION is set.
+func githubClient() *github.Client {
+	if viper.GetString("TUBER_GITHUB_TOKEN") == "" {
+		return nil
+	}
+	return github.New(viper.GetString("TUBER_GITHUB_API_URL"), viper.GetString("TUBER_GITHUB_TOKEN"))
+}
+
+// webhookHosts allows app webhooks and notification targets to reach internal hosts listed in TUBER_WEBHOOK_ALLOWED_HOSTS, e.g. TUBER_WEBHOOK_ALLOWED_HOSTS=deploys.tools.svc.cluster.local
+func webhookHosts() *notify.WebhookHosts {
+	return notify.NewWebhookHosts(strings.Split(viper.GetString("TUBER_WEBHOOK_ALLOWED_HOSTS"), ","))
+}
+
+// lifecyclePublisher publishes release lifecycle events to app webhooks, and to TUBER_LIFECYCLE_EVENTS_TOPIC if it's configured.
+// TUBER_EVENTS_TOPIC keeps getting only the completed message, for its existing subscribers. Both topics are in TUBER_EVENTS_PROJECT.
+// Running locally, events are kept in memory instead of going to pubsub.
+func lifecyclePublisher(ctx context.Context, logger *zap.Logger, db *core.DB, creds []byte, local bool) (events.Publisher, error) {
+	publishers := events.Publishers{events.NewWebhookPublisher(ctx, logger, db, webhookHosts(), events.DefaultWebhookAttempts, events.DefaultWebhookBackoff)}
+	if local {
+		return append(publishers, events.NewLocalPublisher(logger)), nil
+	}
+
+	project := viper.GetString("TUBER_EVENTS_PROJECT")
+	if project == "" {
+		return publishers, nil
+	}
+
+	if topic := viper.GetString("TUBER_EVENTS_TOPIC"); topic != "" {
+		publisher, err := events.NewCompletedPublisher(ctx, logger, project, topic, creds)
+		if err != nil {
+			return nil, err
+		}
+		publishers = append(publishers, publisher)
+	}
+
+	if topic := viper.GetString("TUBER_LIFECYCLE_EVENTS_TOPIC"); topic != "" {
+		publisher, err := events.NewPubsubPublisher(ctx, logger, project, topic, creds)
+		if err != nil {
+			return nil, err
+		}
+		publishers = append(publishers, publisher)
+	}
+	return publishers, nil
+}
+
 func checkAuth(audience string) error {
 	exists, err := iap.RefreshTokenExists(audience)
 	if err != nil {
@@ -303,7 +439,7 @@ func fetchPodname() (string, error) {
 		return pod, nil
 	}
 	template := `{{range $k, $v := $.spec.selector.matchLabels}}{{$k}}={{$v}},{{end}}`
-	l, err := k8s.Get("deployment", fetchWorkload(), appNameFlag, "-o", "go-template", "--template", template)
+	l, err := k8s.Get(context.Background(), "deployment", fetchWorkload(), appNameFlag, "-o", "go-template", "--template", template)
 	if err != nil {
 		return "", err
 	}
@@ -311,7 +447,7 @@ func fetchPodname() (string, error) {
 	labels := strings.TrimSuffix(string(l), ",")
 
 	jsonPath := fmt.Sprintf(`-o=jsonpath="%s"`, `{.items[0].metadata.name}`)
-	podNameByte, err := k8s.GetCollection("pods", appNameFlag, "-l", labels, jsonPath)
+	podNameByte, err := k8s.GetCollection(context.Background(), "pods", appNameFlag, "-l", labels, jsonPath)
 	if err != nil {
 		return "", err
 	}
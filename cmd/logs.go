@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/freshly/tuber/graph/model"
+	"github.com/spf13/cobra"
+)
+
+var logsSinceFlag string
+var logsTailFlag int
+var logsFollowFlag bool
+
+var logsCmd = &cobra.Command{
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Use:           "logs -a [appName] -w [specific workload] -p [specific pod] -c [specific container]",
+	Short:         "print an app's logs, from every pod of a workload",
+	Long: `print an app's logs, from every pod of a workload, each prefixed with its pod and container.
+The workload is the deployment named after the app unless -w is given, which also takes kind/name for statefulsets and daemonsets.`,
+	Args:    cobra.NoArgs,
+	PreRunE: displayCurrentContext,
+	RunE:    runLogs,
+}
+
+func runLogs(cmd *cobra.Command, args []string) error {
+	graphql, err := gqlClient()
+	if err != nil {
+		return err
+	}
+
+	prefixes := logPrefixes{}
+
+	if logsFollowFlag {
+		gql := fmt.Sprintf(`
+			subscription {
+				logs(input: %s) {
+					pod
+					container
+					message
+					error
+				}
+			}
+		`, logsInput())
+
+		return graphql.Subscribe(context.Background(), gql, func(data json.RawMessage) (bool, error) {
+			var respData struct {
+				Logs *model.LogLine
+			}
+			err := json.Unmarshal(data, &respData)
+			if err != nil {
+				return false, err
+			}
+			if respData.Logs.Error != nil {
+				return true, fmt.Errorf("log stream failed: %s", *respData.Logs.Error)
+			}
+			prefixes.print(respData.Logs)
+			return false, nil
+		})
+	}
+
+	gql := fmt.Sprintf(`
+		query {
+			getLogs(input: %s) {
+				pod
+				container
+				message
+			}
+		}
+	`, logsInput())
+
+	var respData struct {
+		GetLogs []*model.LogLine
+	}
+
+	err = graphql.Query(context.Background(), gql, &respData)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range respData.GetLogs {
+		prefixes.print(line)
+	}
+	return nil
+}
+
+// logsInput is the LogsInput literal for the flags given
+func logsInput() string {
+	fields := []string{"appName: " + strconv.Quote(appNameFlag)}
+	for name, value := range map[string]string{"workload": workload, "pod": pod, "container": container, "since": logsSinceFlag} {
+		if value != "" {
+			fields = append(fields, name+": "+strconv.Quote(value))
+		}
+	}
+	if logsTailFlag != 0 {
+		fields = append(fields, "tail: "+strconv.Itoa(logsTailFlag))
+	}
+	return "{" + strings.Join(fields, ", ") + "}"
+}
+
+var logColors = []color.Attribute{color.FgCyan, color.FgGreen, color.FgYellow, color.FgMagenta, color.FgBlue, color.FgHiCyan, color.FgHiGreen, color.FgHiYellow, color.FgHiMagenta, color.FgHiBlue}
+
+// logPrefixes gives each pod's lines their own color, so interleaved pods are easy to tell apart
+type logPrefixes map[string]*color.Color
+
+func (l logPrefixes) print(line *model.LogLine) {
+	if line.Pod == "" {
+		fmt.Println(line.Message)
+		return
+	}
+
+	prefix, ok := l[line.Pod]
+	if !ok {
+		prefix = color.New(logColors[len(l)%len(logColors)])
+		l[line.Pod] = prefix
+	}
+
+	source := line.Pod
+	if line.Container != "" {
+		source += "/" + line.Container
+	}
+	fmt.Printf("%s %s\n", prefix.Sprintf("[%s]", source), line.Message)
+}
+
+func init() {
+	logsCmd.Flags().StringVarP(&appNameFlag, "app", "a", "", "app name (required)")
+	logsCmd.Flags().StringVarP(&workload, "workload", "w", "", "specify a workload if it's not the deployment named after your app, as name or kind/name")
+	logsCmd.Flags().StringVarP(&pod, "pod", "p", "", "only a specific pod's logs")
+	logsCmd.Flags().StringVarP(&container, "container", "c", "", "only a specific container's logs (all containers by default)")
+	logsCmd.Flags().StringVar(&logsSinceFlag, "since", "", "only logs newer than a duration, like 10m")
+	logsCmd.Flags().IntVar(&logsTailFlag, "tail", 0, "only the last lines of each container's logs (1000 by default)")
+	logsCmd.Flags().BoolVarP(&logsFollowFlag, "follow", "f", false, "keep streaming logs as they're written")
+	logsCmd.MarkFlagRequired("app")
+	rootCmd.AddCommand(logsCmd)
+}
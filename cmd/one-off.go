@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"html/template"
 	"strings"
@@ -71,25 +72,25 @@ func runAppsSetup(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	err = k8s.Apply(buf.Bytes(), app.Name)
+	err = k8s.Apply(context.Background(), buf.Bytes(), app.Name)
 	if err != nil {
 		return err
 	}
 
-	err = core.WaitForPhase(podName, "pod", app, 5*time.Minute)
+	err = core.WaitForPhase(context.Background(), podName, "pod", app, 5*time.Minute)
 	if err != nil {
 		return err
 	}
 
 	if err != nil {
-		deleteErr := k8s.Delete("pod", podName, app.Name)
+		deleteErr := k8s.Delete(context.Background(), "pod", podName, app.Name)
 		if deleteErr != nil {
 			return fmt.Errorf(err.Error() + "\n also failed delete:" + deleteErr.Error())
 		}
 		return err
 	}
 
-	err = k8s.Delete("pod", podName, app.Name)
+	err = k8s.Delete(context.Background(), "pod", podName, app.Name)
 	if err != nil {
 		return err
 	}
@@ -2,19 +2,28 @@ package cmd
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/freshly/tuber/graph/model"
 
 	"github.com/spf13/cobra"
 )
 
+var pauseAllFlag bool
+var pauseReasonFlag string
+
 var pauseCmd = &cobra.Command{
 	SilenceUsage: true,
 	Use:          "pause [app name]",
-	Short:        "pause deploys for the specified app",
-	Args:         cobra.ExactArgs(1),
+	Short:        "pause deploys for the specified app, or every release on the cluster with --all",
+	Args:         pauseResumeArgs(&pauseAllFlag),
 	PreRunE:      promptCurrentContext,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if pauseAllFlag {
+			return pauseCluster()
+		}
+
 		appName := args[0]
 		graphql, err := gqlClient()
 		if err != nil {
@@ -43,6 +52,54 @@ var pauseCmd = &cobra.Command{
 	},
 }
 
+func pauseCluster() error {
+	if pauseReasonFlag == "" {
+		return errors.New("--reason is required with --all")
+	}
+
+	graphql, err := gqlClient()
+	if err != nil {
+		return err
+	}
+
+	input := &model.PauseClusterInput{
+		Reason: pauseReasonFlag,
+	}
+
+	var respData struct {
+		PauseCluster *model.ClusterInfo
+	}
+
+	gql := `
+		mutation($input: PauseClusterInput!) {
+			pauseCluster(input: $input) {
+				name
+				pausedBy
+			}
+		}
+	`
+
+	err = graphql.Mutation(context.Background(), gql, nil, input, &respData)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("all releases on %s are paused\n", respData.PauseCluster.Name)
+	return nil
+}
+
+// pauseResumeArgs takes an app name, or none when acting on the whole cluster
+func pauseResumeArgs(all *bool) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if *all {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	}
+}
+
 func init() {
+	pauseCmd.Flags().BoolVar(&pauseAllFlag, "all", false, "pause every automatic and manual release on the cluster")
+	pauseCmd.Flags().StringVar(&pauseReasonFlag, "reason", "", "why the cluster is paused, required with --all")
 	rootCmd.AddCommand(pauseCmd)
 }
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/freshly/tuber/pkg/core"
@@ -18,7 +19,7 @@ var plantCmd = &cobra.Command{
 }
 
 func plant(cmd *cobra.Command, args []string) error {
-	existsAlready, err := k8s.Exists("namespace", "tuber", "tuber")
+	existsAlready, err := k8s.Exists(context.Background(), "namespace", "tuber", "tuber")
 	if err != nil {
 		return err
 	}
@@ -28,27 +29,27 @@ func plant(cmd *cobra.Command, args []string) error {
 	}
 
 	credentialsPath := args[0]
-	err = core.NewAppSetup("tuber", false)
+	err = core.NewAppSetup(context.Background(), "tuber", false)
 	if err != nil {
 		return err
 	}
 
-	err = k8s.Create("tuber", "configmap", "tuber-apps")
+	err = k8s.Create(context.Background(), "tuber", "configmap", "tuber-apps")
 	if err != nil {
 		return err
 	}
 
-	err = k8s.Create("tuber", "configmap", "tuber-repos")
+	err = k8s.Create(context.Background(), "tuber", "configmap", "tuber-repos")
 	if err != nil {
 		return err
 	}
 
-	err = k8s.Create("tuber", "configmap", "tuber-review-triggers")
+	err = k8s.Create(context.Background(), "tuber", "configmap", "tuber-review-triggers")
 	if err != nil {
 		return err
 	}
 
-	return k8s.CreateTuberCredentials(credentialsPath, "tuber")
+	return k8s.CreateTuberCredentials(context.Background(), credentialsPath, "tuber")
 }
 
 func init() {
@@ -2,18 +2,25 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/freshly/tuber/graph/model"
 	"github.com/spf13/cobra"
 )
 
+var resumeAllFlag bool
+
 var resumeCmd = &cobra.Command{
 	SilenceUsage: true,
 	Use:          "resume [app name]",
-	Short:        "resume deploys for the specified app",
-	Args:         cobra.ExactArgs(1),
+	Short:        "resume deploys for the specified app, or releases on the cluster with --all",
+	Args:         pauseResumeArgs(&resumeAllFlag),
 	PreRunE:      promptCurrentContext,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if resumeAllFlag {
+			return resumeCluster()
+		}
+
 		appName := args[0]
 		graphql, err := gqlClient()
 		if err != nil {
@@ -42,6 +49,35 @@ var resumeCmd = &cobra.Command{
 	},
 }
 
+// resumeCluster lifts the cluster-wide pause. Apps paused individually stay paused.
+func resumeCluster() error {
+	graphql, err := gqlClient()
+	if err != nil {
+		return err
+	}
+
+	var respData struct {
+		ResumeCluster *model.ClusterInfo
+	}
+
+	gql := `
+		mutation {
+			resumeCluster {
+				name
+			}
+		}
+	`
+
+	err = graphql.Query(context.Background(), gql, &respData)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("releases on %s are resumed\n", respData.ResumeCluster.Name)
+	return nil
+}
+
 func init() {
+	resumeCmd.Flags().BoolVar(&resumeAllFlag, "all", false, "resume releases on the cluster, leaving individually paused apps paused")
 	rootCmd.AddCommand(resumeCmd)
 }
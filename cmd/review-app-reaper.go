@@ -27,20 +27,18 @@ func runReviewAppReaper(cmd *cobra.Command, args []string) error {
 	if !viper.GetBool("TUBER_REVIEWAPPS_ENABLED") {
 		return nil
 	}
-	out, err := k8s.GetCollection("secrets", "tuber", `-o=jsonpath='{.items[?(@.metadata.annotations.kubernetes\.io/service-account\.name=="tuber")].data.token}'`)
-	if err != nil {
-		return err
-	}
+	client := graph.NewClient("http://tuber.tuber:3000", viper.GetString("TUBER_OAUTH_WEB_CLIENT_ID"))
 
-	decoded, err := base64.StdEncoding.DecodeString(strings.Trim(string(out), "\r\n'"))
-	if err != nil {
-		return err
+	// an api token scoped to every app with the reviewapps scope, from TUBER_API_TOKEN, is preferred over tuber's own service account
+	if client.APIToken == "" {
+		token, err := serviceAccountToken()
+		if err != nil {
+			return err
+		}
+		client.IntraCluster = true
+		client.IntraClusterToken = token
 	}
 
-	client := graph.NewClient("http://tuber.tuber:3000", viper.GetString("TUBER_OAUTH_WEB_CLIENT_ID"))
-	client.IntraCluster = true
-	client.IntraClusterToken = string(decoded)
-
 	var allReviewApps struct {
 		GetAllReviewApps []*model.TuberApp
 	}
@@ -53,7 +51,7 @@ func runReviewAppReaper(cmd *cobra.Command, args []string) error {
 			}
 		}
 	`
-	err = client.Query(context.Background(), gql, &allReviewApps)
+	err := client.Query(context.Background(), gql, &allReviewApps)
 	if err != nil {
 		return err
 	}
@@ -90,6 +88,19 @@ func runReviewAppReaper(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func serviceAccountToken() (string, error) {
+	out, err := k8s.GetCollection(context.Background(), "secrets", "tuber", `-o=jsonpath='{.items[?(@.metadata.annotations.kubernetes\.io/service-account\.name=="tuber")].data.token}'`)
+	if err != nil {
+		return "", err
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.Trim(string(out), "\r\n'"))
+	if err != nil {
+		return "", err
+	}
+	return string(decoded), nil
+}
+
 func init() {
 	rootCmd.AddCommand(reviewAppReaperCmd)
 }
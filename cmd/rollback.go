@@ -16,7 +16,8 @@ var rollbackCmd = &cobra.Command{
 	PreRunE:       promptCurrentContext,
 	Args:          cobra.ExactArgs(1),
 	Long: `immediately rolls back to the resources (and image) applied during the last successful release, without monitoring for success.
-Can be used to abort a running release as well, as tuber's definition of 'last successful release' is not updated until a running release finishes successfully.`,
+Can be used to abort a running release as well, as tuber's definition of 'last successful release' is not updated until a running release finishes successfully.
+With --follow, waits for the rolled back workloads to roll out.`,
 }
 
 func runRollback(cmd *cobra.Command, args []string) error {
@@ -28,7 +29,7 @@ func runRollback(cmd *cobra.Command, args []string) error {
 	gql := `
 		mutation($input: AppInput!) {
 			rollback(input: $input) {
-				name
+				releaseId
 			}
 		}
 	`
@@ -38,12 +39,26 @@ func runRollback(cmd *cobra.Command, args []string) error {
 	}
 
 	var respData struct {
-		rollback *model.TuberApp
+		Rollback *model.StartedRelease
 	}
 
-	return graphql.Mutation(context.Background(), gql, nil, input, &respData)
+	mutation := func() (string, error) {
+		err := graphql.Mutation(context.Background(), gql, nil, input, &respData)
+		if err != nil {
+			return "", err
+		}
+		return respData.Rollback.ReleaseID, nil
+	}
+	if rollbackFollowFlag {
+		return followRelease(appName, mutation)
+	}
+	_, err = mutation()
+	return err
 }
 
+var rollbackFollowFlag bool
+
 func init() {
+	rollbackCmd.Flags().BoolVarP(&rollbackFollowFlag, "follow", "f", false, "follow the rollback's rollout until it finishes, exiting non-zero if it fails")
 	rootCmd.AddCommand(rollbackCmd)
 }
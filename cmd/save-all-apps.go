@@ -11,6 +11,7 @@ var saveAllAppsCmd = &cobra.Command{
 	Hidden:       true,
 	Use:          "save-all-apps",
 	Short:        "general migration tool - internal, hidden, but also, optimistically, always safe",
+	Deprecated:   "model changes are migrations now, which tuber runs on startup - see tuber db migrations",
 	Args:         cobra.NoArgs,
 	PreRunE:      promptCurrentContext,
 	RunE:         runSaveAllAppsCmd,
@@ -5,12 +5,14 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/freshly/tuber/pkg/builds"
+	"github.com/freshly/tuber/pkg/core"
 	"github.com/freshly/tuber/pkg/events"
 	"github.com/freshly/tuber/pkg/pubsub"
 	"github.com/freshly/tuber/pkg/report"
-	"github.com/freshly/tuber/pkg/slack"
+	"github.com/freshly/tuber/pkg/tracing"
 	"github.com/getsentry/sentry-go"
 
 	"github.com/spf13/cobra"
@@ -25,7 +27,15 @@ func init() {
 var startCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Start tuber's pub/sub server",
-	RunE:  start,
+	Long: `Start tuber's pub/sub server.
+
+Set TUBER_EVENT_SOURCE=local to run without google pubsub. Image and cloud build messages are then
+published with "tuber events publish image|build", or posted to the admin server at <prefix>/events/local/images and /builds.
+Publishing requires TUBER_LOCAL_EVENTS_TOKEN, sent as a bearer token - without it the routes aren't served.
+
+Set TUBER_OTLP_ENDPOINT (host:port) to export traces of event intake and releases over OTLP/HTTP, with
+TUBER_OTLP_INSECURE=true for a plain http collector. Standard OTEL_EXPORTER_OTLP_* env vars also apply.`,
+	RunE: start,
 }
 
 // Attaches interrupt and terminate signals to a cancel function
@@ -67,56 +77,111 @@ func start(cmd *cobra.Command, args []string) error {
 
 	bindShutdown(logger, cancel)
 
+	local := viper.GetString("TUBER_EVENT_SOURCE") == "local"
+	if local {
+		startupLogger.Info("using local event sources, google pubsub is disabled")
+	}
+
 	creds, err := credentials()
 	if err != nil {
 		startupLogger.Warn("failed to get credentials", zap.Error(err))
-		report.Error(err, scope.WithContext("getting credentials"))
-		panic(err)
+		if !local {
+			report.Error(err, scope.WithContext("getting credentials"))
+			panic(err)
+		}
 	}
 
 	data, err := clusterData()
 	if err != nil {
 		startupLogger.Warn("failed to get cluster data", zap.Error(err))
-		report.Error(err, scope.WithContext("getting cluster data"))
-		panic(err)
+		if !local {
+			report.Error(err, scope.WithContext("getting cluster data"))
+			panic(err)
+		}
+		data = &core.ClusterData{}
 	}
 
-	slackClient := slack.New(viper.GetString("TUBER_SLACK_TOKEN"), viper.GetBool("TUBER_SLACK_ENABLED"), viper.GetString("TUBER_SLACK_CATCHALL_CHANNEL"))
-	processor := events.NewProcessor(ctx, logger, db, creds, data, viper.GetBool("TUBER_REVIEWAPPS_ENABLED"), slackClient, viper.GetString("TUBER_SENTRY_BEARER_TOKEN"), viper.GetString("TUBER_EVENTS_PROJECT"), viper.GetString("TUBER_EVENTS_TOPIC"))
-	listener, err := pubsub.NewListener(
-		ctx,
-		logger,
-		viper.GetString("TUBER_PUBSUB_PROJECT"),
-		viper.GetString("TUBER_PUBSUB_SUBSCRIPTION_NAME"),
-		creds,
-		data,
-		processor,
-	)
+	shutdownTracing, err := tracing.Init(ctx, viper.GetString("TUBER_OTLP_ENDPOINT"), viper.GetBool("TUBER_OTLP_INSECURE"), viper.GetString("TUBER_CLUSTER_NAME"))
+	if err != nil {
+		startupLogger.Warn("failed to initialize tracing", zap.Error(err))
+		report.Error(err, scope.WithContext("initialize tracing"))
+		panic(err)
+	}
+	defer func() {
+		// ctx is cancelled by now, give the last spans a moment to flush
+		flushCtx, cancelFlush := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancelFlush()
+		_ = shutdownTracing(flushCtx)
+	}()
 
+	publisher, err := lifecyclePublisher(ctx, logger, db, creds, local)
 	if err != nil {
-		startupLogger.Warn("failed to initialize listener", zap.Error(err))
-		report.Error(err, scope.WithContext("initialize listener"))
+		startupLogger.Warn("failed to initialize lifecycle event publisher", zap.Error(err))
+		report.Error(err, scope.WithContext("initialize lifecycle event publisher"))
 		panic(err)
 	}
 
-	go startAdminServer(ctx, db, processor, logger, creds)
-
-	buildEventProcessor := builds.NewProcessor(ctx, logger, db, slackClient)
-	buildListener, err := pubsub.NewListener(
-		ctx,
-		logger,
-		viper.GetString("TUBER_PUBSUB_PROJECT"),
-		viper.GetString("TUBER_PUBSUB_CLOUDBUILD_SUBSCRIPTION_NAME"),
-		creds,
-		data,
-		buildEventProcessor,
-	)
+	notifiers := notifiers()
+	processor := events.NewProcessor(ctx, logger, db, creds, data, viper.GetBool("TUBER_REVIEWAPPS_ENABLED"), notifiers, viper.GetString("TUBER_SENTRY_BEARER_TOKEN"), publisher, approvalTTL(), githubClient(), viper.GetString("TUBER_CLUSTER_NAME"), viper.GetBool("TUBER_GITHUB_PRODUCTION"))
+	inbox := events.NewInbox(ctx, logger, db, processor)
+	buildEventProcessor := builds.NewProcessor(ctx, logger, db, notifiers)
+
+	var listener, buildListener pubsub.Source
+	var localSources map[string]*pubsub.LocalSource
+	if local {
+		token := viper.GetString("TUBER_LOCAL_EVENTS_TOKEN")
+		imageSource := pubsub.NewLocalSource(ctx, logger, pubsub.LocalImages, token, inbox)
+		buildSource := pubsub.NewLocalSource(ctx, logger, pubsub.LocalBuilds, token, buildEventProcessor)
+		if token != "" {
+			localSources = map[string]*pubsub.LocalSource{pubsub.LocalImages: imageSource, pubsub.LocalBuilds: buildSource}
+		} else {
+			startupLogger.Warn("TUBER_LOCAL_EVENTS_TOKEN is unset, local events can't be published")
+		}
+		listener, buildListener = imageSource, buildSource
+	} else {
+		listener, err = pubsub.NewListener(
+			ctx,
+			logger,
+			viper.GetString("TUBER_PUBSUB_PROJECT"),
+			viper.GetString("TUBER_PUBSUB_SUBSCRIPTION_NAME"),
+			creds,
+			data,
+			inbox,
+		)
+		if err != nil {
+			startupLogger.Warn("failed to initialize listener", zap.Error(err))
+			report.Error(err, scope.WithContext("initialize listener"))
+			panic(err)
+		}
+
+		buildListener, err = pubsub.NewListener(
+			ctx,
+			logger,
+			viper.GetString("TUBER_PUBSUB_PROJECT"),
+			viper.GetString("TUBER_PUBSUB_CLOUDBUILD_SUBSCRIPTION_NAME"),
+			creds,
+			data,
+			buildEventProcessor,
+		)
+		if err != nil {
+			startupLogger.Error("failed to start cloud build listener", zap.Error(err))
+			report.Error(err, scope.WithContext("initialize cloud build listener"))
+			panic(err)
+		}
+	}
+
+	backups, err := backupScheduler(ctx, logger, db)
 	if err != nil {
-		startupLogger.Error("failed to start cloud build listener", zap.Error(err))
-		report.Error(err, scope.WithContext("initialize cloud build listener"))
+		startupLogger.Warn("failed to initialize scheduled backups", zap.Error(err))
+		report.Error(err, scope.WithContext("initialize scheduled backups"))
 		panic(err)
 	}
+	if backups != nil {
+		go backups.Start()
+	}
 
+	go inbox.Start()
+	go startAdminServer(ctx, db, processor, inbox, localSources, logger, creds)
 	go buildListener.Start()
 
 	err = listener.Start()
@@ -8,7 +8,6 @@ import (
 	"github.com/freshly/tuber/pkg/builds"
 	"github.com/freshly/tuber/pkg/pubsub"
 	"github.com/freshly/tuber/pkg/report"
-	"github.com/freshly/tuber/pkg/slack"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
@@ -75,9 +74,7 @@ func startBuilds(cmd *cobra.Command, args []string) error {
 	fmt.Println(viper.GetString("TUBER_PUBSUB_PROJECT"))
 	fmt.Println(viper.GetString("TUBER_PUBSUB_CLOUDBUILD_SUBSCRIPTION_NAME"))
 
-	slackClient := slack.New(viper.GetString("TUBER_SLACK_TOKEN"), viper.GetBool("TUBER_SLACK_ENABLED"), viper.GetString("TUBER_SLACK_CATCHALL_CHANNEL"))
-
-	buildEventProcessor := builds.NewProcessor(ctx, logger, db, slackClient)
+	buildEventProcessor := builds.NewProcessor(ctx, logger, db, notifiers())
 	buildListener, err := pubsub.NewListener(
 		ctx,
 		logger,
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 
@@ -50,7 +51,7 @@ func switchCluster(cmd *cobra.Command, args []string) error {
 	}
 
 	if k8sPresent {
-		err = k8s.UseCluster(cluster.Name)
+		err = k8s.UseCluster(context.Background(), cluster.Name)
 		if err != nil {
 			return err
 		}
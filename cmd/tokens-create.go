@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/spf13/cobra"
+)
+
+var tokensCreateAppsFlag []string
+var tokensCreateScopesFlag []string
+
+var tokensCreateCmd = &cobra.Command{
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Use:           "create [name]",
+	Short:         "create an api token, printing it once - only its hash is kept",
+	Long: fmt.Sprintf(`create an api token, printing it once - only its hash is kept.
+Tokens can read the apps they're scoped to, and scopes add what they can change: %s.
+deploy deploys and rolls back, reviewapps creates and destroys review apps, env:read and env:write are the app's env.
+Changing an app's settings, webhooks or notifications, and approving releases, are for people only.
+Automation sends it as a bearer token, or uses it with TUBER_API_TOKEN set.`, strings.Join(model.APITokenScopes, ", ")),
+	Example: `  tuber tokens create potatoes-ci --apps potatoes --scopes deploy
+  tuber tokens create review-app-reaper --apps "*" --scopes reviewapps`,
+	Args:    cobra.ExactArgs(1),
+	PreRunE: promptCurrentContext,
+	RunE:    runTokensCreateCmd,
+}
+
+func runTokensCreateCmd(cmd *cobra.Command, args []string) error {
+	graphql, err := gqlClient()
+	if err != nil {
+		return err
+	}
+
+	input := &model.APITokenInput{
+		Name:   args[0],
+		Apps:   tokensCreateAppsFlag,
+		Scopes: tokensCreateScopesFlag,
+	}
+
+	var respData struct {
+		CreateAPIToken *model.CreatedAPIToken
+	}
+
+	gql := `
+			mutation($input: APITokenInput!) {
+				createAPIToken(input: $input) {
+					token
+					apiToken {
+						id
+					}
+				}
+			}
+		`
+
+	err = graphql.Mutation(context.Background(), gql, nil, input, &respData)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(respData.CreateAPIToken.Token)
+	return nil
+}
+
+func init() {
+	tokensCreateCmd.Flags().StringSliceVar(&tokensCreateAppsFlag, "apps", []string{}, `apps the token is scoped to, or "*" for every app (required)`)
+	tokensCreateCmd.Flags().StringSliceVar(&tokensCreateScopesFlag, "scopes", []string{}, "what the token can change on its apps")
+	tokensCreateCmd.MarkFlagRequired("apps")
+	tokensCmd.AddCommand(tokensCreateCmd)
+}
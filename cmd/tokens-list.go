@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var tokensListJsonFlag bool
+
+var tokensListCmd = &cobra.Command{
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Use:           "list",
+	Short:         "List api tokens",
+	PreRunE:       displayCurrentContext,
+	RunE:          runTokensListCmd,
+}
+
+func runTokensListCmd(*cobra.Command, []string) error {
+	graphql, err := gqlClient()
+	if err != nil {
+		return err
+	}
+
+	gql := `
+			query {
+				getAPITokens {
+					id
+					name
+					apps
+					scopes
+					createdBy
+					createdAt
+					lastUsedAt
+				}
+			}
+		`
+
+	var respData struct {
+		GetAPITokens []*model.APIToken
+	}
+
+	if err := graphql.Query(context.Background(), gql, &respData); err != nil {
+		return err
+	}
+
+	tokens := respData.GetAPITokens
+
+	if tokensListJsonFlag {
+		out, err := json.Marshal(tokens)
+		if err != nil {
+			return err
+		}
+
+		os.Stdout.Write(out)
+		return nil
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"ID", "Name", "Apps", "Scopes", "Created By", "Created At", "Last Used"})
+	table.SetBorder(false)
+
+	for _, token := range tokens {
+		lastUsed := token.LastUsedAt
+		if lastUsed == "" {
+			lastUsed = "never"
+		}
+		table.Append([]string{token.ID, token.Name, strings.Join(token.Apps, ", "), strings.Join(token.Scopes, ", "), token.CreatedBy, token.CreatedAt, lastUsed})
+	}
+
+	table.Render()
+	return nil
+}
+
+func init() {
+	tokensListCmd.Flags().BoolVar(&tokensListJsonFlag, "json", false, "output as json")
+	tokensCmd.AddCommand(tokensListCmd)
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/freshly/tuber/graph"
+	"github.com/freshly/tuber/graph/model"
+	"github.com/spf13/cobra"
+)
+
+var tokensRevokeCmd = &cobra.Command{
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Use:           "revoke [id]",
+	Short:         "revoke an api token, by the id from tuber tokens list",
+	Args:          cobra.ExactArgs(1),
+	PreRunE:       promptCurrentContext,
+	RunE:          runTokensRevokeCmd,
+}
+
+func runTokensRevokeCmd(cmd *cobra.Command, args []string) error {
+	graphql, err := gqlClient()
+	if err != nil {
+		return err
+	}
+
+	var respData struct {
+		RevokeAPIToken *model.APIToken
+	}
+
+	gql := `
+			mutation($id: ID!) {
+				revokeAPIToken(id: $id) {
+					id
+				}
+			}
+		`
+
+	return graphql.Query(context.Background(), gql, &respData, graph.WithVar("id", args[0]))
+}
+
+func init() {
+	tokensCmd.AddCommand(tokensRevokeCmd)
+}
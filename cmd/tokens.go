@@ -0,0 +1,14 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var tokensCmd = &cobra.Command{
+	Use:   "tokens [command]",
+	Short: "A root command for api tokens, which ci and automation authenticate with instead of a google account",
+}
+
+func init() {
+	rootCmd.AddCommand(tokensCmd)
+}
@@ -0,0 +1,43 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/oauth"
+)
+
+// Token scopes for checks that aren't a scope of their own. Every check names the scope an api token needs to pass it.
+const (
+	// apiTokensDenied keeps api tokens out - the check is for people only
+	apiTokensDenied = "-"
+	// apiTokensScopedToApp lets in any api token scoped to the app
+	apiTokensScopedToApp = ""
+)
+
+// apiTokenAuthCheck authorizes an api token by its scopes. An app name of * needs a token scoped to every app.
+func apiTokenAuthCheck(token oauth.APITokenAuth, appName string, tokenScope string) error {
+	if tokenScope == apiTokensDenied || !token.Allows(appName, tokenScope) {
+		return fmt.Errorf("api token %s is unauthorized to perform this action", token.Name)
+	}
+	return nil
+}
+
+// canDestroyApp lets api tokens with the review apps scope destroy review apps of the apps they're scoped to.
+// Everyone else needs to be able to delete the app's deployments.
+func (r *Resolver) canDestroyApp(ctx context.Context, appName string) error {
+	token, ok := oauth.GetAPIToken(ctx)
+	if !ok {
+		return canDeleteDeployments(ctx, appName)
+	}
+
+	app, err := r.db.App(appName)
+	if err != nil || !app.ReviewApp {
+		return fmt.Errorf("api tokens can only destroy review apps")
+	}
+	if !token.Allows(app.SourceAppName, model.APITokenScopeReviewApps) && !token.Allows(app.Name, model.APITokenScopeReviewApps) {
+		return fmt.Errorf("api token %s is unauthorized to perform this action", token.Name)
+	}
+	return nil
+}
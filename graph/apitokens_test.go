@@ -0,0 +1,62 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/oauth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPITokenAuthCheck(t *testing.T) {
+	token := oauth.APITokenAuth{Name: "potatoes-ci", Apps: []string{"potatoes"}, Scopes: []string{model.APITokenScopeDeploy, model.APITokenScopeEnvRead}}
+	ctx := oauth.WithAPIToken(context.Background(), token)
+
+	assert.NoError(t, canGetDeployments(ctx, "potatoes"))
+	assert.NoError(t, canDeploy(ctx, "potatoes"))
+	assert.NoError(t, canGetSecret(ctx, "potatoes", "potatoes-env"))
+	assert.Error(t, canUpdateSecret(ctx, "potatoes", "potatoes-env"), "env:write isn't in its scopes")
+	assert.Error(t, canUpdateDeployments(ctx, "potatoes"), "changing settings is for people")
+	assert.Error(t, canDeleteDeployments(ctx, "potatoes"), "deleting is for people")
+	assert.Error(t, canCreateReviewApp(ctx, "potatoes"), "reviewapps isn't in its scopes")
+	assert.Error(t, canGetDeployments(ctx, "tomatoes"), "tomatoes isn't in its apps")
+	assert.Error(t, canViewAllApps(ctx))
+
+	reaper := oauth.APITokenAuth{Name: "reaper", Apps: []string{"*"}, Scopes: []string{model.APITokenScopeReviewApps}}
+	ctx = oauth.WithAPIToken(context.Background(), reaper)
+	assert.NoError(t, canCreateReviewApp(ctx, "tomatoes"))
+	assert.Error(t, canCreateDeployments(ctx, "tomatoes"), "unsetting excluded resources is for people")
+	assert.NoError(t, canViewAllApps(ctx))
+	assert.Error(t, canCreateApps(ctx), "cluster-wide changes are for people")
+}
+
+func TestAPITokensCannotDecideReleases(t *testing.T) {
+	token := oauth.APITokenAuth{Name: "potatoes-ci", Apps: []string{"potatoes"}, Scopes: model.APITokenScopes}
+	ctx := oauth.WithAPIToken(context.Background(), token)
+	resolver := &mutationResolver{&Resolver{}}
+
+	_, err := resolver.ApproveRelease(ctx, model.ReleaseDecisionInput{AppName: "potatoes"})
+	assert.EqualError(t, err, "api token potatoes-ci is unauthorized to perform this action")
+	_, err = resolver.RejectRelease(ctx, model.ReleaseDecisionInput{AppName: "potatoes"})
+	assert.Error(t, err)
+	_, err = resolver.SetWebhook(ctx, model.SetWebhookInput{AppName: "potatoes", URL: "https://example.com"})
+	assert.Error(t, err)
+	_, err = resolver.SetNotificationTarget(ctx, model.SetNotificationTargetInput{AppName: "potatoes", Type: model.NotificationWebhook, Target: "https://example.com"})
+	assert.Error(t, err)
+	_, err = resolver.SetAppVar(ctx, model.SetTupleInput{Name: "potatoes", Key: "a", Value: "b"})
+	assert.Error(t, err)
+}
+
+func TestNewAPIToken(t *testing.T) {
+	apiToken, token, err := model.NewAPIToken("potatoes-ci", []string{"potatoes"}, []string{model.APITokenScopeDeploy})
+	assert.NoError(t, err)
+	assert.Contains(t, token, model.APITokenPrefix)
+	assert.Equal(t, model.APITokenHash(token), apiToken.Hash)
+	assert.NotContains(t, apiToken.Hash, token)
+
+	_, _, err = model.NewAPIToken("potatoes-ci", []string{"potatoes"}, []string{"admin"})
+	assert.Error(t, err)
+	_, _, err = model.NewAPIToken("potatoes-ci", []string{}, []string{model.APITokenScopeDeploy})
+	assert.Error(t, err)
+}
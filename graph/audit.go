@@ -0,0 +1,307 @@
+package graph
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/core"
+	"github.com/freshly/tuber/pkg/oauth"
+	"github.com/freshly/tuber/pkg/slack"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+)
+
+const auditRedacted = "[redacted]"
+
+// auditPruneInterval is how many entries are recorded between prunes of entries older than the retention
+const auditPruneInterval = 100
+
+// auditRedactions are the input fields of a mutation that never go in the audit log
+var auditRedactions = map[string][]string{
+	"setAppEnv":    {"value"},
+	"unsetAppEnv":  {"value"},
+	"setWebhook":   {"secret"},
+	"unsetWebhook": {"secret"},
+}
+
+// auditSummaries are input fields recorded as a summary instead - manual applies' manifests often hold secrets,
+// imported apps hold webhook secrets and notification urls, and webhook urls like teams' carry their credentials in the path
+var auditSummaries = map[string]map[string]func(interface{}) interface{}{
+	"manualApply":             {"resources": summarizeManifests},
+	"importApp":               {"app": summarizeApp},
+	"setNotificationTarget":   {"target": summarizeURL},
+	"unsetNotificationTarget": {"target": summarizeURL},
+	"setWebhook":              {"url": summarizeURL},
+	"unsetWebhook":            {"url": summarizeURL},
+}
+
+// auditClusterWide are mutations whose input names something other than an app
+var auditClusterWide = map[string]bool{
+	"saveAllApps":        true,
+	"replayEvent":        true,
+	"createFreezeWindow": true,
+	"removeFreezeWindow": true,
+	"pauseCluster":       true,
+	"resumeCluster":      true,
+	"createAPIToken":     true,
+	"revokeAPIToken":     true,
+	"backupDatabase":     true,
+	"restoreDatabase":    true,
+}
+
+// auditDestructive are mutations also forwarded to the audit slack channel, if there is one
+var auditDestructive = map[string]bool{
+	"destroyApp":      true,
+	"removeApp":       true,
+	"rollback":        true,
+	"manualApply":     true,
+	"unsetAppEnv":     true,
+	"pauseCluster":    true,
+	"restoreDatabase": true,
+}
+
+// Auditor records who ran each mutation, with what input, and whether it worked
+type Auditor struct {
+	db           *core.DB
+	logger       *zap.Logger
+	slack        *slack.Client
+	slackChannel string
+	retention    time.Duration
+
+	mu       sync.Mutex
+	recorded int
+}
+
+// NewAuditor records to the db, forwarding destructive mutations to slackChannel unless it's empty. Entries older than retention are pruned.
+func NewAuditor(db *core.DB, logger *zap.Logger, slackClient *slack.Client, slackChannel string, retention time.Duration) *Auditor {
+	return &Auditor{
+		db:           db,
+		logger:       logger.With(zap.String("context", "audit")),
+		slack:        slackClient,
+		slackChannel: slackChannel,
+		retention:    retention,
+	}
+}
+
+// Record saves an audit entry for a mutation. Failing to record is logged, the mutation has already happened.
+func (a *Auditor) Record(ctx context.Context, operation string, input interface{}, mutationErr error) {
+	if a == nil {
+		return
+	}
+
+	args, err := auditInput(operation, input)
+	if err != nil {
+		a.logger.Error("failed to marshal audit input", zap.Error(err), zap.String("operation", operation))
+	}
+
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+
+	entry := &model.AuditEntry{
+		ID:        hex.EncodeToString(id),
+		Operation: operation,
+		AppName:   auditAppName(operation, args),
+		Identity:  oauth.IdentityOrUnknown(ctx),
+		Succeeded: mutationErr == nil,
+	}
+	entry.CreatedAt = time.Now().UTC().Format(entry.TimestampFormat())
+	if mutationErr != nil {
+		entry.Error = mutationErr.Error()
+	}
+	if args != nil {
+		data, _ := json.Marshal(args)
+		entry.Input = string(data)
+	}
+
+	err = a.db.SaveAuditEntry(entry)
+	if err != nil {
+		a.logger.Error("failed to save audit entry", zap.Error(err), zap.String("operation", operation), zap.String("identity", entry.Identity))
+	}
+
+	if auditDestructive[operation] && a.slack != nil && a.slackChannel != "" {
+		a.slack.Message(a.logger, auditMessage(entry), a.slackChannel)
+	}
+
+	a.prune()
+}
+
+// prune deletes entries older than the retention, once every auditPruneInterval entries
+func (a *Auditor) prune() {
+	a.mu.Lock()
+	a.recorded++
+	due := a.recorded >= auditPruneInterval
+	if due {
+		a.recorded = 0
+	}
+	a.mu.Unlock()
+
+	if !due || a.retention <= 0 {
+		return
+	}
+	pruned, err := a.db.PruneAuditLog(time.Now().Add(-a.retention))
+	if err != nil {
+		a.logger.Error("failed to prune audit log", zap.Error(err))
+		return
+	}
+	if pruned > 0 {
+		a.logger.Debug("pruned audit log", zap.Int("entries", pruned))
+	}
+}
+
+// auditMutations records every top level mutation field as it resolves
+func (a *Auditor) auditMutations(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	fieldContext := graphql.GetFieldContext(ctx)
+	if fieldContext == nil || fieldContext.Object != "Mutation" {
+		return next(ctx)
+	}
+
+	res, err := next(ctx)
+	var input interface{} = map[string]interface{}(fieldContext.Args)
+	if value, ok := fieldContext.Args["input"]; ok {
+		input = value
+	}
+	a.Record(ctx, fieldContext.Field.Name, input, err)
+	return res, err
+}
+
+// auditInput is a mutation's input as a map, with anything secret redacted
+func auditInput(operation string, input interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+
+	args := map[string]interface{}{}
+	err = json.Unmarshal(data, &args)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range auditRedactions[operation] {
+		if _, ok := args[field]; ok {
+			args[field] = auditRedacted
+		}
+	}
+	for field, summarize := range auditSummaries[operation] {
+		if value, ok := args[field]; ok {
+			args[field] = summarize(value)
+		}
+	}
+	return args, nil
+}
+
+// summarizeManifests replaces base64 encoded manifests with each resource's kind and name, and a digest of the manifest
+func summarizeManifests(value interface{}) interface{} {
+	encoded, ok := value.([]interface{})
+	if !ok {
+		return auditRedacted
+	}
+
+	summaries := []string{}
+	for _, resource := range encoded {
+		data, err := base64.StdEncoding.DecodeString(fmt.Sprint(resource))
+		if err != nil {
+			summaries = append(summaries, auditRedacted)
+			continue
+		}
+		sum := sha256.Sum256(data)
+		digest := "sha256:" + hex.EncodeToString(sum[:])[:12]
+
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		for {
+			var manifest struct {
+				Kind     string
+				Metadata struct {
+					Name string
+				}
+			}
+			err = decoder.Decode(&manifest)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				summaries = append(summaries, "unparseable "+digest)
+				break
+			}
+			if manifest.Kind == "" {
+				continue
+			}
+			summaries = append(summaries, manifest.Kind+"/"+manifest.Metadata.Name+" "+digest)
+		}
+	}
+	return summaries
+}
+
+// summarizeApp replaces an imported app's json with its name and a digest of the json
+func summarizeApp(value interface{}) interface{} {
+	data := fmt.Sprint(value)
+	sum := sha256.Sum256([]byte(data))
+	summary := map[string]interface{}{"digest": "sha256:" + hex.EncodeToString(sum[:])[:12]}
+
+	var app struct {
+		Name string `json:"name"`
+	}
+	if json.Unmarshal([]byte(data), &app) == nil {
+		summary["name"] = app.Name
+	}
+	return summary
+}
+
+// summarizeURL keeps only a url's scheme and host. Targets that aren't urls, like slack channels and email addresses, are kept as they are.
+func summarizeURL(value interface{}) interface{} {
+	target, ok := value.(string)
+	if !ok {
+		return auditRedacted
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return auditRedacted
+	}
+	if u.Host == "" {
+		return target
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+func auditAppName(operation string, args map[string]interface{}) string {
+	if auditClusterWide[operation] {
+		return ""
+	}
+	for _, field := range []string{"appName", "name"} {
+		if name, ok := args[field].(string); ok && name != "" {
+			return name
+		}
+	}
+	// imported apps are named in their summarized json
+	if app, ok := args["app"].(map[string]interface{}); ok {
+		if name, ok := app["name"].(string); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+func auditMessage(entry *model.AuditEntry) string {
+	target := "the cluster"
+	if entry.AppName != "" {
+		target = "`" + entry.AppName + "`"
+	}
+
+	message := fmt.Sprintf(":memo: %s ran `%s` on %s", entry.Identity, entry.Operation, target)
+	if !entry.Succeeded {
+		message += fmt.Sprintf(" and it failed: %s", entry.Error)
+	}
+	return message
+}
@@ -0,0 +1,157 @@
+package graph
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/k8s"
+)
+
+// authCacheTTL is how long a review is trusted for a caller - long enough to cover a page's worth of queries,
+// short enough that revoked access stops working soon after
+const authCacheTTL = 30 * time.Second
+
+// reviewTimeout caps a review. Reviews run apart from the request that started them, since other callers may be waiting on them too.
+const reviewTimeout = 10 * time.Second
+
+// authConcurrency caps how many namespaces are reviewed at once when filtering a list
+const authConcurrency = 10
+
+// reviews are cached per caller and question. Callers asking the same question at once wait on the same review.
+var reviews = struct {
+	sync.Mutex
+	entries map[string]*review
+}{entries: map[string]*review{}}
+
+type review struct {
+	done    chan struct{}
+	expires time.Time
+	allowed bool
+	rules   *k8s.Rules
+	err     error
+}
+
+// cachedReview runs a review, or returns the cached result of the same one. Failed reviews aren't cached.
+// The review runs on its own context, so a caller giving up doesn't fail it for everyone else waiting on it.
+func cachedReview(ctx context.Context, key string, run func(context.Context, *review)) (*review, error) {
+	now := time.Now()
+	reviews.Lock()
+	r, ok := reviews.entries[key]
+	if !ok || now.After(r.expires) {
+		for k, cached := range reviews.entries {
+			if now.After(cached.expires) {
+				delete(reviews.entries, k)
+			}
+		}
+		r = &review{done: make(chan struct{}), expires: now.Add(authCacheTTL)}
+		reviews.entries[key] = r
+		go func() {
+			reviewCtx, cancel := context.WithTimeout(context.Background(), reviewTimeout)
+			defer cancel()
+			run(reviewCtx, r)
+			if r.err != nil {
+				reviews.Lock()
+				if reviews.entries[key] == r {
+					delete(reviews.entries, key)
+				}
+				reviews.Unlock()
+			}
+			close(r.done)
+		}()
+	}
+	reviews.Unlock()
+
+	select {
+	case <-r.done:
+		return r, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// reviewKey identifies a caller's question without keeping their token around
+func reviewKey(caller k8s.Caller, question string) string {
+	sum := sha256.Sum256([]byte(caller.Token + "\x00" + caller.Impersonate))
+	return hex.EncodeToString(sum[:]) + " " + question
+}
+
+// reviewAccess answers whether a caller can do something. Rules for the namespace answer every check in it with one review,
+// and an access review settles anything the rules can't.
+func reviewAccess(ctx context.Context, caller k8s.Caller, attributes k8s.ResourceAttributes) (bool, error) {
+	if attributes.Namespace != "" {
+		rules, err := cachedReview(ctx, reviewKey(caller, "rules "+attributes.Namespace), func(ctx context.Context, r *review) {
+			r.rules, r.err = k8s.RulesReview(ctx, attributes.Namespace, caller)
+		})
+		if err == nil && rules.rules.Allows(attributes) {
+			return true, nil
+		}
+		if err == nil && !rules.rules.Incomplete {
+			return false, nil
+		}
+	}
+
+	access, err := cachedReview(ctx, reviewKey(caller, "access "+attributes.Namespace+" "+attributes.Verb+" "+attributes.Group+"/"+attributes.Resource+"/"+attributes.Subresource+"/"+attributes.Name), func(ctx context.Context, r *review) {
+		r.allowed, r.err = k8s.AccessReview(ctx, attributes, caller)
+	})
+	if err != nil {
+		return false, err
+	}
+	return access.allowed, nil
+}
+
+// visibleApps is which of a list of apps the caller can see. all is true when they can see every app, and the list needn't be filtered.
+func visibleApps(ctx context.Context, appNames []string) (visible map[string]bool, all bool) {
+	if canViewAllApps(ctx) == nil {
+		return nil, true
+	}
+
+	visible = map[string]bool{}
+	seen := map[string]bool{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, authConcurrency)
+	for _, appName := range appNames {
+		if seen[appName] {
+			continue
+		}
+		seen[appName] = true
+
+		wg.Add(1)
+		limit <- struct{}{}
+		go func(appName string) {
+			defer wg.Done()
+			defer func() { <-limit }()
+			err := canGetDeployments(ctx, appName)
+			mu.Lock()
+			visible[appName] = err == nil
+			mu.Unlock()
+		}(appName)
+	}
+	wg.Wait()
+	return visible, false
+}
+
+// filterApps leaves out apps the caller can't see
+func filterApps(ctx context.Context, apps []*model.TuberApp) []*model.TuberApp {
+	var names []string
+	for _, app := range apps {
+		names = append(names, app.Name)
+	}
+
+	visible, all := visibleApps(ctx, names)
+	if all {
+		return apps
+	}
+
+	filtered := []*model.TuberApp{}
+	for _, app := range apps {
+		if visible[app.Name] {
+			filtered = append(filtered, app)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,42 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedReviewOutlivesCaller(t *testing.T) {
+	reviews.Lock()
+	delete(reviews.entries, "outlives caller")
+	reviews.Unlock()
+
+	release := make(chan struct{})
+	var runs int
+	run := func(ctx context.Context, r *review) {
+		runs++
+		<-release
+		r.err = ctx.Err()
+		r.allowed = true
+	}
+
+	first, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := cachedReview(first, "outlives caller", run)
+	assert.Equal(t, context.Canceled, err, "a caller that gives up stops waiting")
+
+	waiting := make(chan *review)
+	go func() {
+		r, err := cachedReview(context.Background(), "outlives caller", run)
+		assert.NoError(t, err)
+		waiting <- r
+	}()
+	close(release)
+
+	r := <-waiting
+	require.NotNil(t, r)
+	assert.True(t, r.allowed, "the first caller giving up didn't fail the review")
+	assert.Equal(t, 1, runs)
+}
@@ -2,18 +2,27 @@ package graph
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
+	"net/http"
+	"strings"
 
 	"github.com/freshly/tuber/pkg/iap"
+	"github.com/gorilla/websocket"
 	"github.com/machinebox/graphql"
 	"github.com/spf13/viper"
 )
 
 type GraphqlClient struct {
 	client            *graphql.Client
+	url               string
 	IAPAudience       string
 	IntraCluster      bool
 	IntraClusterToken string
+	APIToken          string
 }
 
 func NewClient(clusterURL string, IAPAudience string) *GraphqlClient {
@@ -34,10 +43,65 @@ func NewClient(clusterURL string, IAPAudience string) *GraphqlClient {
 
 	return &GraphqlClient{
 		client:      client,
+		url:         graphqlURL,
 		IAPAudience: IAPAudience,
+		APIToken:    viper.GetString("TUBER_API_TOKEN"),
 	}
 }
 
+// setAuthHeaders authenticates a request with an api token if the client has one, as tuber's service account in cluster,
+// or otherwise through identity-aware proxy with the signed in user's tokens.
+// API tokens go in the Authorization header identity-aware proxy reads, so they need TUBER_GRAPHQL_HOST set to somewhere that skips it.
+func (g *GraphqlClient) setAuthHeaders(header http.Header) error {
+	if g.APIToken != "" {
+		header.Set("Authorization", "Bearer "+g.APIToken)
+		return nil
+	}
+
+	if g.IntraCluster {
+		header.Set("Tuber-Token", g.IntraClusterToken)
+		return nil
+	}
+
+	tokens, err := iap.CreateIDToken(g.IAPAudience)
+	if err != nil {
+		return err
+	}
+
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Authorization", "Bearer "+tokens.IDToken)
+	header.Set("Tuber-Token", tokens.AccessToken)
+	return nil
+}
+
+// Request makes an authenticated request to one of the admin server's other endpoints, like /db.
+// Responses other than 200 are returned as errors.
+func (g *GraphqlClient) Request(ctx context.Context, method string, path string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(g.url, "/graphql")+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	err = g.setAuthHeaders(req.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	// a redirect is to the login page, which isn't what was asked for
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		message, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+		return nil, fmt.Errorf("%s: %s", res.Status, strings.TrimSpace(string(message)))
+	}
+	return res, nil
+}
+
 type callOption struct {
 	vars map[string]string
 }
@@ -65,24 +129,12 @@ func (g *GraphqlClient) Query(ctx context.Context, gql string, target interface{
 		}
 	}
 
-	var tuberToken string
-
-	if !g.IntraCluster {
-		tokens, err := iap.CreateIDToken(g.IAPAudience)
-		if err != nil {
-			return err
-		}
-
-		tuberToken = tokens.AccessToken
-		req.Header.Set("Cache-Control", "no-cache")
-		req.Header.Set("Authorization", "Bearer "+tokens.IDToken)
-	} else {
-		tuberToken = g.IntraClusterToken
+	err := g.setAuthHeaders(req.Header)
+	if err != nil {
+		return err
 	}
 
-	req.Header.Set("Tuber-Token", tuberToken)
-
-	err := g.client.Run(ctx, req, &target)
+	err = g.client.Run(ctx, req, &target)
 	if err != nil {
 		return err
 	}
@@ -101,26 +153,93 @@ func (g *GraphqlClient) Mutation(ctx context.Context, gql string, key *int, inpu
 		req.Var("input", input)
 	}
 
-	var tuberToken string
+	err := g.setAuthHeaders(req.Header)
+	if err != nil {
+		return err
+	}
 
-	if !g.IntraCluster {
-		tokens, err := iap.CreateIDToken(g.IAPAudience)
-		if err != nil {
-			return err
-		}
+	if err = g.client.Run(ctx, req, &target); err != nil {
+		return err
+	}
 
-		tuberToken = tokens.AccessToken
-		req.Header.Set("Cache-Control", "no-cache")
-		req.Header.Set("Authorization", "Bearer "+tokens.IDToken)
-	} else {
-		tuberToken = g.IntraClusterToken
+	return nil
+}
+
+// subscriptionMessage is a graphql-ws protocol message
+type subscriptionMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Subscribe runs a subscription over a websocket, passing each result's data to handle until handle returns true or an error,
+// the server completes the subscription, or ctx is done
+func (g *GraphqlClient) Subscribe(ctx context.Context, gql string, handle func(data json.RawMessage) (bool, error)) error {
+	header := http.Header{}
+	err := g.setAuthHeaders(header)
+	if err != nil {
+		return err
 	}
 
-	req.Header.Set("Tuber-Token", tuberToken)
+	dialer := websocket.Dialer{Proxy: http.ProxyFromEnvironment, Subprotocols: []string{"graphql-ws"}}
+	conn, _, err := dialer.DialContext(ctx, "ws"+strings.TrimPrefix(g.url, "http"), header)
+	if err != nil {
+		return fmt.Errorf("subscription connection failed: %v", err)
+	}
+	defer conn.Close()
 
-	if err := g.client.Run(ctx, req, &target); err != nil {
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	payload, err := json.Marshal(map[string]string{"query": gql})
+	if err != nil {
 		return err
 	}
 
-	return nil
+	for _, message := range []subscriptionMessage{{Type: "connection_init"}, {ID: "1", Type: "start", Payload: payload}} {
+		err = conn.WriteJSON(message)
+		if err != nil {
+			return err
+		}
+	}
+
+	for {
+		var message subscriptionMessage
+		err = conn.ReadJSON(&message)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("subscription connection lost: %v", err)
+		}
+
+		switch message.Type {
+		case "connection_error", "error":
+			return fmt.Errorf("graphql: %s", message.Payload)
+		case "complete":
+			return nil
+		case "data":
+			var result struct {
+				Data   json.RawMessage
+				Errors []struct {
+					Message string
+				}
+			}
+			err = json.Unmarshal(message.Payload, &result)
+			if err != nil {
+				return err
+			}
+			if len(result.Errors) != 0 {
+				return fmt.Errorf("graphql: %s", result.Errors[0].Message)
+			}
+
+			done, err := handle(result.Data)
+			if done || err != nil {
+				_ = conn.WriteJSON(subscriptionMessage{ID: "1", Type: "stop"})
+				return err
+			}
+		}
+	}
 }
@@ -0,0 +1,99 @@
+package graph
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/freshly/tuber/pkg/core"
+	"go.uber.org/zap"
+)
+
+// maxRestoreSize caps uploaded backups once they're decompressed, well past any real tuber db
+const maxRestoreSize = 1 << 30
+
+// DatabaseHandler serves a backup of tuber's db on GET, and restores a backup POSTed to it, gzipped or not.
+// Backups hold every app's settings, webhook secrets and api token hashes, and restores replace every app at once,
+// so both are for people who can do anything in every namespace.
+func DatabaseHandler(db *core.DB, logger *zap.Logger, auditor *Auditor) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := canAdministerCluster(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=tuber-%s.db", time.Now().UTC().Format("20060102-150405")))
+			written, err := db.Backup(w)
+			auditor.Record(r.Context(), "backupDatabase", map[string]interface{}{"bytes": written}, err)
+			if err != nil {
+				// headers are gone by now, so the client sees a truncated file
+				logger.Error("db backup failed", zap.Error(err))
+			}
+		case http.MethodPost:
+			message, err := restore(db, r.Body)
+			auditor.Record(r.Context(), "restoreDatabase", nil, err)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			fmt.Fprintln(w, message)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// restore writes an uploaded backup to a temp file, since bolt only opens files, and restores the db from it
+func restore(db *core.DB, body io.Reader) (string, error) {
+	reader := bufio.NewReader(body)
+	magic, _ := reader.Peek(2)
+	var backup io.Reader = reader
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return "", err
+		}
+		defer gz.Close()
+		backup = gz
+	}
+
+	file, err := ioutil.TempFile("", "tuber-restore-*.db")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+
+	written, err := io.Copy(file, io.LimitReader(backup, maxRestoreSize+1))
+	if err != nil {
+		file.Close()
+		return "", fmt.Errorf("reading backup: %v", err)
+	}
+	if written > maxRestoreSize {
+		file.Close()
+		return "", fmt.Errorf("backup is larger than %d bytes", maxRestoreSize)
+	}
+	err = file.Close()
+	if err != nil {
+		return "", err
+	}
+
+	applied, err := db.Restore(file.Name())
+	if err != nil {
+		return "", err
+	}
+
+	message := "restored"
+	for _, migration := range applied {
+		message += fmt.Sprintf("\napplied migration %d: %s", migration.Version, migration.Description)
+	}
+	return message, nil
+}
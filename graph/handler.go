@@ -1,21 +1,79 @@
 package graph
 
 import (
+	"context"
 	"net/http"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/99designs/gqlgen/graphql"
 	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/freshly/tuber/graph/generated"
 	"github.com/freshly/tuber/pkg/core"
 	"github.com/freshly/tuber/pkg/events"
+	"github.com/freshly/tuber/pkg/metrics"
+	"github.com/freshly/tuber/pkg/notify"
+	"github.com/vektah/gqlparser/v2/ast"
 	"go.uber.org/zap"
 )
 
-func Handler(db *core.DB, processor *events.Processor, logger *zap.Logger, credentials []byte, projectName string, clusterName string, clusterRegion string, reviewAppsEnabled bool) http.Handler {
-	return handler.NewDefaultServer(
+const websocketKeepAlive = 10 * time.Second
+
+func Handler(db *core.DB, processor *events.Processor, inbox *events.Inbox, logger *zap.Logger, credentials []byte, projectName string, clusterName string, clusterRegion string, reviewAppsEnabled bool, webhookHosts *notify.WebhookHosts, auditor *Auditor) http.Handler {
+	server := handler.New(
 		generated.NewExecutableSchema(
 			generated.Config{
-				Resolvers: NewResolver(db, logger, processor, credentials, projectName, clusterName, clusterRegion, reviewAppsEnabled),
+				Resolvers: NewResolver(db, logger, processor, inbox, credentials, projectName, clusterName, clusterRegion, reviewAppsEnabled, webhookHosts),
 			},
 		),
 	)
+
+	// subscriptions are served over websockets, pinged often enough to keep load balancers from closing quiet ones mid-release
+	server.AddTransport(transport.Websocket{KeepAlivePingInterval: websocketKeepAlive})
+	server.AddTransport(transport.Options{})
+	server.AddTransport(transport.GET{})
+	server.AddTransport(transport.POST{})
+	server.AddTransport(transport.MultipartForm{})
+	server.SetQueryCache(lru.New(1000))
+	server.Use(extension.Introspection{})
+	server.Use(extension.AutomaticPersistedQuery{Cache: lru.New(100)})
+
+	server.AroundResponses(observeLatency)
+	server.AroundFields(auditor.auditMutations)
+	return server
+}
+
+// observeLatency records each request's latency by operation
+func observeLatency(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	response := next(ctx)
+	if !graphql.HasOperationContext(ctx) {
+		return response
+	}
+
+	operationContext := graphql.GetOperationContext(ctx)
+	if operationContext.Operation == nil || operationContext.Operation.Operation == ast.Subscription {
+		return response
+	}
+	metrics.GraphqlDuration.WithLabelValues(operationName(operationContext)).Observe(time.Since(operationContext.Stats.OperationStart).Seconds())
+	return response
+}
+
+// operationName is the operation's name, or its top level fields for the anonymous operations the cli sends
+func operationName(operationContext *graphql.OperationContext) string {
+	if operationContext.OperationName != "" {
+		return operationContext.OperationName
+	}
+
+	var fields []string
+	for _, selection := range operationContext.Operation.SelectionSet {
+		if field, ok := selection.(*ast.Field); ok {
+			fields = append(fields, field.Name)
+		}
+	}
+	sort.Strings(fields)
+	return strings.Join(fields, ",")
 }
@@ -0,0 +1,108 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/k8s"
+	"go.uber.org/zap"
+)
+
+// logOptions picks the pod, or every pod of the workload, whose logs were asked for.
+// Workloads are deployments unless given as kind/name, and default to the deployment named after the app.
+func logOptions(ctx context.Context, input model.LogsInput) (k8s.LogOptions, error) {
+	var options k8s.LogOptions
+	if input.Container != nil && *input.Container != "" {
+		err := k8s.ValidateName(*input.Container)
+		if err != nil {
+			return k8s.LogOptions{}, fmt.Errorf("container: %v", err)
+		}
+		options.Container = *input.Container
+	}
+	if input.Tail != nil {
+		if *input.Tail < 0 {
+			return k8s.LogOptions{}, fmt.Errorf("tail can't be negative")
+		}
+		options.Tail = *input.Tail
+	}
+	if input.Since != nil && *input.Since != "" {
+		since, err := time.ParseDuration(*input.Since)
+		if err != nil {
+			return k8s.LogOptions{}, fmt.Errorf("since must be a duration like 10m: %v", err)
+		}
+		options.Since = since
+	}
+
+	if input.Pod != nil && *input.Pod != "" {
+		err := k8s.ValidateName(*input.Pod)
+		if err != nil {
+			return k8s.LogOptions{}, fmt.Errorf("pod: %v", err)
+		}
+		options.Pod = *input.Pod
+		return options, nil
+	}
+
+	kind, name := "deployment", input.AppName
+	if input.Workload != nil && *input.Workload != "" {
+		name = *input.Workload
+		if split := strings.SplitN(name, "/", 2); len(split) == 2 {
+			kind, name = split[0], split[1]
+		}
+		for _, value := range []string{kind, name} {
+			err := k8s.ValidateName(value)
+			if err != nil {
+				return k8s.LogOptions{}, fmt.Errorf("workload: %v", err)
+			}
+		}
+	}
+
+	selector, err := k8s.WorkloadSelector(ctx, kind, name, input.AppName)
+	if err != nil {
+		return k8s.LogOptions{}, fmt.Errorf("unexpected error while finding %s %s: %v", kind, name, err)
+	}
+	if selector == "" {
+		return k8s.LogOptions{}, fmt.Errorf("%s %s has no selector to find its pods by", kind, name)
+	}
+	options.Selector = selector
+	return options, nil
+}
+
+// streamLogLines relays a log stream's lines until it ends or ctx is done. Subscriptions can't send graphql errors once they've started,
+// so a stream that fails ends with a line carrying its error.
+func streamLogLines(ctx context.Context, logger *zap.Logger, stream func(chan<- k8s.LogLine) error) <-chan *model.LogLine {
+	lines := make(chan k8s.LogLine)
+	streamErr := make(chan error, 1)
+	logLines := make(chan *model.LogLine)
+	go func() {
+		defer close(lines)
+		streamErr <- stream(lines)
+	}()
+	go func() {
+		defer close(logLines)
+		for line := range lines {
+			select {
+			case logLines <- logLineModel(line):
+			case <-ctx.Done():
+			}
+		}
+
+		err := <-streamErr
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+		logger.Warn("log stream ended with an error", zap.Error(err))
+		message := err.Error()
+		select {
+		case logLines <- &model.LogLine{Error: &message}:
+		case <-ctx.Done():
+		}
+	}()
+	return logLines
+}
+
+func logLineModel(line k8s.LogLine) *model.LogLine {
+	return &model.LogLine{Pod: line.Pod, Container: line.Container, Message: line.Message}
+}
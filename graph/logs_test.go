@@ -0,0 +1,34 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestStreamLogLinesEndsWithError(t *testing.T) {
+	logLines := streamLogLines(context.Background(), zap.NewNop(), func(lines chan<- k8s.LogLine) error {
+		lines <- k8s.LogLine{Pod: "potatoes-1", Container: "potatoes", Message: "starting"}
+		return errors.New("pods \"potatoes-1\" not found")
+	})
+
+	var received []*model.LogLine
+	for line := range logLines {
+		received = append(received, line)
+	}
+	require.Len(t, received, 2)
+	assert.Equal(t, "starting", received[0].Message)
+	assert.Nil(t, received[0].Error)
+	require.NotNil(t, received[1].Error, "a failed stream should tell the client why it ended")
+	assert.Equal(t, "pods \"potatoes-1\" not found", *received[1].Error)
+
+	logLines = streamLogLines(context.Background(), zap.NewNop(), func(lines chan<- k8s.LogLine) error { return nil })
+	_, open := <-logLines
+	assert.False(t, open, "a stream that ends cleanly just ends")
+}
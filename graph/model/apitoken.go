@@ -0,0 +1,110 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/freshly/tuber/pkg/db"
+)
+
+// APITokenPrefix starts every api token, so they can be told apart from identity-aware proxy's bearer tokens
+const APITokenPrefix = "tuber_"
+
+// API token scopes. A token scoped to an app can always read it; scopes add what it can change.
+const (
+	APITokenScopeDeploy     = "deploy"
+	APITokenScopeEnvRead    = "env:read"
+	APITokenScopeEnvWrite   = "env:write"
+	APITokenScopeReviewApps = "reviewapps"
+)
+
+// APITokenScopes are every scope a token can be given
+var APITokenScopes = []string{APITokenScopeDeploy, APITokenScopeEnvRead, APITokenScopeEnvWrite, APITokenScopeReviewApps}
+
+// APIToken is a token automation authenticates with instead of a google account.
+// Only its hash is stored - the token itself is shown once, when it's created - and the hash isn't in the graphql schema.
+type APIToken struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Hash       string   `json:"hash"`
+	Apps       []string `json:"apps"`
+	Scopes     []string `json:"scopes"`
+	CreatedBy  string   `json:"createdBy"`
+	CreatedAt  string   `json:"createdAt"`
+	LastUsedAt string   `json:"lastUsedAt"`
+}
+
+// NewAPIToken generates a token, returning it along with the APIToken that stores its hash
+func NewAPIToken(name string, apps []string, scopes []string) (*APIToken, string, error) {
+	for _, scope := range scopes {
+		if !validAPITokenScope(scope) {
+			return nil, "", fmt.Errorf("unknown scope %s, scopes are %s", scope, strings.Join(APITokenScopes, ", "))
+		}
+	}
+	if len(apps) == 0 {
+		return nil, "", fmt.Errorf("api tokens need at least one app, or * for every app")
+	}
+
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return nil, "", err
+	}
+	token := APITokenPrefix + hex.EncodeToString(secret)
+	hash := APITokenHash(token)
+
+	return &APIToken{
+		ID:     hash[:12],
+		Name:   name,
+		Hash:   hash,
+		Apps:   apps,
+		Scopes: scopes,
+	}, token, nil
+}
+
+// APITokenHash is how a token is stored and looked up
+func APITokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func validAPITokenScope(scope string) bool {
+	for _, valid := range APITokenScopes {
+		if scope == valid {
+			return true
+		}
+	}
+	return false
+}
+
+func (t APIToken) DBIndexes() (map[string]string, map[string]bool, map[string]int) {
+	return map[string]string{
+		"id":   t.ID,
+		"hash": t.Hash,
+	}, map[string]bool{}, map[string]int{}
+}
+
+func (t APIToken) DBRoot() string {
+	return "apiTokens"
+}
+
+func (t APIToken) DBKey() string {
+	return t.ID
+}
+
+func (t APIToken) DBMarshal() ([]byte, error) {
+	return json.Marshal(t)
+}
+
+func (t APIToken) DBUnmarshal(data []byte) (db.Model, error) {
+	var token APIToken
+	err := json.Unmarshal(data, &token)
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
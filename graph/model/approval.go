@@ -0,0 +1,49 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/freshly/tuber/pkg/db"
+)
+
+func (p PendingRelease) DBIndexes() (map[string]string, map[string]bool, map[string]int) {
+	return map[string]string{
+		"appName": p.AppName,
+	}, map[string]bool{}, map[string]int{}
+}
+
+func (p PendingRelease) DBRoot() string {
+	return "approvals"
+}
+
+// DBKey is the app name - an app has at most one release awaiting approval, the newest one
+func (p PendingRelease) DBKey() string {
+	return p.AppName
+}
+
+func (p PendingRelease) DBMarshal() ([]byte, error) {
+	return json.Marshal(p)
+}
+
+func (p PendingRelease) DBUnmarshal(data []byte) (db.Model, error) {
+	var pending PendingRelease
+	err := json.Unmarshal(data, &pending)
+	if err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
+
+func (p PendingRelease) TimestampFormat() string {
+	return time.RFC3339
+}
+
+// ExpiredAt reports whether the approval window has passed. Unparseable expiries count as expired.
+func (p PendingRelease) ExpiredAt(at time.Time) bool {
+	expiresAt, err := time.Parse(p.TimestampFormat(), p.ExpiresAt)
+	if err != nil {
+		return true
+	}
+	return !at.Before(expiresAt)
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"encoding/json"
+
+	"github.com/freshly/tuber/pkg/db"
+)
+
+func (a AuditEntry) DBIndexes() (map[string]string, map[string]bool, map[string]int) {
+	return map[string]string{
+		"id":      a.ID,
+		"appName": a.AppName,
+	}, map[string]bool{}, map[string]int{}
+}
+
+func (a AuditEntry) DBRoot() string {
+	return "auditLog"
+}
+
+func (a AuditEntry) DBKey() string {
+	return a.ID
+}
+
+func (a AuditEntry) DBMarshal() ([]byte, error) {
+	return json.Marshal(a)
+}
+
+func (a AuditEntry) DBUnmarshal(data []byte) (db.Model, error) {
+	var entry AuditEntry
+	err := json.Unmarshal(data, &entry)
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// TimestampFormat is fixed width, so entries sort and compare by their timestamps
+func (a AuditEntry) TimestampFormat() string {
+	return "2006-01-02T15:04:05.000000000Z07:00"
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/freshly/tuber/pkg/db"
+)
+
+const clusterStateKey = "state"
+
+// ClusterState is cluster-wide release state, stored as a single record
+type ClusterState struct {
+	Paused       bool   `json:"paused"`
+	PausedReason string `json:"pausedReason"`
+	PausedBy     string `json:"pausedBy"`
+	PausedAt     string `json:"pausedAt"`
+}
+
+func (c ClusterState) DBIndexes() (map[string]string, map[string]bool, map[string]int) {
+	return map[string]string{
+		"key": clusterStateKey,
+	}, map[string]bool{}, map[string]int{}
+}
+
+func (c ClusterState) DBRoot() string {
+	return "cluster"
+}
+
+func (c ClusterState) DBKey() string {
+	return clusterStateKey
+}
+
+func (c ClusterState) DBMarshal() ([]byte, error) {
+	return json.Marshal(c)
+}
+
+func (c ClusterState) DBUnmarshal(data []byte) (db.Model, error) {
+	var state ClusterState
+	err := json.Unmarshal(data, &state)
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (c ClusterState) TimestampFormat() string {
+	return time.RFC3339
+}
@@ -0,0 +1,170 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/freshly/tuber/pkg/db"
+	"github.com/robfig/cron/v3"
+)
+
+// freeze window start and end times without an offset are read in the window's timezone
+var freezeTimeFormats = []string{"2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"}
+
+func (f FreezeWindow) DBIndexes() (map[string]string, map[string]bool, map[string]int) {
+	return map[string]string{
+		"name": f.Name,
+	}, map[string]bool{}, map[string]int{}
+}
+
+func (f FreezeWindow) DBRoot() string {
+	return "freezes"
+}
+
+func (f FreezeWindow) DBKey() string {
+	return f.Name
+}
+
+func (f FreezeWindow) DBMarshal() ([]byte, error) {
+	return json.Marshal(f)
+}
+
+func (f FreezeWindow) DBUnmarshal(data []byte) (db.Model, error) {
+	var window FreezeWindow
+	err := json.Unmarshal(data, &window)
+	if err != nil {
+		return nil, err
+	}
+	return window, nil
+}
+
+// Location is the window's timezone, UTC if unset
+func (f FreezeWindow) Location() (*time.Location, error) {
+	if f.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(f.Timezone)
+}
+
+// Validate checks the window is either a start and end, or a cron schedule and a duration
+func (f FreezeWindow) Validate() error {
+	if f.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	_, err := f.Location()
+	if err != nil {
+		return fmt.Errorf("invalid timezone: %v", err)
+	}
+
+	scheduled := f.Cron != "" || f.Duration != ""
+	bounded := f.Start != "" || f.End != ""
+	if scheduled == bounded {
+		return fmt.Errorf("set either start and end, or cron and duration")
+	}
+
+	if scheduled {
+		_, err = f.schedule()
+		if err != nil {
+			return fmt.Errorf("invalid cron: %v", err)
+		}
+		duration, parseErr := time.ParseDuration(f.Duration)
+		if parseErr != nil || duration <= 0 {
+			return fmt.Errorf("duration must be positive, like 48h")
+		}
+		return nil
+	}
+
+	start, end, err := f.bounds()
+	if err != nil {
+		return err
+	}
+	if !end.After(start) {
+		return fmt.Errorf("end must be after start")
+	}
+	return nil
+}
+
+// AppliesTo reports whether releases of an app are blocked by the window
+func (f FreezeWindow) AppliesTo(appName string) bool {
+	for _, excluded := range f.ExcludeApps {
+		if excluded == appName {
+			return false
+		}
+	}
+
+	if len(f.IncludeApps) == 0 {
+		return true
+	}
+
+	for _, included := range f.IncludeApps {
+		if included == appName {
+			return true
+		}
+	}
+	return false
+}
+
+// ActiveAt reports whether the window covers a moment in time. Scheduled windows start at each cron fire and last Duration.
+func (f FreezeWindow) ActiveAt(at time.Time) (bool, error) {
+	if f.Cron == "" {
+		start, end, err := f.bounds()
+		if err != nil {
+			return false, err
+		}
+		return !at.Before(start) && at.Before(end), nil
+	}
+
+	schedule, err := f.schedule()
+	if err != nil {
+		return false, err
+	}
+	duration, err := time.ParseDuration(f.Duration)
+	if err != nil {
+		return false, err
+	}
+
+	// the first fire after (at - duration) is the only one whose window could still be open
+	return !schedule.Next(at.Add(-duration)).After(at), nil
+}
+
+func (f FreezeWindow) schedule() (cron.Schedule, error) {
+	timezone := f.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	return cron.ParseStandard(fmt.Sprintf("CRON_TZ=%s %s", timezone, f.Cron))
+}
+
+func (f FreezeWindow) bounds() (time.Time, time.Time, error) {
+	location, err := f.Location()
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	start, err := parseFreezeTime(f.Start, location)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start: %v", err)
+	}
+	end, err := parseFreezeTime(f.End, location)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid end: %v", err)
+	}
+	return start, end, nil
+}
+
+func parseFreezeTime(value string, location *time.Location) (time.Time, error) {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return parsed, nil
+	}
+
+	for _, format := range freezeTimeFormats {
+		parsed, err = time.ParseInLocation(format, value, location)
+		if err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not RFC3339 or YYYY-MM-DD[ HH:MM]", value)
+}
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFreezeWindowActiveAt(t *testing.T) {
+	newYork, _ := time.LoadLocation("America/New_York")
+
+	tests := []struct {
+		name   string
+		window FreezeWindow
+		at     time.Time
+		want   bool
+	}{
+		{
+			name:   "inside start and end",
+			window: FreezeWindow{Start: "2021-12-23", End: "2022-01-03", Timezone: "America/New_York"},
+			at:     time.Date(2021, 12, 25, 12, 0, 0, 0, newYork),
+			want:   true,
+		},
+		{
+			name:   "end is exclusive, in the window's timezone",
+			window: FreezeWindow{Start: "2021-12-23", End: "2022-01-03", Timezone: "America/New_York"},
+			at:     time.Date(2022, 1, 3, 5, 0, 0, 0, time.UTC),
+			want:   false,
+		},
+		{
+			name:   "rfc3339 ignores timezone",
+			window: FreezeWindow{Start: "2021-12-23T00:00:00Z", End: "2021-12-24T00:00:00Z", Timezone: "America/New_York"},
+			at:     time.Date(2021, 12, 23, 20, 0, 0, 0, newYork),
+			want:   false,
+		},
+		{
+			name:   "weekend cron on saturday",
+			window: FreezeWindow{Cron: "0 17 * * FRI", Duration: "63h", Timezone: "America/New_York"},
+			at:     time.Date(2021, 6, 12, 9, 0, 0, 0, newYork),
+			want:   true,
+		},
+		{
+			name:   "weekend cron before it opens",
+			window: FreezeWindow{Cron: "0 17 * * FRI", Duration: "63h", Timezone: "America/New_York"},
+			at:     time.Date(2021, 6, 11, 16, 59, 0, 0, newYork),
+			want:   false,
+		},
+		{
+			name:   "weekend cron after it closes",
+			window: FreezeWindow{Cron: "0 17 * * FRI", Duration: "63h", Timezone: "America/New_York"},
+			at:     time.Date(2021, 6, 14, 8, 0, 0, 0, newYork),
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.window.ActiveAt(tt.at)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFreezeWindowValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		window  FreezeWindow
+		wantErr bool
+	}{
+		{name: "bounded", window: FreezeWindow{Name: "a", Start: "2021-12-23", End: "2021-12-24 12:00"}},
+		{name: "scheduled", window: FreezeWindow{Name: "a", Cron: "0 0 * * SAT", Duration: "48h", Timezone: "Europe/London"}},
+		{name: "both", window: FreezeWindow{Name: "a", Start: "2021-12-23", End: "2021-12-24", Cron: "0 0 * * *", Duration: "1h"}, wantErr: true},
+		{name: "neither", window: FreezeWindow{Name: "a"}, wantErr: true},
+		{name: "end before start", window: FreezeWindow{Name: "a", Start: "2021-12-24", End: "2021-12-23"}, wantErr: true},
+		{name: "bad cron", window: FreezeWindow{Name: "a", Cron: "weekends", Duration: "48h"}, wantErr: true},
+		{name: "missing duration", window: FreezeWindow{Name: "a", Cron: "0 0 * * SAT"}, wantErr: true},
+		{name: "bad timezone", window: FreezeWindow{Name: "a", Start: "2021-12-23", End: "2021-12-24", Timezone: "Mars/Olympus"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.window.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestFreezeWindowAppliesTo(t *testing.T) {
+	assert.True(t, FreezeWindow{}.AppliesTo("potatoes"))
+	assert.False(t, FreezeWindow{ExcludeApps: []string{"potatoes"}}.AppliesTo("potatoes"))
+	assert.True(t, FreezeWindow{IncludeApps: []string{"potatoes"}}.AppliesTo("potatoes"))
+	assert.False(t, FreezeWindow{IncludeApps: []string{"carrots"}}.AppliesTo("potatoes"))
+	assert.False(t, FreezeWindow{IncludeApps: []string{"potatoes"}, ExcludeApps: []string{"potatoes"}}.AppliesTo("potatoes"))
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/freshly/tuber/pkg/db"
+)
+
+// InboxEvent statuses, in the order an event normally moves through them
+const (
+	InboxEventPending    = "pending"
+	InboxEventProcessing = "processing"
+	InboxEventDone       = "done"
+	InboxEventFailed     = "failed"
+)
+
+// InboxEvent is an image push waiting on, or done with, processing.
+// Trace is the span context it was received in, so processing shows up in the same trace; it isn't in the graphql schema.
+type InboxEvent struct {
+	ID         string            `json:"id"`
+	Digest     string            `json:"digest"`
+	Tag        string            `json:"tag"`
+	Status     string            `json:"status"`
+	Attempts   int               `json:"attempts"`
+	Error      string            `json:"error"`
+	ReceivedAt string            `json:"receivedAt"`
+	UpdatedAt  string            `json:"updatedAt"`
+	Trace      map[string]string `json:"trace,omitempty"`
+}
+
+// InboxEventID is deterministic on digest and tag, so redelivered messages land on the same event
+func InboxEventID(digest string, tag string) string {
+	sum := sha256.Sum256([]byte(digest + " " + tag))
+	return hex.EncodeToString(sum[:])[:20]
+}
+
+func (e InboxEvent) DBIndexes() (map[string]string, map[string]bool, map[string]int) {
+	return map[string]string{
+		"id":     e.ID,
+		"status": e.Status,
+	}, map[string]bool{}, map[string]int{}
+}
+
+func (e InboxEvent) DBRoot() string {
+	return "inbox"
+}
+
+func (e InboxEvent) DBKey() string {
+	return e.ID
+}
+
+func (e InboxEvent) DBMarshal() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func (e InboxEvent) DBUnmarshal(data []byte) (db.Model, error) {
+	var event InboxEvent
+	err := json.Unmarshal(data, &event)
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (e InboxEvent) TimestampFormat() string {
+	return time.RFC3339
+}
+
+func (e InboxEvent) ParsedUpdatedAt() (time.Time, error) {
+	parsed, err := time.Parse(e.TimestampFormat(), e.UpdatedAt)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return parsed, nil
+}
@@ -36,12 +36,6 @@ func (t TuberApp) DBUnmarshal(data []byte) (db.Model, error) {
 	if err != nil {
 		return nil, err
 	}
-	if app.State == nil {
-		app.State = &State{}
-	}
-	if app.ReviewAppsConfig == nil {
-		app.ReviewAppsConfig = &ReviewAppsConfig{}
-	}
 	return app, nil
 }
 
@@ -2,6 +2,12 @@
 
 package model
 
+type APITokenInput struct {
+	Name   string   `json:"name"`
+	Apps   []string `json:"apps"`
+	Scopes []string `json:"scopes"`
+}
+
 type AppInput struct {
 	Name            string  `json:"name"`
 	IsIstio         *bool   `json:"isIstio"`
@@ -10,6 +16,19 @@ type AppInput struct {
 	GithubRepo      *string `json:"githubRepo"`
 	SlackChannel    *string `json:"slackChannel"`
 	CloudSourceRepo *string `json:"cloudSourceRepo"`
+	OverrideFreeze  *bool   `json:"overrideFreeze"`
+	RequireApproval *bool   `json:"requireApproval"`
+}
+
+type AuditEntry struct {
+	ID        string `json:"id"`
+	Operation string `json:"operation"`
+	AppName   string `json:"appName"`
+	Identity  string `json:"identity"`
+	Input     string `json:"input"`
+	Succeeded bool   `json:"succeeded"`
+	Error     string `json:"error"`
+	CreatedAt string `json:"createdAt"`
 }
 
 type Build struct {
@@ -22,6 +41,10 @@ type ClusterInfo struct {
 	Name              string `json:"name"`
 	Region            string `json:"region"`
 	ReviewAppsEnabled bool   `json:"reviewAppsEnabled"`
+	Paused            bool   `json:"paused"`
+	PausedReason      string `json:"pausedReason"`
+	PausedBy          string `json:"pausedBy"`
+	PausedAt          string `json:"pausedAt"`
 }
 
 type CreateReviewAppInput struct {
@@ -29,16 +52,112 @@ type CreateReviewAppInput struct {
 	BranchName string `json:"branchName"`
 }
 
+type CreatedAPIToken struct {
+	Token    string    `json:"token"`
+	APIToken *APIToken `json:"apiToken"`
+}
+
+type FreezeWindow struct {
+	Name        string   `json:"name"`
+	Reason      string   `json:"reason"`
+	Start       string   `json:"start"`
+	End         string   `json:"end"`
+	Cron        string   `json:"cron"`
+	Duration    string   `json:"duration"`
+	Timezone    string   `json:"timezone"`
+	IncludeApps []string `json:"includeApps"`
+	ExcludeApps []string `json:"excludeApps"`
+	Active      bool     `json:"active"`
+}
+
+type FreezeWindowInput struct {
+	Name        string   `json:"name"`
+	Reason      *string  `json:"reason"`
+	Start       *string  `json:"start"`
+	End         *string  `json:"end"`
+	Cron        *string  `json:"cron"`
+	Duration    *string  `json:"duration"`
+	Timezone    *string  `json:"timezone"`
+	IncludeApps []string `json:"includeApps"`
+	ExcludeApps []string `json:"excludeApps"`
+}
+
 type ImportAppInput struct {
 	App           string `json:"app"`
 	SourceAppName string `json:"sourceAppName"`
 }
 
+type LogLine struct {
+	Pod       string  `json:"pod"`
+	Container string  `json:"container"`
+	Message   string  `json:"message"`
+	Error     *string `json:"error"`
+}
+
+type LogsInput struct {
+	AppName   string  `json:"appName"`
+	Workload  *string `json:"workload"`
+	Pod       *string `json:"pod"`
+	Container *string `json:"container"`
+	Since     *string `json:"since"`
+	Tail      *int    `json:"tail"`
+}
+
 type ManualApplyInput struct {
 	Name      string    `json:"name"`
 	Resources []*string `json:"resources"`
 }
 
+type NotificationTarget struct {
+	Type   string `json:"type"`
+	Target string `json:"target"`
+}
+
+type PauseClusterInput struct {
+	Reason string `json:"reason"`
+}
+
+type PendingRelease struct {
+	AppName        string `json:"appName"`
+	Digest         string `json:"digest"`
+	Tag            string `json:"tag"`
+	DiffLink       string `json:"diffLink"`
+	RequestedBy    string `json:"requestedBy"`
+	OverrideFreeze bool   `json:"overrideFreeze"`
+	RequestedAt    string `json:"requestedAt"`
+	ExpiresAt      string `json:"expiresAt"`
+	Expired        bool   `json:"expired"`
+}
+
+type Pod struct {
+	Name      string `json:"name"`
+	Phase     string `json:"phase"`
+	Ready     bool   `json:"ready"`
+	Restarts  int    `json:"restarts"`
+	Age       string `json:"age"`
+	CreatedAt string `json:"createdAt"`
+}
+
+type ReleaseDecisionInput struct {
+	AppName string  `json:"appName"`
+	Reason  *string `json:"reason"`
+}
+
+type ReleaseProgress struct {
+	Type      string `json:"type"`
+	AppName   string `json:"appName"`
+	ReleaseID string `json:"releaseId"`
+	Tag       string `json:"tag"`
+	Phase     string `json:"phase"`
+	Resource  string `json:"resource"`
+	Severity  string `json:"severity"`
+	Message   string `json:"message"`
+	Detail    string `json:"detail"`
+	Finished  bool   `json:"finished"`
+	Succeeded bool   `json:"succeeded"`
+	Time      string `json:"time"`
+}
+
 type Resource struct {
 	Encoded string `json:"encoded"`
 	Kind    string `json:"kind"`
@@ -51,6 +170,12 @@ type ReviewAppsConfig struct {
 	ExcludedResources []*Resource `json:"excludedResources"`
 }
 
+type SetNotificationTargetInput struct {
+	AppName string `json:"appName"`
+	Type    string `json:"type"`
+	Target  string `json:"target"`
+}
+
 type SetRacEnabledInput struct {
 	Name    string `json:"name"`
 	Enabled bool   `json:"enabled"`
@@ -68,33 +193,84 @@ type SetTupleInput struct {
 	Value string `json:"value"`
 }
 
+type SetWebhookInput struct {
+	AppName string   `json:"appName"`
+	URL     string   `json:"url"`
+	Events  []string `json:"events"`
+	Secret  *string  `json:"secret"`
+}
+
+type StartedRelease struct {
+	App       *TuberApp `json:"app"`
+	ReleaseID string    `json:"releaseId"`
+	Tag       string    `json:"tag"`
+}
+
 type State struct {
 	Current  []*Resource `json:"Current"`
 	Previous []*Resource `json:"Previous"`
 }
 
 type TuberApp struct {
-	CreatedAt          string            `json:"createdAt"`
-	UpdatedAt          string            `json:"updatedAt"`
-	CloudSourceRepo    string            `json:"cloudSourceRepo"`
-	CurrentTags        []string          `json:"currentTags"`
-	GithubRepo         string            `json:"githubRepo"`
-	ImageTag           string            `json:"imageTag"`
-	Name               string            `json:"name"`
-	Paused             bool              `json:"paused"`
-	ReviewApp          bool              `json:"reviewApp"`
-	ReviewAppsConfig   *ReviewAppsConfig `json:"reviewAppsConfig"`
-	SlackChannel       string            `json:"slackChannel"`
-	SourceAppName      string            `json:"sourceAppName"`
-	State              *State            `json:"state"`
-	TriggerID          string            `json:"triggerID"`
-	Vars               []*Tuple          `json:"vars"`
-	ReviewApps         []*TuberApp       `json:"reviewApps"`
-	ExcludedResources  []*Resource       `json:"excludedResources"`
-	CloudBuildStatuses []*Build          `json:"cloudBuildStatuses"`
+	CreatedAt           string                 `json:"createdAt"`
+	UpdatedAt           string                 `json:"updatedAt"`
+	CloudSourceRepo     string                 `json:"cloudSourceRepo"`
+	CurrentTags         []string               `json:"currentTags"`
+	CurrentRevision     string                 `json:"currentRevision"`
+	GithubRepo          string                 `json:"githubRepo"`
+	ImageTag            string                 `json:"imageTag"`
+	Name                string                 `json:"name"`
+	Paused              bool                   `json:"paused"`
+	RequireApproval     bool                   `json:"requireApproval"`
+	ReviewApp           bool                   `json:"reviewApp"`
+	ReviewAppsConfig    *ReviewAppsConfig      `json:"reviewAppsConfig"`
+	SlackChannel        string                 `json:"slackChannel"`
+	NotificationTargets []*NotificationTarget  `json:"notificationTargets"`
+	Webhooks            []*WebhookSubscription `json:"webhooks"`
+	SourceAppName       string                 `json:"sourceAppName"`
+	State               *State                 `json:"state"`
+	TriggerID           string                 `json:"triggerID"`
+	Vars                []*Tuple               `json:"vars"`
+	ReviewApps          []*TuberApp            `json:"reviewApps"`
+	ExcludedResources   []*Resource            `json:"excludedResources"`
+	CloudBuildStatuses  []*Build               `json:"cloudBuildStatuses"`
+	Workloads           []*Workload            `json:"workloads"`
 }
 
 type Tuple struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
 }
+
+type WebhookDelivery struct {
+	ID         string `json:"id"`
+	AppName    string `json:"appName"`
+	URL        string `json:"url"`
+	Event      string `json:"event"`
+	ReleaseID  string `json:"releaseId"`
+	Status     string `json:"status"`
+	Attempts   int    `json:"attempts"`
+	StatusCode int    `json:"statusCode"`
+	Error      string `json:"error"`
+	CreatedAt  string `json:"createdAt"`
+	UpdatedAt  string `json:"updatedAt"`
+}
+
+type Workload struct {
+	Kind            string           `json:"kind"`
+	Name            string           `json:"name"`
+	DesiredReplicas int              `json:"desiredReplicas"`
+	ReadyReplicas   int              `json:"readyReplicas"`
+	UpdatedReplicas int              `json:"updatedReplicas"`
+	Images          []string         `json:"images"`
+	Pods            []*Pod           `json:"pods"`
+	Warnings        []*WorkloadEvent `json:"warnings"`
+}
+
+type WorkloadEvent struct {
+	Reason   string `json:"reason"`
+	Message  string `json:"message"`
+	Object   string `json:"object"`
+	Count    int    `json:"count"`
+	LastSeen string `json:"lastSeen"`
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+)
+
+// Notification target types, each served by a notify backend
+const (
+	NotificationSlack   = "slack"
+	NotificationWebhook = "webhook"
+	NotificationTeams   = "teams"
+	NotificationEmail   = "email"
+)
+
+// Validate checks a target is something its backend can deliver to
+func (n NotificationTarget) Validate() error {
+	switch n.Type {
+	case NotificationSlack:
+		if n.Target == "" {
+			return fmt.Errorf("slack targets need a channel")
+		}
+	case NotificationWebhook, NotificationTeams:
+		u, err := url.Parse(n.Target)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("%s targets need an http(s) url", n.Type)
+		}
+	case NotificationEmail:
+		_, err := mail.ParseAddress(n.Target)
+		if err != nil {
+			return fmt.Errorf("email targets need an address: %v", err)
+		}
+	default:
+		return fmt.Errorf("unknown notification type %q, must be one of %s, %s, %s or %s", n.Type, NotificationSlack, NotificationWebhook, NotificationTeams, NotificationEmail)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/freshly/tuber/pkg/db"
+)
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookSubscription sends an app's release lifecycle events to a url. No events means all of them.
+// The secret signs deliveries, and is deliberately left out of the graphql schema so it can't be read back.
+type WebhookSubscription struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+}
+
+// Signed is whether deliveries carry a signature header
+func (w WebhookSubscription) Signed() bool {
+	return w.Secret != ""
+}
+
+// Wants is whether the subscription is for an event type
+func (w WebhookSubscription) Wants(eventType string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, event := range w.Events {
+		if event == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the url, and the events against the lifecycle event types there are to subscribe to
+func (w WebhookSubscription) Validate(eventTypes []string) error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("webhooks need an http(s) url")
+	}
+
+	known := map[string]bool{}
+	for _, eventType := range eventTypes {
+		known[eventType] = true
+	}
+	for _, event := range w.Events {
+		if !known[event] {
+			return fmt.Errorf("unknown event %q, must be one of %v", event, eventTypes)
+		}
+	}
+	return nil
+}
+
+func (d WebhookDelivery) DBIndexes() (map[string]string, map[string]bool, map[string]int) {
+	return map[string]string{
+		"id":      d.ID,
+		"appName": d.AppName,
+		"status":  d.Status,
+	}, map[string]bool{}, map[string]int{}
+}
+
+func (d WebhookDelivery) DBRoot() string {
+	return "webhookDeliveries"
+}
+
+func (d WebhookDelivery) DBKey() string {
+	return d.ID
+}
+
+func (d WebhookDelivery) DBMarshal() ([]byte, error) {
+	return json.Marshal(d)
+}
+
+func (d WebhookDelivery) DBUnmarshal(data []byte) (db.Model, error) {
+	var delivery WebhookDelivery
+	err := json.Unmarshal(data, &delivery)
+	if err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// TimestampFormat is fixed width, so deliveries sort by their timestamps
+func (d WebhookDelivery) TimestampFormat() string {
+	return "2006-01-02T15:04:05.000000000Z07:00"
+}
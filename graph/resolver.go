@@ -3,10 +3,13 @@ package graph
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/freshly/tuber/graph/model"
 	"github.com/freshly/tuber/pkg/core"
 	"github.com/freshly/tuber/pkg/events"
 	"github.com/freshly/tuber/pkg/k8s"
+	"github.com/freshly/tuber/pkg/notify"
 	"github.com/freshly/tuber/pkg/oauth"
 	"go.uber.org/zap"
 )
@@ -23,62 +26,150 @@ type Resolver struct {
 	credentials       []byte
 	projectName       string
 	processor         *events.Processor
+	inbox             *events.Inbox
 	clusterName       string
 	clusterRegion     string
 	reviewAppsEnabled bool
+	webhookHosts      *notify.WebhookHosts
 }
 
-func NewResolver(db *core.DB, logger *zap.Logger, processor *events.Processor, credentials []byte, projectName string, clusterName string, clusterRegion string, reviewAppsEnabled bool) *Resolver {
+func NewResolver(db *core.DB, logger *zap.Logger, processor *events.Processor, inbox *events.Inbox, credentials []byte, projectName string, clusterName string, clusterRegion string, reviewAppsEnabled bool, webhookHosts *notify.WebhookHosts) *Resolver {
 	return &Resolver{
 		db:                db,
 		logger:            logger,
 		credentials:       credentials,
 		projectName:       projectName,
 		processor:         processor,
+		inbox:             inbox,
 		clusterName:       clusterName,
 		clusterRegion:     clusterRegion,
 		reviewAppsEnabled: reviewAppsEnabled,
+		webhookHosts:      webhookHosts,
 	}
 }
 
+func (r *Resolver) clusterInfo() (*model.ClusterInfo, error) {
+	state, err := r.db.ClusterState()
+	if err != nil {
+		return nil, fmt.Errorf("unexpected error while loading cluster state: %v", err)
+	}
+
+	return &model.ClusterInfo{
+		Name:              r.clusterName,
+		Region:            r.clusterRegion,
+		ReviewAppsEnabled: r.reviewAppsEnabled,
+		Paused:            state.Paused,
+		PausedReason:      state.PausedReason,
+		PausedBy:          state.PausedBy,
+		PausedAt:          state.PausedAt,
+	}, nil
+}
+
+// releasesPaused errors if the cluster-wide kill switch is on
+func (r *Resolver) releasesPaused() error {
+	state, err := r.db.ClusterState()
+	if err != nil {
+		return fmt.Errorf("unexpected error while loading cluster state: %v", err)
+	}
+	if state.Paused {
+		return fmt.Errorf("releases are paused cluster-wide by %s: %s", state.PausedBy, state.PausedReason)
+	}
+	return nil
+}
+
+func releaseProgress(event notify.Event) *model.ReleaseProgress {
+	return &model.ReleaseProgress{
+		Type:      event.Type,
+		AppName:   event.App,
+		ReleaseID: event.Release,
+		Tag:       event.Tag,
+		Phase:     event.Phase,
+		Resource:  event.Resource,
+		Severity:  event.Severity,
+		Message:   event.Message,
+		Detail:    event.Detail,
+		Finished:  event.Finished(),
+		Succeeded: event.Succeeded(),
+		Time:      event.Time.Format(time.RFC3339),
+	}
+}
+
+// canUpdateDeployments is for changing an app's settings, and deciding on its releases. It's for people only.
 func canUpdateDeployments(ctx context.Context, appName string) error {
-	return authCheck(ctx, appName, "update", "deployments")
+	return authCheck(ctx, appName, "update", "deployments", apiTokensDenied)
+}
+
+// canDeploy is for releasing an app, or rolling it back
+func canDeploy(ctx context.Context, appName string) error {
+	return authCheck(ctx, appName, "update", "deployments", model.APITokenScopeDeploy)
 }
 
 func canDeleteDeployments(ctx context.Context, appName string) error {
-	return authCheck(ctx, appName, "delete", "deployments")
+	return authCheck(ctx, appName, "delete", "deployments", apiTokensDenied)
 }
 
 func canGetDeployments(ctx context.Context, appName string) error {
-	return authCheck(ctx, appName, "get", "deployments")
+	return authCheck(ctx, appName, "get", "deployments", apiTokensScopedToApp)
+}
+
+func canGetLogs(ctx context.Context, appName string) error {
+	return authCheck(ctx, appName, "get", "pods/log", apiTokensScopedToApp)
 }
 
 func canCreateDeployments(ctx context.Context, appName string) error {
-	return authCheck(ctx, appName, "create", "deployments")
+	return authCheck(ctx, appName, "create", "deployments", apiTokensDenied)
+}
+
+// canCreateReviewApp is for creating a review app of an app
+func canCreateReviewApp(ctx context.Context, appName string) error {
+	return authCheck(ctx, appName, "create", "deployments", model.APITokenScopeReviewApps)
 }
 
 func canGetSecret(ctx context.Context, appName string, secretName string) error {
-	return authCheck(ctx, appName, "get", "secret/"+secretName)
+	return authCheck(ctx, appName, "get", "secret/"+secretName, model.APITokenScopeEnvRead)
 }
 
 func canUpdateSecret(ctx context.Context, appName string, secretName string) error {
-	return authCheck(ctx, appName, "update", "secret/"+secretName)
+	return authCheck(ctx, appName, "update", "secret/"+secretName, model.APITokenScopeEnvWrite)
 }
 
 func canCreateApps(ctx context.Context) error {
-	return authCheckAllNamespaces(ctx, "create", "deployments")
+	return authCheckAllNamespaces(ctx, "create", "deployments", apiTokensDenied)
+}
+
+// canAdministerCluster is for anything that can change every app at once, like restoring the db
+func canAdministerCluster(ctx context.Context) error {
+	return authCheckAllNamespaces(ctx, "*", "*", apiTokensDenied)
 }
 
 func canViewAllApps(ctx context.Context) error {
-	return authCheckAllNamespaces(ctx, "get", "deployments")
+	return authCheckAllNamespaces(ctx, "get", "deployments", apiTokensScopedToApp)
 }
 
-func authCheckAllNamespaces(ctx context.Context, verb string, subject string) error {
+// authCaller is who access reviews ask about - the requester, with their access token, or impersonated when they don't have one
+func authCaller(ctx context.Context) (k8s.Caller, error) {
+	if identity, ok := oauth.Impersonated(ctx); ok {
+		return k8s.Caller{Impersonate: identity}, nil
+	}
+
 	token, err := oauth.GetAccessToken(ctx)
+	if err != nil {
+		return k8s.Caller{}, err
+	}
+	return k8s.Caller{Token: token}, nil
+}
+
+// authCheckAllNamespaces checks people with an access review, and api tokens by tokenScope
+func authCheckAllNamespaces(ctx context.Context, verb string, subject string, tokenScope string) error {
+	if token, ok := oauth.GetAPIToken(ctx); ok {
+		return apiTokenAuthCheck(token, "*", tokenScope)
+	}
+
+	caller, err := authCaller(ctx)
 	if err != nil {
 		return fmt.Errorf("error retrieving authorization params")
 	}
-	authorized, err := k8s.CanIAllNamespaces(verb, subject, "--token="+token)
+	authorized, err := reviewAccess(ctx, caller, k8s.ParseResourceAttributes("", verb, subject))
 	if err != nil {
 		return fmt.Errorf("error determining authorization status")
 	}
@@ -88,12 +179,17 @@ func authCheckAllNamespaces(ctx context.Context, verb string, subject string) er
 	return nil
 }
 
-func authCheck(ctx context.Context, appName string, verb string, subject string) error {
-	token, err := oauth.GetAccessToken(ctx)
+// authCheck checks people with an access review, and api tokens by tokenScope
+func authCheck(ctx context.Context, appName string, verb string, subject string, tokenScope string) error {
+	if token, ok := oauth.GetAPIToken(ctx); ok {
+		return apiTokenAuthCheck(token, appName, tokenScope)
+	}
+
+	caller, err := authCaller(ctx)
 	if err != nil {
 		return fmt.Errorf("error retrieving authorization params")
 	}
-	authorized, err := k8s.CanI(appName, verb, subject, "--token="+token)
+	authorized, err := reviewAccess(ctx, caller, k8s.ParseResourceAttributes(appName, verb, subject))
 	if err != nil {
 		return fmt.Errorf("error determining authorization status")
 	}
@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/freshly/tuber/graph/generated"
 	"github.com/freshly/tuber/graph/model"
@@ -19,17 +20,22 @@ import (
 	"github.com/freshly/tuber/pkg/events"
 	"github.com/freshly/tuber/pkg/gcr"
 	"github.com/freshly/tuber/pkg/k8s"
+	"github.com/freshly/tuber/pkg/notify"
 	"github.com/freshly/tuber/pkg/oauth"
 	"github.com/freshly/tuber/pkg/reviewapps"
 	"go.uber.org/zap"
 )
 
+func (r *freezeWindowResolver) Active(ctx context.Context, obj *model.FreezeWindow) (bool, error) {
+	return obj.ActiveAt(time.Now())
+}
+
 func (r *mutationResolver) CreateApp(ctx context.Context, input model.AppInput) (*model.TuberApp, error) {
 	err := canCreateApps(ctx)
 	if err != nil {
 		return nil, err
 	}
-	err = core.NewAppSetup(input.Name, *input.IsIstio)
+	err = core.NewAppSetup(ctx, input.Name, *input.IsIstio)
 	if err != nil {
 		return nil, err
 	}
@@ -90,8 +96,13 @@ func (r *mutationResolver) RemoveApp(ctx context.Context, input model.AppInput)
 	return app, nil
 }
 
-func (r *mutationResolver) Deploy(ctx context.Context, input model.AppInput) (*model.TuberApp, error) {
-	err := canUpdateDeployments(ctx, input.Name)
+func (r *mutationResolver) Deploy(ctx context.Context, input model.AppInput) (*model.StartedRelease, error) {
+	err := canDeploy(ctx, input.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.Resolver.releasesPaused()
 	if err != nil {
 		return nil, err
 	}
@@ -120,13 +131,19 @@ func (r *mutationResolver) Deploy(ctx context.Context, input model.AppInput) (*m
 		return nil, fmt.Errorf("unexpected error: couldn't find image for the tag: %v", err)
 	}
 
+	if input.OverrideFreeze != nil && *input.OverrideFreeze {
+		event.OverrideFreeze()
+	}
+	releaseID := notify.NewReleaseID(app.Name)
+	event.WithRequester(oauth.IdentityOrUnknown(ctx)).WithReleaseID(releaseID)
+
 	go r.Resolver.processor.ReleaseApp(event, app)
 
-	return app, nil
+	return &model.StartedRelease{App: app, ReleaseID: releaseID, Tag: tag}, nil
 }
 
 func (r *mutationResolver) DestroyApp(ctx context.Context, input model.AppInput) (*model.TuberApp, error) {
-	err := canDeleteDeployments(ctx, input.Name)
+	err := r.Resolver.canDestroyApp(ctx, input.Name)
 	if err != nil {
 		return nil, err
 	}
@@ -141,7 +158,7 @@ func (r *mutationResolver) DestroyApp(ctx context.Context, input model.AppInput)
 }
 
 func (r *mutationResolver) CreateReviewApp(ctx context.Context, input model.CreateReviewAppInput) (*model.TuberApp, error) {
-	err := canCreateDeployments(ctx, input.Name)
+	err := canCreateReviewApp(ctx, input.Name)
 	if err != nil {
 		return nil, err
 	}
@@ -242,11 +259,11 @@ func (r *mutationResolver) SetAppEnv(ctx context.Context, input model.SetTupleIn
 
 	mapName := fmt.Sprintf("%s-env", input.Name)
 
-	if err := k8s.PatchSecret(mapName, input.Name, input.Key, input.Value); err != nil {
+	if err := k8s.PatchSecret(ctx, mapName, input.Name, input.Key, input.Value); err != nil {
 		return nil, err
 	}
 
-	if err := k8s.Restart("deployments", input.Name); err != nil {
+	if err := k8s.Restart(ctx, "deployments", input.Name); err != nil {
 		return nil, err
 	}
 
@@ -261,11 +278,11 @@ func (r *mutationResolver) UnsetAppEnv(ctx context.Context, input model.SetTuple
 
 	mapName := fmt.Sprintf("%s-env", input.Name)
 
-	if err := k8s.RemoveSecretEntry(mapName, input.Name, input.Key); err != nil {
+	if err := k8s.RemoveSecretEntry(ctx, mapName, input.Name, input.Key); err != nil {
 		return nil, err
 	}
 
-	if err := k8s.Restart("deployments", input.Name); err != nil {
+	if err := k8s.Restart(ctx, "deployments", input.Name); err != nil {
 		return nil, err
 	}
 
@@ -331,8 +348,8 @@ func (r *mutationResolver) UnsetExcludedResource(ctx context.Context, input mode
 	return app, nil
 }
 
-func (r *mutationResolver) Rollback(ctx context.Context, input model.AppInput) (*model.TuberApp, error) {
-	err := canUpdateDeployments(ctx, input.Name)
+func (r *mutationResolver) Rollback(ctx context.Context, input model.AppInput) (*model.StartedRelease, error) {
+	err := canDeploy(ctx, input.Name)
 	if err != nil {
 		return nil, err
 	}
@@ -380,7 +397,7 @@ func (r *mutationResolver) Rollback(ctx context.Context, input model.AppInput) (
 	}
 
 	for _, resource := range decodedResources {
-		applyErr := k8s.Apply(resource.decoded, app.Name)
+		applyErr := k8s.Apply(ctx, resource.decoded, app.Name)
 		if applyErr != nil {
 			r.logger.Debug("rollback apply error", zap.Error(applyErr))
 			errors = append(errors, rollbackErr{err: applyErr, resource: resource.resource})
@@ -396,7 +413,10 @@ func (r *mutationResolver) Rollback(ctx context.Context, input model.AppInput) (
 		return nil, fmt.Errorf(strings.TrimSuffix(combined, ", "))
 	}
 
-	return app, nil
+	releaseID := notify.NewReleaseID(app.Name)
+	go r.Resolver.processor.WatchRollback(app, releaseID, app.State.Previous)
+
+	return &model.StartedRelease{App: app, ReleaseID: releaseID, Tag: app.ImageTag}, nil
 }
 
 func (r *mutationResolver) SetGithubRepo(ctx context.Context, input model.AppInput) (*model.TuberApp, error) {
@@ -486,18 +506,168 @@ func (r *mutationResolver) SetSlackChannel(ctx context.Context, input model.AppI
 	return app, nil
 }
 
-func (r *mutationResolver) ManualApply(ctx context.Context, input model.ManualApplyInput) (*model.TuberApp, error) {
-	token, err := oauth.GetAccessToken(ctx)
+func (r *mutationResolver) SetNotificationTarget(ctx context.Context, input model.SetNotificationTargetInput) (*model.TuberApp, error) {
+	err := canUpdateDeployments(ctx, input.AppName)
+	if err != nil {
+		return nil, err
+	}
+
+	target := &model.NotificationTarget{Type: input.Type, Target: input.Target}
+	err = target.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	if target.Type == model.NotificationWebhook || target.Type == model.NotificationTeams {
+		err = r.Resolver.webhookHosts.Check(ctx, target.Target)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	app, err := r.Resolver.db.App(input.AppName)
+	if err != nil {
+		if errors.As(err, &db.NotFoundError{}) {
+			return nil, errors.New("could not find app")
+		}
+
+		return nil, fmt.Errorf("unexpected error while trying to find app: %v", err)
+	}
+
+	for _, existing := range app.NotificationTargets {
+		if *existing == *target {
+			return app, nil
+		}
+	}
+	app.NotificationTargets = append(app.NotificationTargets, target)
+
+	err = r.Resolver.db.SaveApp(app)
+	if err != nil {
+		return nil, fmt.Errorf("could not save changes: %v", err)
+	}
+
+	return app, nil
+}
+
+func (r *mutationResolver) UnsetNotificationTarget(ctx context.Context, input model.SetNotificationTargetInput) (*model.TuberApp, error) {
+	err := canUpdateDeployments(ctx, input.AppName)
+	if err != nil {
+		return nil, err
+	}
+
+	app, err := r.Resolver.db.App(input.AppName)
+	if err != nil {
+		if errors.As(err, &db.NotFoundError{}) {
+			return nil, errors.New("could not find app")
+		}
+
+		return nil, fmt.Errorf("unexpected error while trying to find app: %v", err)
+	}
+
+	targets := []*model.NotificationTarget{}
+	for _, target := range app.NotificationTargets {
+		if !(target.Type == input.Type && target.Target == input.Target) {
+			targets = append(targets, target)
+		}
+	}
+	app.NotificationTargets = targets
+
+	err = r.Resolver.db.SaveApp(app)
+	if err != nil {
+		return nil, fmt.Errorf("could not save changes: %v", err)
+	}
+
+	return app, nil
+}
+
+func (r *mutationResolver) SetWebhook(ctx context.Context, input model.SetWebhookInput) (*model.TuberApp, error) {
+	err := canUpdateDeployments(ctx, input.AppName)
+	if err != nil {
+		return nil, err
+	}
+
+	app, err := r.Resolver.db.App(input.AppName)
+	if err != nil {
+		if errors.As(err, &db.NotFoundError{}) {
+			return nil, errors.New("could not find app")
+		}
+
+		return nil, fmt.Errorf("unexpected error while trying to find app: %v", err)
+	}
+
+	// setting a url that's already subscribed updates it, keeping its secret unless a new one is given
+	subscription := &model.WebhookSubscription{URL: input.URL, Events: input.Events}
+	webhooks := []*model.WebhookSubscription{}
+	for _, existing := range app.Webhooks {
+		if existing.URL == input.URL {
+			subscription.Secret = existing.Secret
+			continue
+		}
+		webhooks = append(webhooks, existing)
+	}
+	if input.Secret != nil {
+		subscription.Secret = *input.Secret
+	}
+
+	err = subscription.Validate(events.LifecycleEventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.Resolver.webhookHosts.Check(ctx, subscription.URL)
+	if err != nil {
+		return nil, err
+	}
+	app.Webhooks = append(webhooks, subscription)
+
+	err = r.Resolver.db.SaveApp(app)
+	if err != nil {
+		return nil, fmt.Errorf("could not save changes: %v", err)
+	}
+
+	return app, nil
+}
+
+func (r *mutationResolver) UnsetWebhook(ctx context.Context, input model.SetWebhookInput) (*model.TuberApp, error) {
+	err := canUpdateDeployments(ctx, input.AppName)
 	if err != nil {
-		return nil, fmt.Errorf("error retrieving authorization params")
+		return nil, err
 	}
 
-	authed, err := k8s.CanI(input.Name, "'*'", "'*'", "--token="+token)
+	app, err := r.Resolver.db.App(input.AppName)
+	if err != nil {
+		if errors.As(err, &db.NotFoundError{}) {
+			return nil, errors.New("could not find app")
+		}
+
+		return nil, fmt.Errorf("unexpected error while trying to find app: %v", err)
+	}
+
+	webhooks := []*model.WebhookSubscription{}
+	for _, existing := range app.Webhooks {
+		if existing.URL != input.URL {
+			webhooks = append(webhooks, existing)
+		}
+	}
+	app.Webhooks = webhooks
+
+	err = r.Resolver.db.SaveApp(app)
+	if err != nil {
+		return nil, fmt.Errorf("could not save changes: %v", err)
+	}
+
+	return app, nil
+}
+
+func (r *mutationResolver) ManualApply(ctx context.Context, input model.ManualApplyInput) (*model.TuberApp, error) {
+	err := authCheck(ctx, input.Name, "*", "*", apiTokensDenied)
 	if err != nil {
 		return nil, err
 	}
-	if !authed {
-		return nil, fmt.Errorf("unauthorized")
+
+	err = r.Resolver.releasesPaused()
+	if err != nil {
+		return nil, fmt.Errorf("%v, nothing applied", err)
 	}
 
 	app, err := r.Resolver.db.App(input.Name)
@@ -553,7 +723,7 @@ func (r *mutationResolver) ManualApply(ctx context.Context, input model.ManualAp
 		return nil, fmt.Errorf("unable to parse currently deployed image, nothing applied: %v", err)
 	}
 
-	err = core.BypassReleaser(app, imageTagWithDigest, resources, r.Resolver.processor.ClusterData)
+	err = core.BypassReleaser(ctx, app, imageTagWithDigest, resources, r.Resolver.processor.ClusterData)
 	if err != nil {
 		r.logger.Error(err.Error())
 		return nil, err
@@ -762,8 +932,13 @@ func (r *mutationResolver) UnsetRacExclusion(ctx context.Context, input model.Se
 }
 
 func (r *mutationResolver) ImportApp(ctx context.Context, input model.ImportAppInput) (*model.TuberApp, error) {
+	err := canCreateApps(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	var newApp model.TuberApp
-	err := json.Unmarshal([]byte(input.App), &newApp)
+	err = json.Unmarshal([]byte(input.App), &newApp)
 	if err != nil {
 		return nil, err
 	}
@@ -778,7 +953,7 @@ func (r *mutationResolver) ImportApp(ctx context.Context, input model.ImportAppI
 	}
 
 	if input.SourceAppName != "" {
-		err = reviewapps.NewReviewAppSetup(input.SourceAppName, app.Name)
+		err = reviewapps.NewReviewAppSetup(ctx, input.SourceAppName, app.Name)
 		if err != nil {
 			return nil, fmt.Errorf("error duplicating namespace, rolebindings, or secrets: %v", err)
 		}
@@ -813,6 +988,251 @@ func (r *mutationResolver) SaveAllApps(ctx context.Context) (*bool, error) {
 	return nil, nil
 }
 
+func (r *mutationResolver) ReplayEvent(ctx context.Context, id string) (*model.InboxEvent, error) {
+	err := canCreateApps(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := r.Resolver.inbox.Replay(id)
+	if err != nil {
+		if errors.As(err, &db.NotFoundError{}) {
+			return nil, errors.New("could not find event")
+		}
+
+		return nil, err
+	}
+
+	return event, nil
+}
+
+func (r *mutationResolver) CreateFreezeWindow(ctx context.Context, input model.FreezeWindowInput) (*model.FreezeWindow, error) {
+	err := canCreateApps(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	window := &model.FreezeWindow{
+		Name:        input.Name,
+		IncludeApps: input.IncludeApps,
+		ExcludeApps: input.ExcludeApps,
+	}
+	if input.Reason != nil {
+		window.Reason = *input.Reason
+	}
+	if input.Start != nil {
+		window.Start = *input.Start
+	}
+	if input.End != nil {
+		window.End = *input.End
+	}
+	if input.Cron != nil {
+		window.Cron = *input.Cron
+	}
+	if input.Duration != nil {
+		window.Duration = *input.Duration
+	}
+	if input.Timezone != nil {
+		window.Timezone = *input.Timezone
+	}
+	if window.IncludeApps == nil {
+		window.IncludeApps = []string{}
+	}
+	if window.ExcludeApps == nil {
+		window.ExcludeApps = []string{}
+	}
+
+	err = window.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.Resolver.db.FreezeWindow(window.Name)
+	if err == nil {
+		return nil, fmt.Errorf("freeze window %s already exists", window.Name)
+	}
+	if !errors.As(err, &db.NotFoundError{}) {
+		return nil, fmt.Errorf("unexpected error while checking for freeze window: %v", err)
+	}
+
+	err = r.Resolver.db.SaveFreezeWindow(window)
+	if err != nil {
+		return nil, err
+	}
+
+	return window, nil
+}
+
+func (r *mutationResolver) RemoveFreezeWindow(ctx context.Context, name string) (*model.FreezeWindow, error) {
+	err := canCreateApps(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	window, err := r.Resolver.db.FreezeWindow(name)
+	if err != nil {
+		if errors.As(err, &db.NotFoundError{}) {
+			return nil, errors.New("could not find freeze window")
+		}
+
+		return nil, fmt.Errorf("unexpected error while trying to find freeze window: %v", err)
+	}
+
+	err = r.Resolver.db.DeleteFreezeWindow(window)
+	if err != nil {
+		return nil, err
+	}
+
+	return window, nil
+}
+
+func (r *mutationResolver) PauseCluster(ctx context.Context, input model.PauseClusterInput) (*model.ClusterInfo, error) {
+	err := canCreateApps(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(input.Reason) == "" {
+		return nil, errors.New("a reason is required to pause the cluster")
+	}
+
+	pausedBy := oauth.IdentityOrUnknown(ctx)
+	_, err = r.Resolver.db.PauseCluster(input.Reason, pausedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Warn("releases paused cluster-wide", zap.String("reason", input.Reason), zap.String("pausedBy", pausedBy))
+	return r.Resolver.clusterInfo()
+}
+
+func (r *mutationResolver) ResumeCluster(ctx context.Context) (*model.ClusterInfo, error) {
+	err := canCreateApps(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.Resolver.db.ResumeCluster()
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Warn("releases resumed cluster-wide", zap.String("resumedBy", oauth.IdentityOrUnknown(ctx)))
+	return r.Resolver.clusterInfo()
+}
+
+func (r *mutationResolver) SetRequireApproval(ctx context.Context, input model.AppInput) (*model.TuberApp, error) {
+	// anyone who could turn the gate off could skip it, so it's an admin setting
+	err := canCreateApps(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	app, err := r.Resolver.db.App(input.Name)
+	if err != nil {
+		if errors.As(err, &db.NotFoundError{}) {
+			return nil, errors.New("could not find app")
+		}
+
+		return nil, fmt.Errorf("unexpected error while trying to find app: %v", err)
+	}
+
+	if input.RequireApproval == nil {
+		return nil, fmt.Errorf("RequireApproval required for SetRequireApproval")
+	}
+
+	app.RequireApproval = *input.RequireApproval
+
+	err = r.Resolver.db.SaveApp(app)
+	if err != nil {
+		return nil, fmt.Errorf("could not save changes: %v", err)
+	}
+
+	return app, nil
+}
+
+func (r *mutationResolver) ApproveRelease(ctx context.Context, input model.ReleaseDecisionInput) (*model.PendingRelease, error) {
+	err := canUpdateDeployments(ctx, input.AppName)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.Resolver.releasesPaused()
+	if err != nil {
+		return nil, err
+	}
+
+	// approvers have to be known, or there's no telling whether they requested the release themselves
+	approvedBy, err := oauth.Identity(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not tell who is approving: %v", err)
+	}
+
+	return r.Resolver.processor.Approve(input.AppName, approvedBy)
+}
+
+func (r *mutationResolver) RejectRelease(ctx context.Context, input model.ReleaseDecisionInput) (*model.PendingRelease, error) {
+	err := canUpdateDeployments(ctx, input.AppName)
+	if err != nil {
+		return nil, err
+	}
+
+	var reason string
+	if input.Reason != nil {
+		reason = *input.Reason
+	}
+
+	return r.Resolver.processor.Reject(input.AppName, oauth.IdentityOrUnknown(ctx), reason)
+}
+
+func (r *mutationResolver) CreateAPIToken(ctx context.Context, input model.APITokenInput) (*model.CreatedAPIToken, error) {
+	err := canCreateApps(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	apiToken, token, err := model.NewAPIToken(input.Name, input.Apps, input.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	apiToken.CreatedBy = oauth.IdentityOrUnknown(ctx)
+	apiToken.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	err = r.Resolver.db.SaveAPIToken(apiToken)
+	if err != nil {
+		return nil, fmt.Errorf("could not save api token: %v", err)
+	}
+
+	return &model.CreatedAPIToken{Token: token, APIToken: apiToken}, nil
+}
+
+func (r *mutationResolver) RevokeAPIToken(ctx context.Context, id string) (*model.APIToken, error) {
+	err := canCreateApps(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	apiToken, err := r.Resolver.db.APIToken(id)
+	if err != nil {
+		if errors.As(err, &db.NotFoundError{}) {
+			return nil, errors.New("could not find api token")
+		}
+
+		return nil, fmt.Errorf("unexpected error while trying to find api token: %v", err)
+	}
+
+	err = r.Resolver.db.DeleteAPIToken(apiToken)
+	if err != nil {
+		return nil, fmt.Errorf("could not revoke api token: %v", err)
+	}
+
+	return apiToken, nil
+}
+
+func (r *pendingReleaseResolver) Expired(ctx context.Context, obj *model.PendingRelease) (bool, error) {
+	return obj.ExpiredAt(time.Now()), nil
+}
+
 func (r *queryResolver) GetAppEnv(ctx context.Context, name string) ([]*model.Tuple, error) {
 	err := canGetSecret(ctx, name, name+"-env")
 	if err != nil {
@@ -821,7 +1241,7 @@ func (r *queryResolver) GetAppEnv(ctx context.Context, name string) ([]*model.Tu
 
 	mapName := fmt.Sprintf("%s-env", name)
 	var config *k8s.ConfigResource
-	config, err = k8s.GetConfigResource(mapName, name, "Secret")
+	config, err = k8s.GetConfigResource(ctx, mapName, name, "Secret")
 	if err != nil {
 		return nil, err
 	}
@@ -852,21 +1272,21 @@ func (r *queryResolver) GetApp(ctx context.Context, name string) (*model.TuberAp
 }
 
 func (r *queryResolver) GetApps(ctx context.Context) ([]*model.TuberApp, error) {
-	err := canViewAllApps(ctx)
+	apps, err := r.Resolver.db.SourceApps()
 	if err != nil {
 		return nil, err
 	}
 
-	return r.Resolver.db.SourceApps()
+	return filterApps(ctx, apps), nil
 }
 
 func (r *queryResolver) GetAllReviewApps(ctx context.Context) ([]*model.TuberApp, error) {
-	err := canViewAllApps(ctx)
+	apps, err := r.Resolver.db.ReviewApps()
 	if err != nil {
 		return nil, err
 	}
 
-	return r.Resolver.db.ReviewApps()
+	return filterApps(ctx, apps), nil
 }
 
 func (r *queryResolver) GetClusterInfo(ctx context.Context) (*model.ClusterInfo, error) {
@@ -875,11 +1295,193 @@ func (r *queryResolver) GetClusterInfo(ctx context.Context) (*model.ClusterInfo,
 		return nil, err
 	}
 
-	return &model.ClusterInfo{
-		Name:              r.Resolver.clusterName,
-		Region:            r.Resolver.clusterRegion,
-		ReviewAppsEnabled: r.Resolver.reviewAppsEnabled,
-	}, nil
+	return r.Resolver.clusterInfo()
+}
+
+func (r *queryResolver) GetInboxEvents(ctx context.Context, status *string) ([]*model.InboxEvent, error) {
+	err := canViewAllApps(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var filter string
+	if status != nil {
+		filter = *status
+	}
+
+	return r.Resolver.db.InboxEvents(filter)
+}
+
+func (r *queryResolver) GetFreezeWindows(ctx context.Context) ([]*model.FreezeWindow, error) {
+	err := canViewAllApps(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Resolver.db.FreezeWindows()
+}
+
+func (r *queryResolver) GetPendingReleases(ctx context.Context, appName *string) ([]*model.PendingRelease, error) {
+	if appName != nil {
+		err := canGetDeployments(ctx, *appName)
+		if err != nil {
+			return nil, err
+		}
+
+		return r.Resolver.db.PendingReleases(*appName)
+	}
+
+	releases, err := r.Resolver.db.PendingReleases("")
+	if err != nil {
+		return nil, err
+	}
+
+	var appNames []string
+	for _, release := range releases {
+		appNames = append(appNames, release.AppName)
+	}
+	visible, all := visibleApps(ctx, appNames)
+	if all {
+		return releases, nil
+	}
+
+	filtered := []*model.PendingRelease{}
+	for _, release := range releases {
+		if visible[release.AppName] {
+			filtered = append(filtered, release)
+		}
+	}
+	return filtered, nil
+}
+
+func (r *queryResolver) GetWebhookDeliveries(ctx context.Context, appName string) ([]*model.WebhookDelivery, error) {
+	err := canGetDeployments(ctx, appName)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Resolver.db.WebhookDeliveries(appName)
+}
+
+func (r *queryResolver) GetAuditLog(ctx context.Context, appName *string, since *string) ([]*model.AuditEntry, error) {
+	var sinceTime time.Time
+	if since != nil && *since != "" {
+		var err error
+		sinceTime, err = time.Parse(time.RFC3339, *since)
+		if err != nil {
+			return nil, fmt.Errorf("since must be an RFC3339 timestamp: %v", err)
+		}
+	}
+
+	if appName != nil && *appName != "" {
+		err := canGetDeployments(ctx, *appName)
+		if err != nil {
+			return nil, err
+		}
+
+		return r.Resolver.db.AuditLog(*appName, sinceTime)
+	}
+
+	entries, err := r.Resolver.db.AuditLog("", sinceTime)
+	if err != nil {
+		return nil, err
+	}
+
+	var appNames []string
+	for _, entry := range entries {
+		if entry.AppName != "" {
+			appNames = append(appNames, entry.AppName)
+		}
+	}
+	visible, all := visibleApps(ctx, appNames)
+	if all {
+		return entries, nil
+	}
+
+	// cluster-wide entries have no app, and stay with people who can see every app
+	filtered := []*model.AuditEntry{}
+	for _, entry := range entries {
+		if visible[entry.AppName] {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered, nil
+}
+
+func (r *queryResolver) GetLogs(ctx context.Context, input model.LogsInput) ([]*model.LogLine, error) {
+	err := canGetLogs(ctx, input.AppName)
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := logOptions(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := k8s.Logs(ctx, input.AppName, options)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected error while getting logs: %v", err)
+	}
+
+	logLines := []*model.LogLine{}
+	for _, line := range lines {
+		logLines = append(logLines, logLineModel(line))
+	}
+	return logLines, nil
+}
+
+func (r *queryResolver) GetAPITokens(ctx context.Context) ([]*model.APIToken, error) {
+	err := canCreateApps(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Resolver.db.APITokens()
+}
+
+func (r *subscriptionResolver) ReleaseProgress(ctx context.Context, appName string) (<-chan *model.ReleaseProgress, error) {
+	err := canGetDeployments(ctx, appName)
+	if err != nil {
+		return nil, err
+	}
+
+	events, stop := r.Resolver.processor.Watch(appName)
+	progress := make(chan *model.ReleaseProgress)
+	go func() {
+		defer close(progress)
+		defer stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-events:
+				select {
+				case progress <- releaseProgress(event):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return progress, nil
+}
+
+func (r *subscriptionResolver) Logs(ctx context.Context, input model.LogsInput) (<-chan *model.LogLine, error) {
+	err := canGetLogs(ctx, input.AppName)
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := logOptions(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return streamLogLines(ctx, r.logger.With(zap.String("appName", input.AppName)), func(lines chan<- k8s.LogLine) error {
+		return k8s.StreamLogs(ctx, input.AppName, options, lines)
+	}), nil
 }
 
 func (r *tuberAppResolver) ReviewApps(ctx context.Context, obj *model.TuberApp) ([]*model.TuberApp, error) {
@@ -889,7 +1491,12 @@ func (r *tuberAppResolver) ReviewApps(ctx context.Context, obj *model.TuberApp)
 		return nil, err
 	}
 
-	return r.db.ReviewAppsFor(obj)
+	apps, err := r.db.ReviewAppsFor(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return filterApps(ctx, apps), nil
 }
 
 func (r *tuberAppResolver) CloudBuildStatuses(ctx context.Context, obj *model.TuberApp) ([]*model.Build, error) {
@@ -906,15 +1513,43 @@ func (r *tuberAppResolver) CloudBuildStatuses(ctx context.Context, obj *model.Tu
 	return builds, nil
 }
 
+func (r *tuberAppResolver) Workloads(ctx context.Context, obj *model.TuberApp) ([]*model.Workload, error) {
+	err := canGetDeployments(ctx, obj.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	workloads, err := k8s.Workloads(ctx, obj.Name)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected error while getting workloads: %v", err)
+	}
+
+	return workloadModels(workloads, time.Now()), nil
+}
+
+// FreezeWindow returns generated.FreezeWindowResolver implementation.
+func (r *Resolver) FreezeWindow() generated.FreezeWindowResolver { return &freezeWindowResolver{r} }
+
 // Mutation returns generated.MutationResolver implementation.
 func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResolver{r} }
 
+// PendingRelease returns generated.PendingReleaseResolver implementation.
+func (r *Resolver) PendingRelease() generated.PendingReleaseResolver {
+	return &pendingReleaseResolver{r}
+}
+
 // Query returns generated.QueryResolver implementation.
 func (r *Resolver) Query() generated.QueryResolver { return &queryResolver{r} }
 
+// Subscription returns generated.SubscriptionResolver implementation.
+func (r *Resolver) Subscription() generated.SubscriptionResolver { return &subscriptionResolver{r} }
+
 // TuberApp returns generated.TuberAppResolver implementation.
 func (r *Resolver) TuberApp() generated.TuberAppResolver { return &tuberAppResolver{r} }
 
+type freezeWindowResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
+type pendingReleaseResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type subscriptionResolver struct{ *Resolver }
 type tuberAppResolver struct{ *Resolver }
@@ -0,0 +1,258 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/core"
+	"github.com/freshly/tuber/pkg/events"
+	"github.com/freshly/tuber/pkg/notify"
+	"github.com/freshly/tuber/pkg/oauth"
+	"github.com/freshly/tuber/pkg/report"
+	"github.com/freshly/tuber/pkg/slack"
+	slackapi "github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+const slackMaxBodySize = 1 << 20
+
+const slackCommandHelp = "usage: `/tuber status|deploy|pause|resume [app name]`, `/tuber deploy [app name] [image tag]` to deploy a specific tag"
+
+// slackHandler serves slack button clicks and /tuber slash commands. They run through the same resolvers and
+// authorization as the graphql api, impersonating the slack user's identity - tuber's service account needs to be allowed to impersonate users.
+type slackHandler struct {
+	resolver      *Resolver
+	logger        *zap.Logger
+	signingSecret string
+	users         *slack.Users
+	auditor       *Auditor
+}
+
+// SlackHandler serves <path>/interactions and <path>/commands, verifying requests with slack's signing secret
+func SlackHandler(db *core.DB, processor *events.Processor, inbox *events.Inbox, logger *zap.Logger, credentials []byte, projectName string, clusterName string, clusterRegion string,
+	reviewAppsEnabled bool, webhookHosts *notify.WebhookHosts, signingSecret string, users *slack.Users, auditor *Auditor) http.Handler {
+	return &slackHandler{
+		resolver:      NewResolver(db, logger, processor, inbox, credentials, projectName, clusterName, clusterRegion, reviewAppsEnabled, webhookHosts),
+		logger:        logger.With(zap.String("context", "slack")),
+		signingSecret: signingSecret,
+		users:         users,
+		auditor:       auditor,
+	}
+}
+
+func (h *slackHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, slackMaxBodySize))
+	if err != nil {
+		http.Error(w, "unreadable body", http.StatusBadRequest)
+		return
+	}
+
+	verifier, err := slackapi.NewSecretsVerifier(r.Header, h.signingSecret)
+	if err == nil {
+		_, err = verifier.Write(body)
+	}
+	if err == nil {
+		err = verifier.Ensure()
+	}
+	if err != nil {
+		h.logger.Warn("slack request verification failed", zap.Error(err))
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "invalid form body", http.StatusBadRequest)
+		return
+	}
+
+	// slack wants an answer within 3 seconds, so work happens after acking and replies go to the response url
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/commands"):
+		go h.command(form.Get("user_id"), form.Get("text"), form.Get("response_url"))
+	case strings.HasSuffix(r.URL.Path, "/interactions"):
+		var callback slackapi.InteractionCallback
+		err = json.Unmarshal([]byte(form.Get("payload")), &callback)
+		if err != nil {
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+		go h.interaction(callback)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// interaction runs a button click, then replaces the message it came from so the buttons can't be clicked twice
+func (h *slackHandler) interaction(callback slackapi.InteractionCallback) {
+	if len(callback.ActionCallback.BlockActions) == 0 {
+		return
+	}
+	action := callback.ActionCallback.BlockActions[0]
+
+	var value slack.ActionValue
+	err := json.Unmarshal([]byte(action.Value), &value)
+	if err != nil {
+		h.logger.Warn("invalid slack action value", zap.Error(err), zap.String("value", action.Value))
+		return
+	}
+
+	logger := h.logger.With(zap.String("action", action.ActionID), zap.String("appName", value.App), zap.String("slackUser", callback.User.ID))
+
+	result := func() string {
+		ctx, identity, err := h.impersonate(callback.User.ID)
+		if err != nil {
+			return ":warning: " + err.Error()
+		}
+
+		mutation := h.resolver.Mutation()
+		switch action.ActionID {
+		case slack.ActionRollback:
+			input := model.AppInput{Name: value.App}
+			_, err = mutation.Rollback(ctx, input)
+			h.auditor.Record(ctx, "rollback", input, err)
+			if err == nil {
+				return ":rewind: rolled back by " + identity
+			}
+		case slack.ActionResume:
+			paused := false
+			input := model.AppInput{Name: value.App, Paused: &paused}
+			_, err = mutation.UpdateApp(ctx, input)
+			h.auditor.Record(ctx, "updateApp", input, err)
+			if err == nil {
+				return ":arrow_forward: resumed by " + identity
+			}
+		case slack.ActionRetry:
+			tag := value.Tag
+			input := model.AppInput{Name: value.App, ImageTag: &tag}
+			_, err = mutation.Deploy(ctx, input)
+			h.auditor.Record(ctx, "deploy", input, err)
+			if err == nil {
+				return ":repeat: retry of " + tag + " started by " + identity
+			}
+		default:
+			return ":warning: unknown action " + action.ActionID
+		}
+		return ":warning: " + action.ActionID + " by " + identity + " failed: " + err.Error()
+	}()
+
+	logger.Info("slack action handled", zap.String("result", result))
+	h.respond(logger, callback.ResponseURL, slack.Response{Text: callback.Message.Text + "\n" + result, ReplaceOriginal: true})
+}
+
+// command runs `/tuber <subcommand> <app> [tag]`. Failures are only shown to the user who ran it.
+func (h *slackHandler) command(userID string, text string, responseURL string) {
+	logger := h.logger.With(zap.String("command", text), zap.String("slackUser", userID))
+
+	args := strings.Fields(text)
+	if len(args) < 2 || len(args) > 3 || (len(args) == 3 && args[0] != "deploy") {
+		h.respond(logger, responseURL, slack.Response{Text: slackCommandHelp, ResponseType: "ephemeral"})
+		return
+	}
+	subcommand, appName := args[0], args[1]
+
+	ctx, identity, err := h.impersonate(userID)
+	if err != nil {
+		h.respond(logger, responseURL, slack.Response{Text: ":warning: " + err.Error(), ResponseType: "ephemeral"})
+		return
+	}
+
+	mutation := h.resolver.Mutation()
+	var result string
+	switch subcommand {
+	case "status":
+		result, err = h.status(ctx, appName)
+		if err == nil {
+			h.respond(logger, responseURL, slack.Response{Text: result, ResponseType: "ephemeral"})
+			return
+		}
+	case "deploy":
+		input := model.AppInput{Name: appName}
+		if len(args) == 3 {
+			input.ImageTag = &args[2]
+		}
+		_, err = mutation.Deploy(ctx, input)
+		h.auditor.Record(ctx, "deploy", input, err)
+		result = ":rocket: *" + appName + "*: deploy started by " + identity
+	case "pause":
+		paused := true
+		input := model.AppInput{Name: appName, Paused: &paused}
+		_, err = mutation.UpdateApp(ctx, input)
+		h.auditor.Record(ctx, "updateApp", input, err)
+		result = ":double_vertical_bar: *" + appName + "*: paused by " + identity
+	case "resume":
+		paused := false
+		input := model.AppInput{Name: appName, Paused: &paused}
+		_, err = mutation.UpdateApp(ctx, input)
+		h.auditor.Record(ctx, "updateApp", input, err)
+		result = ":arrow_forward: *" + appName + "*: resumed by " + identity
+	default:
+		h.respond(logger, responseURL, slack.Response{Text: slackCommandHelp, ResponseType: "ephemeral"})
+		return
+	}
+
+	if err != nil {
+		h.respond(logger, responseURL, slack.Response{Text: ":warning: " + subcommand + " failed: " + err.Error(), ResponseType: "ephemeral"})
+		return
+	}
+
+	logger.Info("slack command handled", zap.String("result", result))
+	h.respond(logger, responseURL, slack.Response{Text: result, ResponseType: "in_channel"})
+}
+
+func (h *slackHandler) status(ctx context.Context, appName string) (string, error) {
+	app, err := h.resolver.Query().GetApp(ctx, appName)
+	if err != nil {
+		return "", err
+	}
+
+	lines := []string{
+		"*" + app.Name + "*",
+		"image tag: " + app.ImageTag,
+		"current revision: " + app.CurrentRevision,
+		fmt.Sprintf("paused: %t", app.Paused),
+	}
+
+	pending, err := h.resolver.db.PendingReleases(app.Name)
+	if err == nil && len(pending) != 0 {
+		lines = append(lines, "awaiting approval: "+pending[0].Tag+" requested by "+pending[0].RequestedBy)
+	}
+
+	state, err := h.resolver.db.ClusterState()
+	if err == nil && state.Paused {
+		lines = append(lines, ":octagonal_sign: releases are paused cluster-wide by "+state.PausedBy+": "+state.PausedReason)
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+func (h *slackHandler) impersonate(userID string) (context.Context, string, error) {
+	identity, err := h.users.Identity(userID)
+	if err != nil {
+		h.logger.Warn("slack user could not be mapped to an identity", zap.Error(err), zap.String("slackUser", userID))
+		return nil, "", fmt.Errorf("your slack user isn't mapped to an identity tuber can authorize")
+	}
+	return oauth.WithImpersonation(context.Background(), identity), identity, nil
+}
+
+func (h *slackHandler) respond(logger *zap.Logger, responseURL string, response slack.Response) {
+	err := slack.Respond(responseURL, response)
+	if err != nil {
+		logger.Error("failed to respond to slack", zap.Error(err))
+		report.Error(err, report.Scope{"context": "slack response"})
+	}
+}
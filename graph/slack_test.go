@@ -0,0 +1,64 @@
+package graph
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/freshly/tuber/pkg/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func signedSlackRequest(path string, body string, secret string) *http.Request {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":" + body))
+
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func TestSlackHandler(t *testing.T) {
+	responses := make(chan slack.Response, 1)
+	responseServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response slack.Response
+		_ = json.NewDecoder(r.Body).Decode(&response)
+		responses <- response
+	}))
+	defer responseServer.Close()
+
+	handler := SlackHandler(nil, nil, nil, zap.NewNop(), nil, "", "", "", false, nil, "signing-secret", slack.NewUsers(nil, nil), nil)
+	body := url.Values{"text": {"help"}, "user_id": {"U123"}, "response_url": {responseServer.URL}}.Encode()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, signedSlackRequest("/tuber/slack/commands", body, "wrong-secret"))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, signedSlackRequest("/tuber/slack/unknown", body, "signing-secret"))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, signedSlackRequest("/tuber/slack/commands", body, "signing-secret"))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	select {
+	case response := <-responses:
+		assert.Equal(t, slackCommandHelp, response.Text)
+		assert.Equal(t, "ephemeral", response.ResponseType)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no response posted")
+	}
+}
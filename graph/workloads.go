@@ -0,0 +1,60 @@
+package graph
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/k8s"
+)
+
+func workloadModels(workloads []k8s.Workload, now time.Time) []*model.Workload {
+	models := []*model.Workload{}
+	for _, workload := range workloads {
+		m := &model.Workload{
+			Kind:            workload.Kind,
+			Name:            workload.Name,
+			DesiredReplicas: workload.DesiredReplicas,
+			ReadyReplicas:   workload.ReadyReplicas,
+			UpdatedReplicas: workload.UpdatedReplicas,
+			Images:          append([]string{}, workload.Images...),
+			Pods:            []*model.Pod{},
+			Warnings:        []*model.WorkloadEvent{},
+		}
+		for _, pod := range workload.Pods {
+			m.Pods = append(m.Pods, &model.Pod{
+				Name:      pod.Name,
+				Phase:     pod.Phase,
+				Ready:     pod.Ready,
+				Restarts:  pod.Restarts,
+				Age:       age(now.Sub(pod.CreatedAt)),
+				CreatedAt: pod.CreatedAt.UTC().Format(time.RFC3339),
+			})
+		}
+		for _, event := range workload.Warnings {
+			m.Warnings = append(m.Warnings, &model.WorkloadEvent{
+				Reason:   event.Reason,
+				Message:  event.Message,
+				Object:   event.Object,
+				Count:    event.Count,
+				LastSeen: event.LastSeen.UTC().Format(time.RFC3339),
+			})
+		}
+		models = append(models, m)
+	}
+	return models
+}
+
+// age is a duration the way kubectl shows them - 45s, 12m, 5h, 3d
+func age(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
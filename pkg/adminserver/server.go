@@ -8,15 +8,22 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/freshly/tuber/graph"
+	"github.com/freshly/tuber/graph/model"
 	"github.com/freshly/tuber/pkg/config"
 	"github.com/freshly/tuber/pkg/core"
 	"github.com/freshly/tuber/pkg/events"
 	"github.com/freshly/tuber/pkg/iap"
+	"github.com/freshly/tuber/pkg/metrics"
+	"github.com/freshly/tuber/pkg/notify"
 	"github.com/freshly/tuber/pkg/oauth"
+	"github.com/freshly/tuber/pkg/pubsub"
+	"github.com/freshly/tuber/pkg/slack"
+	"github.com/freshly/tuber/pkg/webhook"
 	"github.com/go-http-utils/logger"
 	"github.com/gorilla/securecookie"
 	"go.uber.org/zap"
@@ -34,17 +41,25 @@ type server struct {
 	db                  *core.DB
 	port                string
 	processor           *events.Processor
+	inbox               *events.Inbox
 	clusterName         string
 	clusterRegion       string
 	prefix              string
 	useDevServer        bool
 	authenticator       *oauth.Authenticator
 	secureCookie        *securecookie.SecureCookie
+	webhookSources      map[string]webhook.Source
+	localSources        map[string]*pubsub.LocalSource
+	slackSigningSecret  string
+	slackUsers          *slack.Users
+	auditor             *graph.Auditor
+	webhookHosts        *notify.WebhookHosts
 }
 
-func Start(ctx context.Context, logger *zap.Logger, db *core.DB, processor *events.Processor, triggersProjectName string,
+func Start(ctx context.Context, logger *zap.Logger, db *core.DB, processor *events.Processor, inbox *events.Inbox, triggersProjectName string,
 	creds []byte, reviewAppsEnabled bool, clusterDefaultHost string, port string, clusterName string, clusterRegion string,
-	prefix string, useDevServer bool, authenticator *oauth.Authenticator, secureCookie *securecookie.SecureCookie) error {
+	prefix string, useDevServer bool, authenticator *oauth.Authenticator, secureCookie *securecookie.SecureCookie,
+	webhookSources map[string]webhook.Source, localSources map[string]*pubsub.LocalSource, slackSigningSecret string, slackUsers *slack.Users, auditor *graph.Auditor, webhookHosts *notify.WebhookHosts) error {
 	var cloudbuildClient *cloudbuild.Service
 
 	if reviewAppsEnabled {
@@ -65,12 +80,19 @@ func Start(ctx context.Context, logger *zap.Logger, db *core.DB, processor *even
 		db:                  db,
 		port:                port,
 		processor:           processor,
+		inbox:               inbox,
 		clusterName:         clusterName,
 		clusterRegion:       clusterRegion,
 		prefix:              prefix,
 		useDevServer:        useDevServer,
 		authenticator:       authenticator,
 		secureCookie:        secureCookie,
+		webhookSources:      webhookSources,
+		localSources:        localSources,
+		slackSigningSecret:  slackSigningSecret,
+		slackUsers:          slackUsers,
+		auditor:             auditor,
+		webhookHosts:        webhookHosts,
 	}.start()
 }
 
@@ -84,6 +106,16 @@ func (s server) requireAuth(next http.Handler) http.Handler {
 		if s.useDevServer {
 			w, r = s.devServerAuth(w, r)
 		}
+		if token, ok := oauth.BearerToken(r); ok && strings.HasPrefix(token, model.APITokenPrefix) {
+			r, err = s.apiTokenAuth(r, token)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		var authed bool
 		r, authed = s.authenticator.TrySetHeaderAuthContext(r)
 		if authed {
@@ -107,6 +139,34 @@ func (s server) requireAuth(next http.Handler) http.Handler {
 	})
 }
 
+// apiTokenLastUsedInterval is how stale a token's last used time gets before a request updates it, so busy tokens aren't a write per request
+const apiTokenLastUsedInterval = time.Minute
+
+// apiTokenAuth authenticates a request with an api token, leaving authorization to the token's scopes
+func (s server) apiTokenAuth(r *http.Request, token string) (*http.Request, error) {
+	apiToken, err := s.db.APITokenByHash(model.APITokenHash(token))
+	if err != nil {
+		return nil, fmt.Errorf("invalid api token")
+	}
+
+	now := time.Now().UTC()
+	lastUsed, err := time.Parse(time.RFC3339, apiToken.LastUsedAt)
+	if err != nil || now.Sub(lastUsed) > apiTokenLastUsedInterval {
+		apiToken.LastUsedAt = now.Format(time.RFC3339)
+		err = s.db.SaveAPIToken(apiToken)
+		if err != nil {
+			s.logger.Error("failed to record api token use", zap.String("token", apiToken.Name), zap.Error(err))
+		}
+	}
+
+	return r.WithContext(oauth.WithAPIToken(r.Context(), oauth.APITokenAuth{
+		ID:     apiToken.ID,
+		Name:   apiToken.Name,
+		Apps:   apiToken.Apps,
+		Scopes: apiToken.Scopes,
+	})), nil
+}
+
 func (s server) receiveAuthRedirect(w http.ResponseWriter, r *http.Request) {
 	fmt.Println("received auth redirect")
 	queryVals := r.URL.Query()
@@ -149,11 +209,21 @@ func (s server) start() error {
 	mux.HandleFunc(s.prefixed("/"), func(w http.ResponseWriter, r *http.Request) { s.requireAuth(proxy).ServeHTTP(w, r) })
 	mux.HandleFunc(s.prefixed("/_next/"), func(w http.ResponseWriter, r *http.Request) { proxy.ServeHTTP(w, r) })
 	mux.HandleFunc(s.prefixed("/graphql/playground"), playground.Handler("GraphQL playground", s.prefixed("/graphql")))
-	mux.Handle(s.prefixed("/graphql"), s.requireAuth(graph.Handler(s.db, s.processor, s.logger, s.creds, s.triggersProjectName, s.clusterName, s.clusterRegion, s.reviewAppsEnabled)))
+	mux.Handle(s.prefixed("/graphql"), s.requireAuth(graph.Handler(s.db, s.processor, s.inbox, s.logger, s.creds, s.triggersProjectName, s.clusterName, s.clusterRegion, s.reviewAppsEnabled, s.webhookHosts, s.auditor)))
+	mux.Handle(s.prefixed("/db"), s.requireAuth(graph.DatabaseHandler(s.db, s.logger, s.auditor)))
 	mux.HandleFunc(s.prefixed("/unauthorized/"), unauthorized)
 	mux.HandleFunc(s.prefixed("/auth/"), s.receiveAuthRedirect)
+	mux.Handle(s.prefixed("/webhooks/"), webhook.NewHandler(s.logger, s.inbox, s.webhookSources, s.prefixed("/webhooks/")))
+	if s.slackSigningSecret != "" {
+		mux.Handle(s.prefixed("/slack/"), graph.SlackHandler(s.db, s.processor, s.inbox, s.logger, s.creds, s.triggersProjectName, s.clusterName, s.clusterRegion, s.reviewAppsEnabled, s.webhookHosts, s.slackSigningSecret, s.slackUsers, s.auditor))
+	}
+	for name, source := range s.localSources {
+		mux.Handle(s.prefixed("/events/local/"+name), source)
+	}
+	// unprefixed, so it's only reachable in-cluster by whatever scrapes the pod, not through the ingress
+	mux.Handle("/metrics", metrics.Handler())
 
-	handler := logger.Handler(mux, os.Stdout, logger.DevLoggerType)
+	handler := skipLoggingUpgrades(logger.Handler(mux, os.Stdout, logger.DevLoggerType), mux)
 
 	port := ":3000"
 	if s.port != "" {
@@ -167,6 +237,17 @@ func (s server) start() error {
 	return http.ListenAndServe(port, handler)
 }
 
+// skipLoggingUpgrades serves websocket upgrades, for graphql subscriptions, straight from mux - the request logger's response writer can't be hijacked
+func skipLoggingUpgrades(logged http.Handler, mux http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			mux.ServeHTTP(w, r)
+			return
+		}
+		logged.ServeHTTP(w, r)
+	})
+}
+
 // cmon it's kinda cool
 func (s server) devServerAuth(w http.ResponseWriter, r *http.Request) (http.ResponseWriter, *http.Request) {
 	var refreshFound bool
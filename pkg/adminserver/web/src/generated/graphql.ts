@@ -54,7 +54,7 @@ export type Mutation = {
   createApp?: Maybe<TuberApp>;
   updateApp?: Maybe<TuberApp>;
   removeApp?: Maybe<TuberApp>;
-  deploy?: Maybe<TuberApp>;
+  deploy?: Maybe<StartedRelease>;
   destroyApp?: Maybe<TuberApp>;
   createReviewApp?: Maybe<TuberApp>;
   setAppVar?: Maybe<TuberApp>;
@@ -63,7 +63,7 @@ export type Mutation = {
   unsetAppEnv?: Maybe<TuberApp>;
   setExcludedResource?: Maybe<TuberApp>;
   unsetExcludedResource?: Maybe<TuberApp>;
-  rollback?: Maybe<TuberApp>;
+  rollback?: Maybe<StartedRelease>;
   setGithubRepo?: Maybe<TuberApp>;
   setCloudSourceRepo?: Maybe<TuberApp>;
   setSlackChannel?: Maybe<TuberApp>;
@@ -240,6 +240,13 @@ export type State = {
   Previous: Array<Resource>;
 };
 
+export type StartedRelease = {
+  __typename?: 'StartedRelease';
+  app: TuberApp;
+  releaseId: Scalars['String'];
+  tag: Scalars['String'];
+};
+
 export type TuberApp = {
   __typename?: 'TuberApp';
   cloudSourceRepo: Scalars['String'];
@@ -287,8 +294,12 @@ export type DeployMutationVariables = Exact<{
 export type DeployMutation = (
   { __typename?: 'Mutation' }
   & { deploy?: Maybe<(
-    { __typename?: 'TuberApp' }
-    & Pick<TuberApp, 'name'>
+    { __typename?: 'StartedRelease' }
+    & Pick<StartedRelease, 'releaseId'>
+    & { app: (
+      { __typename?: 'TuberApp' }
+      & Pick<TuberApp, 'name'>
+    ) }
   )> }
 );
 
@@ -1649,7 +1660,10 @@ export function useCreateReviewAppMutation() {
 export const DeployDocument = gql`
     mutation Deploy($input: AppInput!) {
   deploy(input: $input) {
-    name
+    releaseId
+    app {
+      name
+    }
   }
 }
     `;
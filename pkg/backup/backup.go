@@ -0,0 +1,212 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/freshly/tuber/pkg/core"
+	"github.com/freshly/tuber/pkg/k8s"
+	"github.com/freshly/tuber/pkg/report"
+	"go.uber.org/zap"
+)
+
+// namePrefix starts every scheduled backup's name, which is followed by when it was taken, so names sort oldest first
+const namePrefix = "tuber-"
+
+const nameTimeFormat = "20060102-150405"
+
+// labelKey marks secrets holding backups, with the target's name as its value
+const labelKey = "tuber-backup"
+
+// dataKey is the gzipped db's key in a backup secret
+const dataKey = "db.gz"
+
+// Target is somewhere scheduled backups are kept. Backups are gzipped bolt files.
+type Target interface {
+	Save(ctx context.Context, name string, backup []byte) error
+	List(ctx context.Context) ([]string, error)
+	Delete(ctx context.Context, name string) error
+	String() string
+}
+
+// Scheduler backs up the db on an interval, keeping the most recent backups
+type Scheduler struct {
+	ctx       context.Context
+	logger    *zap.Logger
+	db        *core.DB
+	target    Target
+	interval  time.Duration
+	retention int
+}
+
+func NewScheduler(ctx context.Context, logger *zap.Logger, db *core.DB, target Target, interval time.Duration, retention int) *Scheduler {
+	return &Scheduler{
+		ctx:       ctx,
+		logger:    logger.With(zap.String("context", "backups"), zap.String("target", target.String())),
+		db:        db,
+		target:    target,
+		interval:  interval,
+		retention: retention,
+	}
+}
+
+func (s *Scheduler) Start() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			s.logger.Debug("backups stopped")
+			return
+		case <-ticker.C:
+			name, err := s.Run(s.ctx)
+			if err != nil {
+				s.logger.Error("scheduled backup failed", zap.Error(err))
+				report.Error(err, report.Scope{"context": "scheduled backup"})
+				continue
+			}
+			s.logger.Info("backed up db", zap.String("backup", name))
+		}
+	}
+}
+
+// Run takes a backup, then deletes all but the most recent ones. It returns the new backup's name.
+func (s *Scheduler) Run(ctx context.Context) (string, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := s.db.Backup(gz)
+	if err != nil {
+		return "", err
+	}
+	err = gz.Close()
+	if err != nil {
+		return "", err
+	}
+
+	name := namePrefix + time.Now().UTC().Format(nameTimeFormat)
+	err = s.target.Save(ctx, name, buf.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("saving backup %s: %v", name, err)
+	}
+
+	return name, s.prune(ctx)
+}
+
+func (s *Scheduler) prune(ctx context.Context) error {
+	names, err := s.target.List(ctx)
+	if err != nil {
+		return fmt.Errorf("listing backups: %v", err)
+	}
+
+	sort.Strings(names)
+	for i := 0; i < len(names)-s.retention; i++ {
+		err = s.target.Delete(ctx, names[i])
+		if err != nil {
+			return fmt.Errorf("deleting backup %s: %v", names[i], err)
+		}
+	}
+	return nil
+}
+
+// Dir keeps backups as files in a directory, like a mounted volume
+type Dir struct {
+	path string
+}
+
+func NewDir(path string) *Dir {
+	return &Dir{path: path}
+}
+
+func (d *Dir) String() string {
+	return "dir:" + d.path
+}
+
+func (d *Dir) Save(ctx context.Context, name string, backup []byte) error {
+	err := os.MkdirAll(d.path, 0755)
+	if err != nil {
+		return err
+	}
+	// written aside and renamed, so a partial write never looks like a backup
+	path := filepath.Join(d.path, name+".db.gz")
+	err = ioutil.WriteFile(path+".tmp", backup, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+func (d *Dir) List(ctx context.Context) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(d.path, namePrefix+"*.db.gz"))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, path := range paths {
+		names = append(names, strings.TrimSuffix(filepath.Base(path), ".db.gz"))
+	}
+	return names, nil
+}
+
+func (d *Dir) Delete(ctx context.Context, name string) error {
+	return os.Remove(filepath.Join(d.path, name+".db.gz"))
+}
+
+// Secret keeps each backup in its own secret, labeled with the target's name.
+// Secrets are capped at 1MB, so this is for smaller clusters.
+type Secret struct {
+	name      string
+	namespace string
+}
+
+func NewSecret(name string, namespace string) *Secret {
+	return &Secret{name: name, namespace: namespace}
+}
+
+func (s *Secret) String() string {
+	return "secret:" + s.namespace + "/" + s.name
+}
+
+func (s *Secret) Save(ctx context.Context, name string, backup []byte) error {
+	manifest, err := json.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"type":       "Opaque",
+		"metadata": map[string]interface{}{
+			"name":      s.name + "-" + strings.TrimPrefix(name, namePrefix),
+			"namespace": s.namespace,
+			"labels":    map[string]string{labelKey: s.name},
+		},
+		"data": map[string]string{dataKey: base64.StdEncoding.EncodeToString(backup)},
+	})
+	if err != nil {
+		return err
+	}
+	return k8s.CreateFromData(ctx, manifest, s.namespace)
+}
+
+func (s *Secret) List(ctx context.Context) ([]string, error) {
+	out, err := k8s.GetCollection(ctx, "secrets", s.namespace, "-l", labelKey+"="+s.name, "-o", "jsonpath={.items[*].metadata.name}")
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, secretName := range strings.Fields(string(out)) {
+		names = append(names, namePrefix+strings.TrimPrefix(secretName, s.name+"-"))
+	}
+	return names, nil
+}
+
+func (s *Secret) Delete(ctx context.Context, name string) error {
+	return k8s.Delete(ctx, "secret", s.name+"-"+strings.TrimPrefix(name, namePrefix), s.namespace)
+}
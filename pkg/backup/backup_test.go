@@ -0,0 +1,37 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/core"
+	"github.com/freshly/tuber/pkg/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSchedulerRun(t *testing.T) {
+	database, err := db.NewDefaultDB(filepath.Join(t.TempDir(), "db"), model.TuberApp{}.DBRoot())
+	require.NoError(t, err)
+	defer database.Close()
+
+	dir := NewDir(t.TempDir())
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		require.NoError(t, dir.Save(ctx, fmt.Sprintf("%s2021010%d-000000", namePrefix, i), []byte("old")))
+	}
+
+	scheduler := NewScheduler(ctx, zap.NewNop(), core.NewDB(database), dir, 0, 3)
+	name, err := scheduler.Run(ctx)
+	require.NoError(t, err)
+
+	names, err := dir.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, names, 3)
+	assert.Contains(t, names, name)
+	assert.NotContains(t, names, namePrefix+"20210100-000000", "oldest backups go first")
+}
@@ -2,14 +2,13 @@ package builds
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/freshly/tuber/graph/model"
 	"github.com/freshly/tuber/pkg/core"
 	"github.com/freshly/tuber/pkg/gcr"
+	"github.com/freshly/tuber/pkg/notify"
 	"github.com/freshly/tuber/pkg/pubsub"
 	"github.com/freshly/tuber/pkg/report"
-	"github.com/freshly/tuber/pkg/slack"
 	"github.com/getsentry/sentry-go"
 	"go.uber.org/zap"
 )
@@ -35,20 +34,20 @@ func newEvent(logger *zap.Logger, message pubsub.Message) *Event {
 	}
 }
 
-func NewProcessor(ctx context.Context, logger *zap.Logger, db *core.DB, slackClient *slack.Client) *Processor {
+func NewProcessor(ctx context.Context, logger *zap.Logger, db *core.DB, notifiers *notify.Notifiers) *Processor {
 	return &Processor{
-		ctx:         ctx,
-		logger:      logger,
-		db:          db,
-		slackClient: slackClient,
+		ctx:       ctx,
+		logger:    logger,
+		db:        db,
+		notifiers: notifiers,
 	}
 }
 
 type Processor struct {
-	ctx         context.Context
-	logger      *zap.Logger
-	db          *core.DB
-	slackClient *slack.Client
+	ctx       context.Context
+	logger    *zap.Logger
+	db        *core.DB
+	notifiers *notify.Notifiers
 }
 
 func (p *Processor) Process(message pubsub.Message) {
@@ -77,8 +76,7 @@ func (p *Processor) notify(event *Event) {
 	}
 
 	for _, app := range apps {
-		message := buildMessage(event, app)
-		p.slackClient.Message(p.logger, message, app.SlackChannel, slack.MsgOptionDisableLinkUnfurl())
+		p.notifiers.Notify(p.logger, app, buildNotification(event))
 	}
 }
 
@@ -113,16 +111,23 @@ func (p *Processor) appsToNotify(event *Event, repoName string) ([]*model.TuberA
 	return matches, nil
 }
 
-func buildMessage(event *Event, app *model.TuberApp) string {
-	var msg string
+func buildNotification(event *Event) notify.Event {
+	notification := notify.Event{
+		Tag:     event.Substitutions.BranchName,
+		LogLink: event.LogURL,
+	}
 	switch event.Status {
 	case "WORKING":
-		msg = fmt.Sprintf(":package: Build started for *%s*:%s - <%s|Logs>", app.Name, event.Substitutions.BranchName, event.LogURL)
+		notification.Type = notify.BuildStarted
+		notification.Message = "build started for " + event.Substitutions.BranchName
 	case "SUCCESS":
-		msg = fmt.Sprintf(":white_check_mark: Build succeeded for *%s*:%s - <%s|Logs>", app.Name, event.Substitutions.BranchName, event.LogURL)
+		notification.Type = notify.BuildSucceeded
+		notification.Message = "build succeeded for " + event.Substitutions.BranchName
 	case "FAILURE":
-		msg = fmt.Sprintf(":bomb: Build failed for *%s*:%s - <%s|Logs>", app.Name, event.Substitutions.BranchName, event.LogURL)
+		notification.Type = notify.BuildFailed
+		notification.Severity = notify.SeverityError
+		notification.Message = "build failed for " + event.Substitutions.BranchName
 	}
 
-	return msg
+	return notification
 }
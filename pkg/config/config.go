@@ -1,6 +1,7 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -48,7 +49,7 @@ func (c *tuberConfig) CurrentClusterConfig() (*cluster, error) {
 	k8sPresent := k8sCheckErr == nil
 
 	if k8sPresent {
-		kctlClusterName, err := k8s.CurrentCluster()
+		kctlClusterName, err := k8s.CurrentCluster(context.Background())
 		if err != nil {
 			return nil, fmt.Errorf("kubectl detected, but `kubectl config current-context` failed")
 		}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/db"
+)
+
+func (d *DB) APIToken(id string) (*model.APIToken, error) {
+	r, err := d.db.Find(model.APIToken{}, id)
+	if err != nil {
+		return nil, err
+	}
+	token, ok := r.(model.APIToken)
+	if !ok {
+		return nil, fmt.Errorf("db result could not be asserted as model.APIToken")
+	}
+	return &token, nil
+}
+
+// APITokenByHash finds the token a request presented, by its hash
+func (d *DB) APITokenByHash(hash string) (*model.APIToken, error) {
+	r, err := d.db.Get(model.APIToken{}, db.Q().String("hash", hash))
+	if err != nil {
+		return nil, err
+	}
+	if len(r) == 0 {
+		return nil, fmt.Errorf("api token not found")
+	}
+	token, ok := r[0].(model.APIToken)
+	if !ok {
+		return nil, fmt.Errorf("db result could not be asserted as model.APIToken")
+	}
+	return &token, nil
+}
+
+func (d *DB) APITokens() ([]*model.APIToken, error) {
+	r, err := d.db.Get(model.APIToken{}, db.Q())
+	if err != nil {
+		return nil, err
+	}
+
+	var tokens []*model.APIToken
+	for _, m := range r {
+		token, ok := m.(model.APIToken)
+		if !ok {
+			return nil, fmt.Errorf("db result could not be asserted as model.APIToken")
+		}
+		tokens = append(tokens, &token)
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt < tokens[j].CreatedAt
+	})
+	return tokens, nil
+}
+
+func (d *DB) SaveAPIToken(token *model.APIToken) error {
+	return d.db.Save(token)
+}
+
+func (d *DB) DeleteAPIToken(token *model.APIToken) error {
+	return d.db.Delete(token, token.ID)
+}
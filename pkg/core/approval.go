@@ -0,0 +1,66 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/db"
+)
+
+// PendingReleases returns releases awaiting approval, expired ones included, optionally for one app
+func (d *DB) PendingReleases(appName string) ([]*model.PendingRelease, error) {
+	query := db.Q()
+	if appName != "" {
+		query = query.String("appName", appName)
+	}
+
+	r, err := d.db.Get(model.PendingRelease{}, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var pendings []*model.PendingRelease
+	for _, m := range r {
+		pending, ok := m.(model.PendingRelease)
+		if !ok {
+			return nil, fmt.Errorf("db result could not be asserted as model.PendingRelease")
+		}
+		pendings = append(pendings, &pending)
+	}
+
+	sort.Slice(pendings, func(i, j int) bool {
+		return pendings[i].RequestedAt < pendings[j].RequestedAt
+	})
+	return pendings, nil
+}
+
+// SavePendingRelease replaces any release already awaiting approval for the app
+func (d *DB) SavePendingRelease(pending *model.PendingRelease) error {
+	return d.db.Save(pending)
+}
+
+// TakePendingRelease removes an app's pending release and returns it, so only one decision is ever made on it.
+// If check is set and errors, the pending release is left for someone else to decide on.
+func (d *DB) TakePendingRelease(appName string, check func(*model.PendingRelease) error) (*model.PendingRelease, error) {
+	var takeCheck func(db.Model) error
+	if check != nil {
+		takeCheck = func(m db.Model) error {
+			pending, ok := m.(model.PendingRelease)
+			if !ok {
+				return fmt.Errorf("db result could not be asserted as model.PendingRelease")
+			}
+			return check(&pending)
+		}
+	}
+
+	r, err := d.db.Take(model.PendingRelease{}, appName, takeCheck)
+	if err != nil {
+		return nil, err
+	}
+	pending, ok := r.(model.PendingRelease)
+	if !ok {
+		return nil, fmt.Errorf("db result could not be asserted as model.PendingRelease")
+	}
+	return &pending, nil
+}
@@ -101,7 +101,14 @@ func (d *DB) App(appName string) (*model.TuberApp, error) {
 	return assert(r)
 }
 
+// SaveApp saves an app, filling in empty state and review apps config so stored apps always have them
 func (d *DB) SaveApp(app *model.TuberApp) error {
+	if app.State == nil {
+		app.State = &model.State{}
+	}
+	if app.ReviewAppsConfig == nil {
+		app.ReviewAppsConfig = &model.ReviewAppsConfig{}
+	}
 	currentTime := time.Now().Format(app.TimestampFormat())
 	if app.CreatedAt == "" {
 		app.CreatedAt = currentTime
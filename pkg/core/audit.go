@@ -0,0 +1,70 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/db"
+)
+
+// AuditLog returns audit entries since a time, most recent first. An empty app name returns every app's entries, and cluster-wide ones.
+func (d *DB) AuditLog(appName string, since time.Time) ([]*model.AuditEntry, error) {
+	query := db.Q()
+	if appName != "" {
+		query = query.String("appName", appName)
+	}
+
+	r, err := d.db.Get(model.AuditEntry{}, query)
+	if err != nil {
+		return nil, err
+	}
+
+	after := since.UTC().Format(model.AuditEntry{}.TimestampFormat())
+	var entries []*model.AuditEntry
+	for _, m := range r {
+		entry, ok := m.(model.AuditEntry)
+		if !ok {
+			return nil, fmt.Errorf("db result could not be asserted as model.AuditEntry")
+		}
+		if entry.CreatedAt >= after {
+			entries = append(entries, &entry)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].CreatedAt > entries[j].CreatedAt
+	})
+	return entries, nil
+}
+
+func (d *DB) SaveAuditEntry(entry *model.AuditEntry) error {
+	return d.db.Save(entry)
+}
+
+// PruneAuditLog deletes audit entries from before a time, returning how many it deleted
+func (d *DB) PruneAuditLog(before time.Time) (int, error) {
+	r, err := d.db.Get(model.AuditEntry{}, db.Q())
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := before.UTC().Format(model.AuditEntry{}.TimestampFormat())
+	var pruned int
+	for _, m := range r {
+		entry, ok := m.(model.AuditEntry)
+		if !ok {
+			return pruned, fmt.Errorf("db result could not be asserted as model.AuditEntry")
+		}
+		if entry.CreatedAt >= cutoff {
+			continue
+		}
+		err = d.db.Delete(entry, entry.ID)
+		if err != nil {
+			return pruned, err
+		}
+		pruned++
+	}
+	return pruned, nil
+}
@@ -0,0 +1,19 @@
+package core
+
+import (
+	"io"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/db"
+)
+
+// Backup writes a consistent snapshot of the db
+func (d *DB) Backup(w io.Writer) (int64, error) {
+	return d.db.Backup(w)
+}
+
+// Restore replaces the db with a backup file's contents, migrated up to date with Migrations.
+// Api tokens and the audit log are kept as they are, so revoked tokens stay revoked and the audit trail survives the restore.
+func (d *DB) Restore(backupPath string) ([]db.Migration, error) {
+	return d.db.Restore(backupPath, Migrations, model.APIToken{}.DBRoot(), model.AuditEntry{}.DBRoot())
+}
@@ -0,0 +1,50 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/db"
+)
+
+// ClusterState returns cluster-wide release state, unpaused if it's never been set
+func (d *DB) ClusterState() (*model.ClusterState, error) {
+	r, err := d.db.Find(model.ClusterState{}, model.ClusterState{}.DBKey())
+	if err != nil {
+		if errors.As(err, &db.NotFoundError{}) {
+			return &model.ClusterState{}, nil
+		}
+		return nil, err
+	}
+	state, ok := r.(model.ClusterState)
+	if !ok {
+		return nil, fmt.Errorf("db result could not be asserted as model.ClusterState")
+	}
+	return &state, nil
+}
+
+// PauseCluster stops every release on the cluster until ResumeCluster
+func (d *DB) PauseCluster(reason string, pausedBy string) (*model.ClusterState, error) {
+	state := &model.ClusterState{
+		Paused:       true,
+		PausedReason: reason,
+		PausedBy:     pausedBy,
+	}
+	state.PausedAt = time.Now().UTC().Format(state.TimestampFormat())
+	err := d.db.Save(state)
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (d *DB) ResumeCluster() (*model.ClusterState, error) {
+	state := &model.ClusterState{}
+	err := d.db.Save(state)
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/db"
+)
+
+func (d *DB) FreezeWindow(name string) (*model.FreezeWindow, error) {
+	r, err := d.db.Find(model.FreezeWindow{}, name)
+	if err != nil {
+		return nil, err
+	}
+	window, ok := r.(model.FreezeWindow)
+	if !ok {
+		return nil, fmt.Errorf("db result could not be asserted as model.FreezeWindow")
+	}
+	return &window, nil
+}
+
+func (d *DB) FreezeWindows() ([]*model.FreezeWindow, error) {
+	r, err := d.db.Get(model.FreezeWindow{}, db.Q())
+	if err != nil {
+		return nil, err
+	}
+
+	var windows []*model.FreezeWindow
+	for _, m := range r {
+		window, ok := m.(model.FreezeWindow)
+		if !ok {
+			return nil, fmt.Errorf("db result could not be asserted as model.FreezeWindow")
+		}
+		windows = append(windows, &window)
+	}
+
+	sort.Slice(windows, func(i, j int) bool {
+		return windows[i].Name < windows[j].Name
+	})
+	return windows, nil
+}
+
+func (d *DB) SaveFreezeWindow(window *model.FreezeWindow) error {
+	return d.db.Save(window)
+}
+
+func (d *DB) DeleteFreezeWindow(window *model.FreezeWindow) error {
+	return d.db.Delete(window, window.Name)
+}
+
+// ActiveFreezeWindow returns the first window blocking releases of an app at a moment in time, or nil if there isn't one.
+// Windows that can't be evaluated are treated as active, so a typo doesn't silently let releases through.
+func (d *DB) ActiveFreezeWindow(appName string, at time.Time) (*model.FreezeWindow, error) {
+	windows, err := d.FreezeWindows()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, window := range windows {
+		if !window.AppliesTo(appName) {
+			continue
+		}
+		active, activeErr := window.ActiveAt(at)
+		if active || activeErr != nil {
+			return window, activeErr
+		}
+	}
+	return nil, nil
+}
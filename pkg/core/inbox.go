@@ -0,0 +1,95 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/db"
+)
+
+func (d *DB) InboxEvent(id string) (*model.InboxEvent, error) {
+	r, err := d.db.Find(model.InboxEvent{}, id)
+	if err != nil {
+		return nil, err
+	}
+	event, ok := r.(model.InboxEvent)
+	if !ok {
+		return nil, fmt.Errorf("db result could not be asserted as model.InboxEvent")
+	}
+	return &event, nil
+}
+
+// InsertInboxEvent saves a new event, returning false without saving it if an event with its id already exists
+func (d *DB) InsertInboxEvent(event *model.InboxEvent) (bool, error) {
+	currentTime := time.Now().UTC().Format(event.TimestampFormat())
+	event.ReceivedAt = currentTime
+	event.UpdatedAt = currentTime
+	return d.db.Insert(event)
+}
+
+// InboxEvents returns events oldest first, optionally filtered by status
+func (d *DB) InboxEvents(status string) ([]*model.InboxEvent, error) {
+	query := db.Q()
+	if status != "" {
+		query = query.String("status", status)
+	}
+
+	r, err := d.db.Get(model.InboxEvent{}, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []*model.InboxEvent
+	for _, m := range r {
+		event, ok := m.(model.InboxEvent)
+		if !ok {
+			return nil, fmt.Errorf("db result could not be asserted as model.InboxEvent")
+		}
+		events = append(events, &event)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].ReceivedAt < events[j].ReceivedAt
+	})
+	return events, nil
+}
+
+func (d *DB) SaveInboxEvent(event *model.InboxEvent) error {
+	currentTime := time.Now().UTC().Format(event.TimestampFormat())
+	if event.ReceivedAt == "" {
+		event.ReceivedAt = currentTime
+	}
+	event.UpdatedAt = currentTime
+	return d.db.Save(event)
+}
+
+// UpdateInboxEvent changes an event with update and saves it, in one transaction so nothing else changes it in between.
+// If update errors, the event is left as it was.
+func (d *DB) UpdateInboxEvent(id string, update func(*model.InboxEvent) error) (*model.InboxEvent, error) {
+	r, err := d.db.Update(model.InboxEvent{}, id, func(m db.Model) (db.Model, error) {
+		event, ok := m.(model.InboxEvent)
+		if !ok {
+			return nil, fmt.Errorf("db result could not be asserted as model.InboxEvent")
+		}
+		err := update(&event)
+		if err != nil {
+			return nil, err
+		}
+		event.UpdatedAt = time.Now().UTC().Format(event.TimestampFormat())
+		return event, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	event, ok := r.(model.InboxEvent)
+	if !ok {
+		return nil, fmt.Errorf("db result could not be asserted as model.InboxEvent")
+	}
+	return &event, nil
+}
+
+func (d *DB) DeleteInboxEvent(event *model.InboxEvent) error {
+	return d.db.Delete(event, event.ID)
+}
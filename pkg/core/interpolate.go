@@ -2,6 +2,7 @@ package core
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"strings"
 	"text/template"
@@ -12,16 +13,16 @@ import (
 )
 
 // ApplyTemplate interpolates and applies a yaml to a given namespace
-func ApplyTemplate(namespace string, templateString string, data map[string]string) error {
+func ApplyTemplate(ctx context.Context, namespace string, templateString string, data map[string]string) error {
 	interpolated, err := interpolate(templateString, data)
 	if err != nil {
 		return err
 	}
-	return k8s.Apply(interpolated, namespace)
+	return k8s.Apply(ctx, interpolated, namespace)
 }
 
 // BypassReleaser is for when you're feeling frisky and want to cowboy code
-func BypassReleaser(app *model.TuberApp, imageTagWithDigest string, yamls []string, data *ClusterData) error {
+func BypassReleaser(ctx context.Context, app *model.TuberApp, imageTagWithDigest string, yamls []string, data *ClusterData) error {
 	var interpolated [][]byte
 	interplationData := releaseData(imageTagWithDigest, app, data)
 	for _, y := range yamls {
@@ -68,7 +69,7 @@ func BypassReleaser(app *model.TuberApp, imageTagWithDigest string, yamls []stri
 
 	var errors []error
 	for _, resource := range resources {
-		applyErr := k8s.Apply(resource.contents, app.Name)
+		applyErr := k8s.Apply(ctx, resource.contents, app.Name)
 		if applyErr != nil {
 			errors = append(errors, applyErr)
 			continue
@@ -111,6 +112,21 @@ type ClusterData struct {
 	AdminHost      string
 }
 
+// EnvironmentURL is where an app can be reached, from its environmentUrl var interpolated the same way its yamls are,
+// e.g. https://{{.tuberAppName}}.{{.clusterDefaultHost}}. It's empty if the var isn't set.
+func EnvironmentURL(app *model.TuberApp, clusterData *ClusterData) (string, error) {
+	data := releaseData("", app, clusterData)
+	if data["environmentUrl"] == "" {
+		return "", nil
+	}
+
+	interpolated, err := interpolate(data["environmentUrl"], data)
+	if err != nil {
+		return "", err
+	}
+	return string(interpolated), nil
+}
+
 func releaseData(digest string, app *model.TuberApp, clusterData *ClusterData) (data map[string]string) {
 	vars := map[string]string{
 		"tuberImage":            digest,
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/db"
+)
+
+// Migrations are every change to stored data, oldest first. Add new ones to the end, with the next version - never edit or reorder applied ones.
+var Migrations = []db.Migration{
+	{
+		Version:     1,
+		Description: "fill in empty app state and review apps config, and rewrite app indexes",
+		Up: func(tx *db.Tx) error {
+			apps, err := tx.All(model.TuberApp{})
+			if err != nil {
+				return err
+			}
+			for _, m := range apps {
+				app, ok := m.(model.TuberApp)
+				if !ok {
+					return fmt.Errorf("db result could not be asserted as model.TuberApp")
+				}
+				if app.State == nil {
+					app.State = &model.State{}
+				}
+				if app.ReviewAppsConfig == nil {
+					app.ReviewAppsConfig = &model.ReviewAppsConfig{}
+				}
+				err = tx.Save(app)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+}
+
+// Migrate brings the db up to date with Migrations
+func (d *DB) Migrate() ([]db.Migration, error) {
+	return d.db.Migrate(Migrations)
+}
+
+// MigrateDryRun runs the migrations Migrate would, then rolls them back
+func (d *DB) MigrateDryRun() ([]db.Migration, error) {
+	return d.db.MigrateDryRun(Migrations)
+}
+
+// Migrations lists Migrations with when each was applied
+func (d *DB) Migrations() ([]db.MigrationStatus, error) {
+	return d.db.Migrations(Migrations)
+}
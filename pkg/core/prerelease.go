@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -12,29 +13,29 @@ import (
 
 // RunPrerelease takes an array of pods, that are designed to be single use command runners
 // that have access to the new code being released.
-func RunPrerelease(logger *zap.Logger, resources []appResource, app *model.TuberApp) error {
+func RunPrerelease(ctx context.Context, logger *zap.Logger, resources []appResource, app *model.TuberApp) error {
 	for _, resource := range resources {
 		if resource.kind != "Pod" {
 			return fmt.Errorf("prerelease resources must be Pods, received %s", resource.kind)
 		}
 
-		err := k8s.Apply(resource.contents, app.Name)
+		err := k8s.Apply(ctx, resource.contents, app.Name)
 		if err != nil {
 			return err
 		}
 
-		err = WaitForPhase(resource.name, "pod", app, resource.timeout)
+		err = WaitForPhase(ctx, resource.name, "pod", app, resource.timeout)
 		if err != nil {
 			logger.Error("prerelease faled", zap.Error(err))
 			contextErr := fmt.Errorf("prerelease phase failed for pod: %s", resource.name)
-			deleteErr := k8s.Delete("pod", resource.name, app.Name)
+			deleteErr := k8s.Delete(ctx, "pod", resource.name, app.Name)
 			if deleteErr != nil {
 				return fmt.Errorf(contextErr.Error() + "\n also failed delete:" + deleteErr.Error())
 			}
 			return contextErr
 		}
 
-		err = k8s.Delete("pod", resource.name, app.Name)
+		err = k8s.Delete(ctx, "pod", resource.name, app.Name)
 		if err != nil {
 			return err
 		}
@@ -43,7 +44,7 @@ func RunPrerelease(logger *zap.Logger, resources []appResource, app *model.Tuber
 	return nil
 }
 
-func WaitForPhase(name string, kind string, app *model.TuberApp, resourceTimeout time.Duration) error {
+func WaitForPhase(ctx context.Context, name string, kind string, app *model.TuberApp, resourceTimeout time.Duration) error {
 	containerStatusesTemplate := fmt.Sprintf(
 		`go-template="%s"`,
 		"{{range .status.containerStatuses}}{{if .state.terminated.reason}}{{.state.terminated.reason}}{{end}}{{end}}",
@@ -66,7 +67,7 @@ func WaitForPhase(name string, kind string, app *model.TuberApp, resourceTimeout
 		}
 		time.Sleep(10 * time.Second)
 
-		statuses, err := k8s.Get(kind, name, app.Name, "-o", containerStatusesTemplate)
+		statuses, err := k8s.Get(ctx, kind, name, app.Name, "-o", containerStatusesTemplate)
 		if err != nil {
 			return err
 		}
@@ -76,7 +77,7 @@ func WaitForPhase(name string, kind string, app *model.TuberApp, resourceTimeout
 			break
 		}
 
-		status, err := k8s.Get(kind, name, app.Name, "-o", phaseTemplate)
+		status, err := k8s.Get(ctx, kind, name, app.Name, "-o", phaseTemplate)
 		if err != nil {
 			return err
 		}
@@ -85,7 +86,7 @@ func WaitForPhase(name string, kind string, app *model.TuberApp, resourceTimeout
 		case "Succeeded":
 			return nil
 		case "Failed":
-			message, failedRetrieval := k8s.Get(kind, name, app.Name, "-o", failureTemplate)
+			message, failedRetrieval := k8s.Get(ctx, kind, name, app.Name, "-o", failureTemplate)
 			if err != nil {
 				return failedRetrieval
 			}
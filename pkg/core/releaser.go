@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"strconv"
@@ -11,15 +12,18 @@ import (
 	"github.com/freshly/tuber/graph/model"
 	"github.com/freshly/tuber/pkg/gcr"
 	"github.com/freshly/tuber/pkg/k8s"
+	"github.com/freshly/tuber/pkg/metrics"
 	"github.com/freshly/tuber/pkg/monitor"
+	"github.com/freshly/tuber/pkg/notify"
 	"github.com/freshly/tuber/pkg/report"
-	"github.com/freshly/tuber/pkg/slack"
+	"github.com/freshly/tuber/pkg/tracing"
 
 	"github.com/goccy/go-yaml"
 	"go.uber.org/zap"
 )
 
 type releaser struct {
+	ctx               context.Context
 	logger            *zap.Logger
 	errorScope        report.Scope
 	app               *model.TuberApp
@@ -29,9 +33,9 @@ type releaser struct {
 	prereleaseYamls   []string
 	postreleaseYamls  []string
 	tags              []string
+	revision          string
 	db                *DB
-	slackClient       *slack.Client
-	diffText          string
+	notifications     *notify.Release
 	sentryBearerToken string
 }
 
@@ -78,27 +82,48 @@ func (r releaser) releaseError(err error) error {
 
 // Release interpolates and applies an app's resources. It removes deleted resources, and rolls back on any release failure.
 // If you edit a resource manually, and a release fails, tuber will roll back to the previously released state of the object, not to the state you manually specified.
-func Release(db *DB, yamls *gcr.AppYamls, logger *zap.Logger, errorScope report.Scope, app *model.TuberApp, digest string, data *ClusterData, slackClient *slack.Client, diffText string, sentryBearerToken string) error {
+func Release(ctx context.Context, db *DB, yamls *gcr.AppYamls, logger *zap.Logger, errorScope report.Scope, app *model.TuberApp, digest string, data *ClusterData, notifications *notify.Release, sentryBearerToken string) error {
 	return releaser{
+		ctx:               ctx,
 		logger:            logger,
 		errorScope:        errorScope,
 		releaseYamls:      yamls.Release,
 		prereleaseYamls:   yamls.Prerelease,
 		postreleaseYamls:  yamls.PostRelease,
 		tags:              yamls.Tags,
+		revision:          yamls.Revision,
 		app:               app,
 		digest:            digest,
 		data:              data,
 		db:                db,
-		slackClient:       slackClient,
-		diffText:          diffText,
+		notifications:     notifications,
 		sentryBearerToken: sentryBearerToken,
 	}.release()
 }
 
+// rolledBack lets the release's followers know it's been rolled back to the previously released state
+func (r releaser) rolledBack() {
+	metrics.Rollbacks.WithLabelValues(r.app.Name).Inc()
+	r.notifications.Notify(r.logger, notify.Event{Type: notify.ReleaseRolledBack, Message: "rolled back to the previous release"})
+}
+
+// phase starts timing a release phase, and makes it the parent span of the phase's kubectl calls until the returned func ends it
+func (r *releaser) phase(name string) func(error) {
+	parent := r.ctx
+	ctx, span := tracing.Start(parent, "release."+name, tracing.App(r.app.Name), tracing.Digest(r.digest))
+	r.ctx = ctx
+	start := time.Now()
+	r.notifications.Progress(notify.Event{Type: notify.ReleasePhaseStarted, Phase: name, Message: name + " starting"})
+	return func(err error) {
+		metrics.Phase(name, start)
+		tracing.End(span, err)
+		r.ctx = parent
+	}
+}
+
 func (r releaser) release() error {
 	r.logger.Debug("releaser starting")
-	r.slackClient.Message(r.logger, ":game_die: *"+r.app.Name+"*: release starting"+r.diffText, r.app.SlackChannel)
+	r.notifications.Notify(r.logger, notify.Event{Type: notify.ReleaseStarted, Message: "release starting"})
 
 	rr, err := r.resourcesToApply()
 	if err != nil {
@@ -116,22 +141,29 @@ func (r releaser) release() error {
 	if len(rr.Prerelease) > 0 {
 		r.logger.Debug("prerelease starting")
 
-		err = RunPrerelease(r.logger, r.applyCurrentReplicasToCollection(rr.Prerelease, crtg), r.app)
+		endPhase := r.phase(metrics.PhasePrerelease)
+		err = RunPrerelease(r.ctx, r.logger, r.applyCurrentReplicasToCollection(rr.Prerelease, crtg), r.app)
+		endPhase(err)
 		if err != nil {
 			return ErrorContext{context: "prerelease", err: err}
 		}
 
 		r.logger.Debug("prerelease complete")
+		r.notifications.Notify(r.logger, notify.Event{Type: notify.ReleasePrereleaseFinished, Message: "prerelease finished"})
 	}
 
+	endPhase := r.phase(metrics.PhaseApply)
 	appliedConfigs, err := r.apply(rr.Configs)
 	if err != nil {
+		endPhase(err)
 		_ = r.releaseError(err)
 		r.rollback(appliedConfigs, decodedStateBeforeApply)
+		r.rolledBack()
 		return err
 	}
 
 	appliedWorkloads, err := r.apply(r.applyCurrentReplicasToCollection(rr.Workloads, crtg))
+	endPhase(err)
 	if err != nil {
 		_ = r.releaseError(err)
 		_, configRollbackErrors := r.rollback(appliedConfigs, decodedStateBeforeApply)
@@ -143,19 +175,28 @@ func (r releaser) release() error {
 		for _, watchError := range watchErrors {
 			_ = r.releaseError(watchError)
 		}
+		r.rolledBack()
 		return err
 	}
 
 	if len(rr.Postrelease) != 0 {
-		r.slackClient.Message(r.logger, ":bird: *"+r.app.Name+"*: canary rollout starting"+r.diffText, r.app.SlackChannel)
+		r.notifications.Notify(r.logger, notify.Event{Type: notify.ReleaseCanaryStarted, Message: "canary rollout starting"})
 	}
 
+	endPhase = r.phase(metrics.PhaseRollout)
 	rolloutErr, err := r.watchWorkloads(appliedWorkloads)
+	endPhase(err)
 	if err != nil {
 		if !rolloutErr.monitorFail {
 			_ = r.releaseError(err)
 		} else {
-			r.slackClient.Message(r.logger, "<!here> :loudspeaker: *"+r.app.Name+"*: monitoring failed for "+strings.ToLower(rolloutErr.resource.kind)+" "+rolloutErr.resource.name+" - "+rolloutErr.monitorFailMessage, r.app.SlackChannel)
+			metrics.MonitorFailures.WithLabelValues(r.app.Name).Inc()
+			r.notifications.Notify(r.logger, notify.Event{
+				Type:     notify.ReleaseMonitorFailed,
+				Severity: notify.SeverityError,
+				Message:  "monitoring failed for " + strings.ToLower(rolloutErr.resource.kind) + " " + rolloutErr.resource.name + " - " + rolloutErr.monitorFailMessage,
+				Actions:  []string{notify.ActionResume, notify.ActionRetry},
+			})
 			r.app.Paused = true
 			saveErr := r.db.SaveApp(r.app)
 			if saveErr != nil {
@@ -171,15 +212,21 @@ func (r releaser) release() error {
 		for _, watchError := range watchErrors {
 			_ = r.releaseError(watchError)
 		}
+		r.rolledBack()
 		return err
 	}
 
 	if len(rr.Postrelease) != 0 {
-		r.slackClient.Message(r.logger, ":bird: *"+r.app.Name+"*: deployed to canary"+r.diffText, r.app.SlackChannel)
+		r.notifications.Notify(r.logger, notify.Event{Type: notify.ReleaseCanaryDeployed, Message: "deployed to canary"})
 	}
 
+	endPhase = func(error) {}
+	if len(rr.Postrelease) != 0 {
+		endPhase = r.phase(metrics.PhasePostrelease)
+	}
 	appliedPostreleaseResources, err := r.apply(r.applyCurrentReplicasToCollection(rr.Postrelease, crtg))
 	if err != nil {
+		endPhase(err)
 		_ = r.releaseError(err)
 		_, configRollbackErrors := r.rollback(appliedConfigs, decodedStateBeforeApply)
 		rolledBackResources, workloadRollbackErrors := r.rollback(appliedWorkloads, decodedStateBeforeApply)
@@ -190,15 +237,23 @@ func (r releaser) release() error {
 		for _, watchError := range watchErrors {
 			_ = r.releaseError(watchError)
 		}
+		r.rolledBack()
 		return err
 	}
 
 	rolloutErr, err = r.watchWorkloads(appliedPostreleaseResources)
+	endPhase(err)
 	if err != nil {
 		if !rolloutErr.monitorFail {
 			_ = r.releaseError(err)
 		} else {
-			r.slackClient.Message(r.logger, "<!here> :loudspeaker: *"+r.app.Name+"*: monitoring failed for "+rolloutErr.resource.kind+" "+rolloutErr.resource.name+"+"+rolloutErr.monitorFailMessage, r.app.SlackChannel)
+			metrics.MonitorFailures.WithLabelValues(r.app.Name).Inc()
+			r.notifications.Notify(r.logger, notify.Event{
+				Type:     notify.ReleaseMonitorFailed,
+				Severity: notify.SeverityError,
+				Message:  "monitoring failed for " + strings.ToLower(rolloutErr.resource.kind) + " " + rolloutErr.resource.name + " - " + rolloutErr.monitorFailMessage,
+				Actions:  []string{notify.ActionResume, notify.ActionRetry},
+			})
 			r.app.Paused = true
 			saveErr := r.db.SaveApp(r.app)
 			if saveErr != nil {
@@ -215,6 +270,7 @@ func (r releaser) release() error {
 		for _, watchError := range watchErrors {
 			_ = r.releaseError(watchError)
 		}
+		r.rolledBack()
 		return err
 	}
 
@@ -223,12 +279,12 @@ func (r releaser) release() error {
 
 	cleanupErr := r.deleteRemovedResources(decodedStateBeforeApply, appliedResources)
 	if cleanupErr != nil {
-		r.slackClient.Message(r.logger, "<!here> :confused: *"+r.app.Name+"*: Release is complete, but deletion of a resource removed with this release failed."+r.diffText, r.app.SlackChannel)
+		r.notifications.Notify(r.logger, notify.Event{Type: notify.ReleaseWarning, Severity: notify.SeverityError, Message: "Release is complete, but deletion of a resource removed with this release failed."})
 	}
 
 	saveStateErr := r.updateState(appliedResources)
 	if saveStateErr != nil {
-		r.slackClient.Message(r.logger, "<!here> :skull_and_crossbones: *"+r.app.Name+"*: Release is complete, but the current and previous states *failed to update*.\nRolling back from this release is therefore NOT necessarily safe. Please contact devops."+r.diffText, r.app.SlackChannel)
+		r.notifications.Notify(r.logger, notify.Event{Type: notify.ReleaseWarning, Severity: notify.SeverityError, Message: "Release is complete, but the current and previous states failed to update.", Detail: "Rolling back from this release is therefore NOT necessarily safe. Please contact devops."})
 	}
 	if cleanupErr != nil {
 		return r.releaseError(cleanupErr)
@@ -264,6 +320,11 @@ func (a appResource) supportsRollback() bool {
 	return a.kind == "Deployment" || a.kind == "Daemonset" || a.kind == "StatefulSet"
 }
 
+// displayName is how kubectl refers to the resource, e.g. deployment/potatoes
+func (a appResource) displayName() string {
+	return strings.ToLower(a.kind) + "/" + a.name
+}
+
 func (a appResource) canBeManaged() bool {
 	return a.kind != "Secret" && a.kind != "ClusterRole" && a.kind != "ClusterRoleBinding"
 }
@@ -366,7 +427,7 @@ func (r releaser) applyCurrentReplicas(contents []byte, hpaName string) []byte {
 		return nil
 	}
 
-	out, err := k8s.Get("hpa", hpaName, r.app.Name, `-o=go-template="{{.status.desiredReplicas}}"`)
+	out, err := k8s.Get(r.ctx, "hpa", hpaName, r.app.Name, `-o=go-template="{{.status.desiredReplicas}}"`)
 	if err != nil {
 		r.logger.Warn(fmt.Sprintf("error getting hpa applyCurrentReplicas: %v", err))
 		return nil
@@ -543,7 +604,7 @@ func (r releaser) apply(resources []appResource) ([]appResource, error) {
 	var applied []appResource
 	for _, resource := range resources {
 		scope, logger := resource.scopes(r)
-		err := k8s.Apply(resource.contents, r.app.Name)
+		err := k8s.Apply(r.ctx, resource.contents, r.app.Name)
 		if err != nil {
 			return applied, ErrorContext{err: err, scope: scope, logger: logger, context: "apply"}
 		}
@@ -628,14 +689,14 @@ func (r releaser) goWatch(resource appResource, timeout time.Duration, errors ch
 	}
 
 	if !resource.hasMonitoring() {
-		err := k8s.RolloutStatus(resource.kind, resource.name, r.app.Name, timeout)
+		err := r.rolloutStatus(resource, timeout)
 		if err != nil {
 			errors <- rolloutError{err: err, resource: resource}
 		}
 	} else {
 		wg.Add(1)
 		go func(errors chan rolloutError, wg *sync.WaitGroup) {
-			err := k8s.RolloutStatus(resource.kind, resource.name, r.app.Name, timeout)
+			err := r.rolloutStatus(resource, timeout)
 			if err != nil {
 				errors <- rolloutError{err: err, resource: resource}
 			}
@@ -646,7 +707,7 @@ func (r releaser) goWatch(resource appResource, timeout time.Duration, errors ch
 			wg.Add(1)
 			go func(url string, errors chan rolloutError, wg *sync.WaitGroup) {
 				_, logger := resource.scopes(r)
-				healthy, message := monitor.Sentry(logger, url, r.sentryBearerToken, resource.watchDuration)
+				healthy, message := monitor.Sentry(r.ctx, logger, url, r.sentryBearerToken, resource.watchDuration)
 				if !healthy {
 					errors <- rolloutError{
 						err:                fmt.Errorf("sentry monitoring found failure"),
@@ -654,6 +715,8 @@ func (r releaser) goWatch(resource appResource, timeout time.Duration, errors ch
 						monitorFail:        true,
 						monitorFailMessage: message,
 					}
+				} else {
+					r.notifications.Progress(notify.Event{Type: notify.MonitorPassed, Resource: resource.displayName(), Message: "monitoring passed for " + resource.displayName()})
 				}
 				wg.Done()
 			}(url, errors, wg)
@@ -666,12 +729,42 @@ func (r releaser) goWatchRollback(resource appResource, timeout time.Duration, e
 	if !resource.supportsRollback() {
 		return
 	}
-	err := k8s.RolloutStatus(resource.kind, resource.name, r.app.Name, timeout)
+	err := r.rolloutStatus(resource, timeout)
 	if err != nil {
 		errors <- rolloutError{err: err, resource: resource}
 	}
 }
 
+// rolloutStatus waits for a workload to roll out, letting anyone watching the app follow along
+func (r releaser) rolloutStatus(resource appResource, timeout time.Duration) error {
+	name := resource.displayName()
+	r.notifications.Progress(notify.Event{Type: notify.ResourceRollingOut, Resource: name, Message: name + " rolling out"})
+	err := k8s.RolloutStatus(r.ctx, resource.kind, resource.name, r.app.Name, timeout)
+	if err != nil {
+		r.notifications.Progress(notify.Event{Type: notify.ResourceRolloutFailed, Severity: notify.SeverityError, Resource: name, Message: name + " failed to roll out", Detail: err.Error()})
+		return err
+	}
+	r.notifications.Progress(notify.Event{Type: notify.ResourceRolledOut, Resource: name, Message: name + " rolled out"})
+	return nil
+}
+
+// WatchRollout waits for an app's workloads to roll out outside of a release, like after a manual rollback, reporting each one's progress
+func WatchRollout(ctx context.Context, logger *zap.Logger, app *model.TuberApp, resources []*model.Resource, notifications *notify.Release) []error {
+	r := releaser{
+		ctx:           ctx,
+		logger:        logger,
+		errorScope:    report.Scope{"appName": app.Name},
+		app:           app,
+		notifications: notifications,
+	}
+
+	var workloads []appResource
+	for _, resource := range resources {
+		workloads = append(workloads, appResource{kind: resource.Kind, name: resource.Name})
+	}
+	return r.watchRollback(workloads)
+}
+
 func (r releaser) rollback(appliedResources []appResource, cachedResources []appResource) ([]appResource, []error) {
 	var rolledBack []appResource
 	var errors []error
@@ -694,7 +787,7 @@ func (r releaser) rollback(appliedResources []appResource, cachedResources []app
 		}
 
 		if !inPreviousState && !emptyState {
-			err := k8s.Delete(applied.kind, applied.name, r.app.Name)
+			err := k8s.Delete(r.ctx, applied.kind, applied.name, r.app.Name)
 			if err != nil {
 				errors = append(errors, r.releaseError(ErrorContext{err: err, context: "deleting newly created resource on error", scope: scope, logger: logger}))
 			}
@@ -706,9 +799,9 @@ func (r releaser) rollback(appliedResources []appResource, cachedResources []app
 func (r releaser) rollbackResource(applied appResource, cached appResource) error {
 	var err error
 	if applied.supportsRollback() {
-		err = k8s.RolloutUndo(applied.kind, applied.name, r.app.Name)
+		err = k8s.RolloutUndo(r.ctx, applied.kind, applied.name, r.app.Name)
 	} else {
-		err = k8s.Apply(cached.contents, r.app.Name)
+		err = k8s.Apply(r.ctx, cached.contents, r.app.Name)
 	}
 
 	if err != nil {
@@ -734,7 +827,7 @@ func (r releaser) deleteRemovedResources(stateBeforeApply []appResource, applied
 		}
 		if !cachedWasPartOfRelease {
 			scope, logger := cached.scopes(r)
-			out, err := k8s.Get(cached.kind, cached.name, r.app.Name, "-o", "yaml")
+			out, err := k8s.Get(r.ctx, cached.kind, cached.name, r.app.Name, "-o", "yaml")
 			if _, notFound := err.(k8s.NotFoundError); notFound {
 				continue
 			}
@@ -749,7 +842,7 @@ func (r releaser) deleteRemovedResources(stateBeforeApply []appResource, applied
 			}
 
 			if parsed.Metadata.OwnerReferences == nil {
-				deleteErr := k8s.Delete(cached.kind, cached.name, r.app.Name)
+				deleteErr := k8s.Delete(r.ctx, cached.kind, cached.name, r.app.Name)
 				if deleteErr != nil {
 					return ErrorContext{err: deleteErr, context: "delete resource removed from state", scope: scope, logger: logger}
 				}
@@ -768,6 +861,7 @@ func (r releaser) updateState(appliedResources appResources) error {
 	latest.State.Previous = latest.State.Current
 	latest.State.Current = appliedResources.encode()
 	latest.CurrentTags = r.tags
+	latest.CurrentRevision = r.revision
 
 	err = r.db.SaveApp(latest)
 	if err != nil {
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"fmt"
 
 	yamls "github.com/freshly/tuber/data/tuberapps"
@@ -9,8 +10,8 @@ import (
 )
 
 // DestroyTuberApp deletes all resources for the given app on the current cluster
-func DestroyTuberApp(db *DB, app *model.TuberApp) error {
-	if err := k8s.Delete("namespace", app.Name, app.Name); err != nil {
+func DestroyTuberApp(ctx context.Context, db *DB, app *model.TuberApp) error {
+	if err := k8s.Delete(ctx, "namespace", app.Name, app.Name); err != nil {
 		return fmt.Errorf("k8s.Delete failed: %v", err)
 	}
 
@@ -23,7 +24,7 @@ func DestroyTuberApp(db *DB, app *model.TuberApp) error {
 
 // NewAppSetup adds a new tuber app configuration, including namespace,
 // role, rolebinding, and a listing in tuber-apps
-func NewAppSetup(appName string, istio bool) error {
+func NewAppSetup(ctx context.Context, appName string, istio bool) error {
 	var err error
 	var istioEnabled string
 	if istio {
@@ -38,19 +39,19 @@ func NewAppSetup(appName string, istio bool) error {
 	}
 
 	for _, yaml := range []yamls.TuberYaml{yamls.Namespace, yamls.Role, yamls.Rolebinding} {
-		err = ApplyTemplate(appName, string(yaml.Contents), data)
+		err = ApplyTemplate(ctx, appName, string(yaml.Contents), data)
 		if err != nil {
 			return err
 		}
 	}
 
-	existsAlready, err := k8s.Exists("secret", appName+"-env", appName)
+	existsAlready, err := k8s.Exists(ctx, "secret", appName+"-env", appName)
 	if err != nil {
 		return err
 	}
 
 	if !existsAlready {
-		err = k8s.CreateEnv(appName)
+		err = k8s.CreateEnv(ctx, appName)
 	}
 
 	if err != nil {
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/db"
+)
+
+// webhookDeliveriesKept is how many of an app's most recent deliveries are kept in the delivery log
+const webhookDeliveriesKept = 100
+
+// WebhookDeliveries returns an app's delivery log, most recent first
+func (d *DB) WebhookDeliveries(appName string) ([]*model.WebhookDelivery, error) {
+	r, err := d.db.Get(model.WebhookDelivery{}, db.Q().String("appName", appName))
+	if err != nil {
+		return nil, err
+	}
+
+	var deliveries []*model.WebhookDelivery
+	for _, m := range r {
+		delivery, ok := m.(model.WebhookDelivery)
+		if !ok {
+			return nil, fmt.Errorf("db result could not be asserted as model.WebhookDelivery")
+		}
+		deliveries = append(deliveries, &delivery)
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt > deliveries[j].CreatedAt
+	})
+	return deliveries, nil
+}
+
+// SaveWebhookDelivery saves a delivery
+func (d *DB) SaveWebhookDelivery(delivery *model.WebhookDelivery) error {
+	return d.db.Save(delivery)
+}
+
+// TrimWebhookDeliveries trims an app's delivery log to the most recent deliveries
+func (d *DB) TrimWebhookDeliveries(appName string) error {
+	deliveries, err := d.WebhookDeliveries(appName)
+	if err != nil {
+		return err
+	}
+	for i := webhookDeliveriesKept; i < len(deliveries); i++ {
+		err = d.db.Delete(deliveries[i], deliveries[i].ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package db
+
+import (
+	"fmt"
+	"io"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Backup writes a consistent snapshot of the db from a read transaction, so writes carry on while it's taken.
+// The snapshot is a bolt file of its own.
+func (d *DB) Backup(w io.Writer) (int64, error) {
+	var written int64
+	err := d.db.View(func(tx *bolt.Tx) error {
+		var err error
+		written, err = tx.WriteTo(w)
+		return err
+	})
+	return written, err
+}
+
+// Restore replaces everything in the db with the contents of a backup file, then migrates it up to date, in a single transaction.
+// Roots the backup doesn't have are left empty rather than removed, and kept roots are left as they are rather than restored.
+// Backups from a newer tuber, with migrations this one doesn't know, are refused. It returns the migrations applied to the restored data.
+func (d *DB) Restore(backupPath string, migrations []Migration, keep ...string) ([]Migration, error) {
+	err := validateMigrations(migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	backup, err := bolt.Open(backupPath, DefaultFilemode, &bolt.Options{ReadOnly: true, Timeout: DefaultStartupTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("backup isn't a readable database: %v", err)
+	}
+	defer backup.Close()
+
+	kept := map[string]bool{}
+	for _, root := range keep {
+		kept[root] = true
+	}
+
+	var applied []Migration
+	err = backup.View(func(backupTx *bolt.Tx) error {
+		return d.db.Update(func(tx *bolt.Tx) error {
+			var roots [][]byte
+			err := tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+				if !kept[string(name)] {
+					roots = append(roots, append([]byte{}, name...))
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			for _, root := range roots {
+				err = tx.DeleteBucket(root)
+				if err != nil {
+					return err
+				}
+				_, err = tx.CreateBucket(root)
+				if err != nil {
+					return err
+				}
+			}
+
+			err = backupTx.ForEach(func(name []byte, b *bolt.Bucket) error {
+				if kept[string(name)] {
+					return nil
+				}
+				root, err := tx.CreateBucketIfNotExists(name)
+				if err != nil {
+					return err
+				}
+				return copyBucket(root, b)
+			})
+			if err != nil {
+				return err
+			}
+
+			applied, err = migrateTx(tx, migrations)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
+
+// copyBucket copies every key and nested bucket of src into dst
+func copyBucket(dst *bolt.Bucket, src *bolt.Bucket) error {
+	return src.ForEach(func(k, v []byte) error {
+		if v != nil {
+			return dst.Put(k, v)
+		}
+
+		nested, err := dst.CreateBucketIfNotExists(k)
+		if err != nil {
+			return err
+		}
+		return copyBucket(nested, src.Bucket(k))
+	})
+}
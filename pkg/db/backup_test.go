@@ -0,0 +1,81 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupRestore(t *testing.T) {
+	dir := t.TempDir()
+	db, err := NewDefaultDB(filepath.Join(dir, "db"), testModel{}.DBRoot(), "tomatoes")
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.Save(testModel{Name: "russet", Color: "brown"}))
+
+	migrations := []Migration{{Version: 1, Description: "nothing", Up: func(tx *Tx) error { return nil }}}
+
+	backupPath := filepath.Join(dir, "backup.db")
+	file, err := os.Create(backupPath)
+	require.NoError(t, err)
+	written, err := db.Backup(file)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	assert.NotZero(t, written)
+
+	require.NoError(t, db.Save(testModel{Name: "yukon", Color: "gold"}))
+	require.NoError(t, db.Delete(testModel{}, "russet"))
+
+	applied, err := db.Restore(backupPath, migrations)
+	require.NoError(t, err)
+	assert.Len(t, applied, 1, "restored data is migrated")
+	assert.True(t, db.Exists(testModel{}, "russet"))
+	assert.False(t, db.Exists(testModel{}, "yukon"), "restores replace everything")
+	found, err := db.Get(testModel{}, Q().String("color", "brown"))
+	require.NoError(t, err)
+	assert.Len(t, found, 1, "indexes come along")
+
+	_, err = db.Restore(backupPath, migrations[:0])
+	assert.NoError(t, err, "the backup predates the migration")
+	_, err = db.Migrate(migrations)
+	require.NoError(t, err)
+	newer := filepath.Join(dir, "newer.db")
+	file, err = os.Create(newer)
+	require.NoError(t, err)
+	_, err = db.Backup(file)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	_, err = db.Restore(newer, migrations[:0])
+	assert.Error(t, err, "backups from a newer tuber are refused")
+	assert.True(t, db.Exists(testModel{}, "russet"), "refused restores change nothing")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "junk.db"), []byte("potatoes"), 0600))
+	_, err = db.Restore(filepath.Join(dir, "junk.db"), migrations)
+	assert.Error(t, err)
+}
+
+func TestRestoreKeepsRoots(t *testing.T) {
+	dir := t.TempDir()
+	db, err := NewDefaultDB(filepath.Join(dir, "db"), testModel{}.DBRoot())
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.Save(testModel{Name: "russet", Color: "brown"}))
+
+	backupPath := filepath.Join(dir, "backup.db")
+	file, err := os.Create(backupPath)
+	require.NoError(t, err)
+	_, err = db.Backup(file)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	require.NoError(t, db.Delete(testModel{}, "russet"))
+	require.NoError(t, db.Save(testModel{Name: "yukon", Color: "gold"}))
+
+	_, err = db.Restore(backupPath, nil, testModel{}.DBRoot())
+	require.NoError(t, err)
+	assert.False(t, db.Exists(testModel{}, "russet"), "kept roots aren't restored")
+	assert.True(t, db.Exists(testModel{}, "yukon"), "kept roots aren't cleared")
+}
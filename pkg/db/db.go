@@ -57,6 +57,23 @@ func NewDB(path string, filemode os.FileMode, boltOptions *bolt.Options, startup
 	return &DB{db: db}, nil
 }
 
+// NewReadOnlyDB opens an existing db file without writing to it, failing if there's no file at path rather than creating one
+func NewReadOnlyDB(path string) (*DB, error) {
+	_, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(path, DefaultFilemode, &bolt.Options{ReadOnly: true, Timeout: DefaultStartupTimeout})
+	if err == bolt.ErrTimeout {
+		return nil, fmt.Errorf("timeout opening database - check for other running processes that access the file")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &DB{db: db}, nil
+}
+
 func (d *DB) Close() {
 	d.db.Close()
 }
@@ -72,13 +89,62 @@ type Model interface {
 }
 
 func (d *DB) Save(m Model) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		return save(tx, m)
+	})
+}
+
+// Insert saves a model only if nothing is saved under its key yet, checking and saving in one transaction.
+// It returns whether the model was saved.
+func (d *DB) Insert(m Model) (bool, error) {
+	var inserted bool
+	err := d.db.Update(func(tx *bolt.Tx) error {
+		rootb := tx.Bucket([]byte(m.DBRoot()))
+		if rootb != nil && rootb.Bucket([]byte(m.DBKey())) != nil {
+			return nil
+		}
+		inserted = true
+		return save(tx, m)
+	})
+	return inserted && err == nil, err
+}
+
+// Update finds a model and saves what update makes of it in one transaction, so nothing else can change it in between.
+// If update errors, nothing is saved and its error is returned.
+func (d *DB) Update(m Model, key string, update func(Model) (Model, error)) (Model, error) {
+	var updated Model
+	err := d.db.Update(func(tx *bolt.Tx) error {
+		root := m.DBRoot()
+		rootb := tx.Bucket([]byte(root))
+		rootbentryb := rootb.Bucket([]byte(key))
+		if rootbentryb == nil {
+			return NotFoundError{err: fmt.Errorf("key %s not found in %s/", key, root)}
+		}
+
+		found, err := m.DBUnmarshal(rootbentryb.Get([]byte(marshalledKey)))
+		if err != nil {
+			return fmt.Errorf("unmarshal failed for %s/%s/: %v", root, key, err)
+		}
+
+		updated, err = update(found)
+		if err != nil {
+			return err
+		}
+		return save(tx, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func save(tx *bolt.Tx, m Model) error {
 	key := m.DBKey()
 	if key == "" {
 		return fmt.Errorf("save failed, model key nil")
 	}
 
 	strings, bools, ints := m.DBIndexes()
-	var err error
 
 	for k, v := range bools {
 		if strings[k] != "" {
@@ -99,27 +165,20 @@ func (d *DB) Save(m Model) error {
 		return err
 	}
 
-	err = d.db.Update(func(tx *bolt.Tx) error {
-		rootb := tx.Bucket([]byte(m.DBRoot()))
-		mb, err := rootb.CreateBucketIfNotExists([]byte(m.DBKey()))
-		if err != nil {
-			return err
-		}
-		err = mb.Put([]byte(marshalledKey), marshalled)
+	rootb := tx.Bucket([]byte(m.DBRoot()))
+	mb, err := rootb.CreateBucketIfNotExists([]byte(m.DBKey()))
+	if err != nil {
+		return err
+	}
+	err = mb.Put([]byte(marshalledKey), marshalled)
+	if err != nil {
+		return err
+	}
+	for k, v := range strings {
+		err = mb.Put([]byte(k), []byte(v))
 		if err != nil {
 			return err
 		}
-		for k, v := range strings {
-			err = mb.Put([]byte(k), []byte(v))
-			if err != nil {
-				return err
-			}
-		}
-
-		return nil
-	})
-	if err != nil {
-		return err
 	}
 	return nil
 }
@@ -238,6 +297,39 @@ func (d *DB) Find(m Model, key string) (Model, error) {
 	return model, nil
 }
 
+// Take finds a model and deletes it in one transaction, so when several callers take the same model only one gets it.
+// If check is set and errors, the model is left alone and check's error is returned.
+func (d *DB) Take(m Model, key string, check func(Model) error) (Model, error) {
+	var model Model
+	err := d.db.Update(func(tx *bolt.Tx) error {
+		root := m.DBRoot()
+		rootb := tx.Bucket([]byte(root))
+		rootbentryb := rootb.Bucket([]byte(key))
+		if rootbentryb == nil {
+			return NotFoundError{err: fmt.Errorf("key %s not found in %s/", key, root)}
+		}
+
+		var err error
+		model, err = m.DBUnmarshal(rootbentryb.Get([]byte(marshalledKey)))
+		if err != nil {
+			return fmt.Errorf("unmarshal failed for %s/%s/: %v", root, key, err)
+		}
+
+		if check != nil {
+			err = check(model)
+			if err != nil {
+				return err
+			}
+		}
+		return rootb.DeleteBucket([]byte(key))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
 func (d *DB) Exists(m Model, key string) bool {
 	var found bool
 	d.db.View(func(tx *bolt.Tx) error {
@@ -0,0 +1,209 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// metadataRoot holds the db's own bookkeeping - the migration it's at, and when each migration was applied
+const metadataRoot = "metadata"
+
+const versionKey = "version"
+const appliedKeyPrefix = "applied/"
+
+// errDryRun rolls back a dry run's transaction once its migrations have run
+var errDryRun = errors.New("dry run")
+
+// Migration changes stored data to match a model change. Versions start at 1 and count up by one, in the order migrations run.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(tx *Tx) error
+}
+
+// MigrationStatus is a migration, and when it was applied - empty if it's pending
+type MigrationStatus struct {
+	Migration
+	AppliedAt string
+}
+
+// Tx is the transaction migrations run in
+type Tx struct {
+	tx *bolt.Tx
+}
+
+// Bolt is the underlying bolt transaction, for anything the helpers don't cover
+func (t *Tx) Bolt() *bolt.Tx {
+	return t.tx
+}
+
+// CreateRoot creates a root bucket, if it doesn't exist yet
+func (t *Tx) CreateRoot(root string) error {
+	_, err := t.tx.CreateBucketIfNotExists([]byte(root))
+	return err
+}
+
+// All is every model stored in a model's root
+func (t *Tx) All(m Model) ([]Model, error) {
+	rootb := t.tx.Bucket([]byte(m.DBRoot()))
+	if rootb == nil {
+		return nil, nil
+	}
+
+	var results []Model
+	err := rootb.ForEach(func(k, v []byte) error {
+		rootbentryb := rootb.Bucket(k)
+		if rootbentryb == nil {
+			return nil
+		}
+		result, err := m.DBUnmarshal(rootbentryb.Get([]byte(marshalledKey)))
+		if err != nil {
+			return fmt.Errorf("unmarshal failed for %s/%s/: %v", m.DBRoot(), k, err)
+		}
+		results = append(results, result)
+		return nil
+	})
+	return results, err
+}
+
+// Save saves a model, rewriting its indexes
+func (t *Tx) Save(m Model) error {
+	return save(t.tx, m)
+}
+
+// Delete deletes a model
+func (t *Tx) Delete(m Model, key string) error {
+	return t.tx.Bucket([]byte(m.DBRoot())).DeleteBucket([]byte(key))
+}
+
+// Version is the last migration applied to the db, 0 if none have been
+func (d *DB) Version() (int, error) {
+	var version int
+	err := d.db.View(func(tx *bolt.Tx) error {
+		var err error
+		version, err = readVersion(tx)
+		return err
+	})
+	return version, err
+}
+
+// Migrations lists migrations with when each was applied
+func (d *DB) Migrations(migrations []Migration) ([]MigrationStatus, error) {
+	err := validateMigrations(migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []MigrationStatus
+	err = d.db.View(func(tx *bolt.Tx) error {
+		metadata := tx.Bucket([]byte(metadataRoot))
+		for _, migration := range migrations {
+			status := MigrationStatus{Migration: migration}
+			if metadata != nil {
+				status.AppliedAt = string(metadata.Get([]byte(appliedKeyPrefix + strconv.Itoa(migration.Version))))
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+// Migrate applies every migration newer than the db, in order, in a single transaction - so a failed migration leaves the db as it was.
+// It refuses to touch a db migrated past the newest migration it knows, since this binary doesn't understand that data.
+// It returns the migrations it applied.
+func (d *DB) Migrate(migrations []Migration) ([]Migration, error) {
+	return d.migrate(migrations, false)
+}
+
+// MigrateDryRun runs the migrations Migrate would, then rolls them back
+func (d *DB) MigrateDryRun(migrations []Migration) ([]Migration, error) {
+	return d.migrate(migrations, true)
+}
+
+func (d *DB) migrate(migrations []Migration, dryRun bool) ([]Migration, error) {
+	err := validateMigrations(migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	err = d.db.Update(func(tx *bolt.Tx) error {
+		var err error
+		applied, err = migrateTx(tx, migrations)
+		if err != nil {
+			return err
+		}
+
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && err != errDryRun {
+		return nil, err
+	}
+	return applied, nil
+}
+
+func migrateTx(tx *bolt.Tx, migrations []Migration) ([]Migration, error) {
+	version, err := readVersion(tx)
+	if err != nil {
+		return nil, err
+	}
+	if version > len(migrations) {
+		return nil, fmt.Errorf("database is at migration %d, newer than this tuber's latest migration %d - run a newer tuber, or restore a backup from before the upgrade", version, len(migrations))
+	}
+
+	metadata, err := tx.CreateBucketIfNotExists([]byte(metadataRoot))
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, migration := range migrations[version:] {
+		err = migration.Up(&Tx{tx: tx})
+		if err != nil {
+			return nil, fmt.Errorf("migration %d (%s) failed: %v", migration.Version, migration.Description, err)
+		}
+		err = metadata.Put([]byte(appliedKeyPrefix+strconv.Itoa(migration.Version)), []byte(time.Now().UTC().Format(time.RFC3339)))
+		if err != nil {
+			return nil, err
+		}
+		applied = append(applied, migration)
+	}
+
+	err = metadata.Put([]byte(versionKey), []byte(strconv.Itoa(len(migrations))))
+	if err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
+
+func readVersion(tx *bolt.Tx) (int, error) {
+	metadata := tx.Bucket([]byte(metadataRoot))
+	if metadata == nil {
+		return 0, nil
+	}
+	version := metadata.Get([]byte(versionKey))
+	if version == nil {
+		return 0, nil
+	}
+	return strconv.Atoi(string(version))
+}
+
+func validateMigrations(migrations []Migration) error {
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return fmt.Errorf("migration %q is version %d, but it's number %d in the list - versions count up from 1", migration.Description, migration.Version, i+1)
+		}
+		if migration.Up == nil {
+			return fmt.Errorf("migration %d has nothing to run", migration.Version)
+		}
+	}
+	return nil
+}
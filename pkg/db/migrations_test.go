@@ -0,0 +1,123 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testModel struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+func (m testModel) DBIndexes() (map[string]string, map[string]bool, map[string]int) {
+	return map[string]string{"color": m.Color}, map[string]bool{}, map[string]int{}
+}
+
+func (m testModel) DBRoot() string {
+	return "potatoes"
+}
+
+func (m testModel) DBKey() string {
+	return m.Name
+}
+
+func (m testModel) DBMarshal() ([]byte, error) {
+	return json.Marshal(m)
+}
+
+func (m testModel) DBUnmarshal(data []byte) (Model, error) {
+	var model testModel
+	err := json.Unmarshal(data, &model)
+	return model, err
+}
+
+func TestMigrate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db")
+	db, err := NewDefaultDB(path, testModel{}.DBRoot())
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.Save(testModel{Name: "russet"}))
+
+	migrations := []Migration{
+		{Version: 1, Description: "default colors", Up: func(tx *Tx) error {
+			models, err := tx.All(testModel{})
+			if err != nil {
+				return err
+			}
+			for _, m := range models {
+				potato := m.(testModel)
+				potato.Color = "brown"
+				err = tx.Save(potato)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+	}
+
+	applied, err := db.MigrateDryRun(migrations)
+	require.NoError(t, err)
+	assert.Len(t, applied, 1)
+	version, err := db.Version()
+	require.NoError(t, err)
+	assert.Equal(t, 0, version, "dry runs roll back")
+
+	applied, err = db.Migrate(migrations)
+	require.NoError(t, err)
+	assert.Len(t, applied, 1)
+	found, err := db.Get(testModel{}, Q().String("color", "brown"))
+	require.NoError(t, err)
+	assert.Len(t, found, 1, "migrations rewrite indexes")
+
+	applied, err = db.Migrate(migrations)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	statuses, err := db.Migrations(migrations)
+	require.NoError(t, err)
+	assert.NotEmpty(t, statuses[0].AppliedAt)
+
+	failing := append(migrations, Migration{Version: 2, Description: "fails", Up: func(tx *Tx) error {
+		require.NoError(t, tx.Save(testModel{Name: "yukon", Color: "gold"}))
+		return fmt.Errorf("nope")
+	}})
+	_, err = db.Migrate(failing)
+	assert.Error(t, err)
+	assert.False(t, db.Exists(testModel{}, "yukon"), "failed migrations roll back")
+
+	_, err = db.Migrate(migrations[:0])
+	assert.Error(t, err, "refuses a db newer than its migrations")
+
+	_, err = db.Migrate([]Migration{{Version: 2, Description: "out of order", Up: migrations[0].Up}})
+	assert.Error(t, err)
+}
+
+func TestMigrationsReadOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db")
+	migrations := []Migration{{Version: 1, Description: "nothing", Up: func(*Tx) error { return nil }}}
+
+	_, err := NewReadOnlyDB(path)
+	assert.Error(t, err, "a missing file isn't created")
+
+	db, err := NewDefaultDB(path, testModel{}.DBRoot())
+	require.NoError(t, err)
+	_, err = db.Migrate(migrations)
+	require.NoError(t, err)
+	db.Close()
+
+	db, err = NewReadOnlyDB(path)
+	require.NoError(t, err)
+	defer db.Close()
+	statuses, err := db.Migrations(migrations)
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.NotEmpty(t, statuses[0].AppliedAt)
+	assert.Error(t, db.Save(testModel{Name: "russet"}), "read only")
+}
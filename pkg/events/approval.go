@@ -0,0 +1,115 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/db"
+	"github.com/freshly/tuber/pkg/notify"
+	"github.com/freshly/tuber/pkg/report"
+	"go.uber.org/zap"
+)
+
+// requestApproval parks a release for an app that requires approval, replacing any older one awaiting approval
+func (p Processor) requestApproval(logger *zap.Logger, errorScope report.Scope, event *Event, app *model.TuberApp, changes notify.Changes) error {
+	requestedBy := event.requestedBy
+	if requestedBy == "" {
+		requestedBy = "image push"
+	}
+
+	pending := &model.PendingRelease{
+		AppName:        app.Name,
+		Digest:         event.digest,
+		Tag:            event.tag,
+		DiffLink:       changes.DiffLink,
+		RequestedBy:    requestedBy,
+		OverrideFreeze: event.overrideFreeze,
+	}
+	now := time.Now().UTC()
+	pending.RequestedAt = now.Format(pending.TimestampFormat())
+	pending.ExpiresAt = now.Add(p.approvalTTL).Format(pending.TimestampFormat())
+
+	err := p.db.SavePendingRelease(pending)
+	if err != nil {
+		p.notifiers.Notify(logger, app, notify.Event{Type: notify.ReleaseFailed, Severity: notify.SeverityError, Tag: event.tag, Release: event.releaseID, Message: "release requires approval, but could not be saved for it"})
+		logger.Error("failed to save pending release", zap.Error(err))
+		report.Error(err, errorScope.WithContext("save pending release"))
+		return err
+	}
+
+	p.notifiers.Notify(logger, app, notify.Event{
+		Type:    notify.ReleaseAwaitingApproval,
+		Tag:     event.tag,
+		Release: event.releaseID,
+		Message: "release requested by " + requestedBy + " is awaiting approval",
+		Detail:  "approve with tuber approve -a " + app.Name + " before " + pending.ExpiresAt,
+		Changes: changes,
+	})
+	logger.Info("release awaiting approval", zap.String("requestedBy", requestedBy), zap.String("expiresAt", pending.ExpiresAt))
+	return nil
+}
+
+// Approve resumes an app's release awaiting approval. It still goes through the same pause and freeze checks as any other release,
+// overriding freezes only if the release it resumes did. Nobody can approve a release they requested.
+func (p Processor) Approve(appName string, approvedBy string) (*model.PendingRelease, error) {
+	pending, app, err := p.decidable(appName, func(pending *model.PendingRelease) error {
+		if strings.EqualFold(pending.RequestedBy, approvedBy) {
+			return fmt.Errorf("%s requested this release, so someone else needs to approve it", approvedBy)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	event := NewEvent(p.logger, pending.Digest, pending.Tag).WithRequester(pending.RequestedBy).WithApproval()
+	if pending.OverrideFreeze {
+		event.OverrideFreeze()
+	}
+	p.notifiers.Notify(event.logger, app, notify.Event{Type: notify.ReleaseApproved, Tag: pending.Tag, Message: "release approved by " + approvedBy})
+	go p.ReleaseApp(event, app)
+
+	return pending, nil
+}
+
+// Reject discards an app's release awaiting approval
+func (p Processor) Reject(appName string, rejectedBy string, reason string) (*model.PendingRelease, error) {
+	pending, app, err := p.decidable(appName, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	message := "release rejected by " + rejectedBy
+	if reason != "" {
+		message += ": " + reason
+	}
+	p.notifiers.Notify(p.logger, app, notify.Event{Type: notify.ReleaseRejected, Tag: pending.Tag, Message: message})
+
+	return pending, nil
+}
+
+// decidable takes an app's pending release out of the db, erroring if there isn't one or it's expired.
+// Taking it is atomic, so two decisions made at once can't both go through, and a release failing check isn't taken.
+func (p Processor) decidable(appName string, check func(*model.PendingRelease) error) (*model.PendingRelease, *model.TuberApp, error) {
+	pending, err := p.db.TakePendingRelease(appName, check)
+	if err != nil {
+		if errors.As(err, &db.NotFoundError{}) {
+			return nil, nil, fmt.Errorf("no release is awaiting approval for %s", appName)
+		}
+		return nil, nil, err
+	}
+
+	if pending.ExpiredAt(time.Now()) {
+		return nil, nil, fmt.Errorf("approval for %s expired at %s, deploy again to request a new one", appName, pending.ExpiresAt)
+	}
+
+	app, err := p.db.App(appName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pending, app, nil
+}
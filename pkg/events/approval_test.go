@@ -0,0 +1,121 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRejectRelease(t *testing.T) {
+	database := testDB(t)
+	processor := NewProcessor(context.Background(), zap.NewNop(), database, nil, nil, false, notify.New(nil, nil, nil, nil), "", nil, time.Hour, nil, "", false)
+	require.NoError(t, database.SaveApp(&model.TuberApp{Name: "potatoes"}))
+
+	now := time.Now().UTC()
+	pending := &model.PendingRelease{AppName: "potatoes", Tag: "potatoes:master", RequestedBy: "image push"}
+	pending.ExpiresAt = now.Add(time.Minute).Format(pending.TimestampFormat())
+	require.NoError(t, database.SavePendingRelease(pending))
+
+	rejected, err := processor.Reject("potatoes", "someone", "not today")
+	require.NoError(t, err)
+	assert.Equal(t, "potatoes:master", rejected.Tag)
+
+	_, err = processor.Reject("potatoes", "someone", "")
+	assert.EqualError(t, err, "no release is awaiting approval for potatoes")
+
+	pending.ExpiresAt = now.Add(-time.Minute).Format(pending.TimestampFormat())
+	require.NoError(t, database.SavePendingRelease(pending))
+
+	_, err = processor.Approve("potatoes", "someone")
+	assert.Error(t, err, "expired releases can't be approved")
+
+	remaining, err := database.PendingReleases("potatoes")
+	require.NoError(t, err)
+	assert.Empty(t, remaining)
+}
+
+func TestDecideOnce(t *testing.T) {
+	database := testDB(t)
+	processor := NewProcessor(context.Background(), zap.NewNop(), database, nil, nil, false, notify.New(nil, nil, nil, nil), "", nil, time.Hour, nil, "", false)
+	require.NoError(t, database.SaveApp(&model.TuberApp{Name: "potatoes"}))
+
+	pending := &model.PendingRelease{AppName: "potatoes", Tag: "potatoes:master", RequestedBy: "image push", OverrideFreeze: true}
+	pending.ExpiresAt = time.Now().UTC().Add(time.Minute).Format(pending.TimestampFormat())
+	require.NoError(t, database.SavePendingRelease(pending))
+
+	decided := make(chan *model.PendingRelease, 10)
+	for i := 0; i < 10; i++ {
+		go func() {
+			rejected, _ := processor.Reject("potatoes", "someone", "")
+			decided <- rejected
+		}()
+	}
+
+	var succeeded int
+	for i := 0; i < 10; i++ {
+		if rejected := <-decided; rejected != nil {
+			succeeded++
+			assert.True(t, rejected.OverrideFreeze, "the release keeps its freeze override")
+		}
+	}
+	assert.Equal(t, 1, succeeded, "only one decision is made on a pending release")
+}
+
+func TestApproveOwnRelease(t *testing.T) {
+	database := testDB(t)
+	processor := NewProcessor(context.Background(), zap.NewNop(), database, nil, nil, false, notify.New(nil, nil, nil, nil), "", nil, time.Hour, nil, "", false)
+	require.NoError(t, database.SaveApp(&model.TuberApp{Name: "potatoes"}))
+
+	pending := &model.PendingRelease{AppName: "potatoes", Tag: "potatoes:master", RequestedBy: "someone@example.com"}
+	pending.ExpiresAt = time.Now().UTC().Add(time.Minute).Format(pending.TimestampFormat())
+	require.NoError(t, database.SavePendingRelease(pending))
+
+	_, err := processor.Approve("potatoes", "Someone@example.com")
+	assert.EqualError(t, err, "Someone@example.com requested this release, so someone else needs to approve it")
+
+	remaining, err := database.PendingReleases("potatoes")
+	require.NoError(t, err)
+	assert.Len(t, remaining, 1, "a refused approval leaves the release for someone else")
+}
+
+func TestApproveOverridesFreeze(t *testing.T) {
+	database := testDB(t)
+	notifiers := notify.New(nil, nil, nil, nil)
+	processor := NewProcessor(context.Background(), zap.NewNop(), database, nil, nil, false, notifiers, "", nil, time.Hour, nil, "", false)
+	require.NoError(t, database.SaveApp(&model.TuberApp{Name: "potatoes"}))
+
+	now := time.Now().UTC()
+	require.NoError(t, database.SaveFreezeWindow(&model.FreezeWindow{
+		Name:     "holidays",
+		Start:    now.Add(-time.Hour).Format(time.RFC3339),
+		End:      now.Add(time.Hour).Format(time.RFC3339),
+		Timezone: "UTC",
+	}))
+
+	pending := &model.PendingRelease{AppName: "potatoes", Tag: "potatoes:master", RequestedBy: "image push", OverrideFreeze: true}
+	pending.ExpiresAt = now.Add(time.Minute).Format(pending.TimestampFormat())
+	require.NoError(t, database.SavePendingRelease(pending))
+
+	events, stop := notifiers.Watch("potatoes")
+	defer stop()
+	assert.Equal(t, notify.WatchStarted, (<-events).Type)
+
+	approved, err := processor.Approve("potatoes", "someone@example.com")
+	require.NoError(t, err)
+	assert.True(t, approved.OverrideFreeze)
+
+	assert.Equal(t, notify.ReleaseApproved, (<-events).Type)
+	select {
+	case event := <-events:
+		assert.NotEqual(t, notify.ReleaseFrozen, event.Type, "the approved release should override the freeze")
+		assert.Equal(t, "image or tuber layer not found", event.Message, "the approved release should get as far as finding its image")
+	case <-time.After(5 * time.Second):
+		t.Fatal("the approved release never ran")
+	}
+}
@@ -0,0 +1,86 @@
+package events
+
+import (
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/core"
+	"github.com/freshly/tuber/pkg/github"
+	"github.com/freshly/tuber/pkg/notify"
+	"github.com/freshly/tuber/pkg/report"
+	"go.uber.org/zap"
+)
+
+// deploymentQueue is how many of a release's events its github deployment can fall behind by before new ones are dropped
+const deploymentQueue = 100
+
+// trackDeployment creates a github deployment for a release and keeps its status in step with the release's notifications.
+// It's skipped without a github client, or when the release's repo and commit aren't known.
+// Github is called from its own goroutine, in the order the release's events happen, so a slow github never holds up a release.
+func (p Processor) trackDeployment(logger *zap.Logger, errorScope report.Scope, app *model.TuberApp, ti tagInfo, notifications *notify.Release) {
+	if p.githubClient == nil || ti.repo == "" || ti.newSHA == "" {
+		return
+	}
+
+	environment := p.githubEnvironment
+	if app.ReviewApp {
+		environment += "/" + app.Name
+	}
+
+	environmentURL, err := core.EnvironmentURL(app, p.ClusterData)
+	if err != nil {
+		logger.Warn("environment url could not be interpolated for github deployment", zap.Error(err))
+	}
+
+	events := make(chan notify.Event, deploymentQueue)
+	notifications.Observe(func(_ *zap.Logger, event notify.Event) {
+		select {
+		case events <- event:
+		default:
+			logger.Warn("github deployment is too far behind, dropping status", zap.String("eventType", event.Type))
+		}
+	})
+
+	go func() {
+		deployment, err := p.githubClient.CreateDeployment(ti.repo, ti.newSHA, environment, environmentURL, app.Name, app.ReviewApp, p.githubProduction && !app.ReviewApp)
+		if err != nil {
+			logger.Error("failed to create github deployment", zap.Error(err))
+			report.Error(err, errorScope.WithContext("create github deployment"))
+			return
+		}
+
+		logger := logger.With(zap.Int64("githubDeployment", deployment.ID))
+		for {
+			select {
+			case <-p.ctx.Done():
+				return
+			case event := <-events:
+				updateDeployment(logger, errorScope, deployment, event)
+				if event.Finished() {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// updateDeployment moves a github deployment along with one of its release's events
+func updateDeployment(logger *zap.Logger, errorScope report.Scope, deployment *github.Deployment, event notify.Event) {
+	var err error
+	switch event.Type {
+	case notify.ReleaseStarted, notify.ReleaseCanaryStarted, notify.ReleaseCanaryDeployed:
+		err = deployment.SetStatus(github.StateInProgress, event.Message)
+	case notify.ReleaseSucceeded:
+		err = deployment.SetStatus(github.StateSuccess, event.Message)
+		if err == nil {
+			err = deployment.InactivatePrevious()
+		}
+	case notify.ReleaseFailed:
+		err = deployment.SetStatus(github.StateFailure, event.Message+": "+event.Detail)
+	default:
+		return
+	}
+
+	if err != nil {
+		logger.Error("failed to update github deployment status", zap.Error(err), zap.String("eventType", event.Type))
+		report.Error(err, errorScope.WithContext("update github deployment status"))
+	}
+}
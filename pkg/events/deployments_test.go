@@ -0,0 +1,46 @@
+package events
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/core"
+	"github.com/freshly/tuber/pkg/github"
+	"github.com/freshly/tuber/pkg/notify"
+	"github.com/freshly/tuber/pkg/report"
+	"go.uber.org/zap"
+)
+
+func TestTrackDeploymentDoesNotWaitOnGithub(t *testing.T) {
+	stuck := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-stuck
+	}))
+	defer server.Close()
+	defer close(stuck)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	notifiers := notify.New(nil, nil, nil, nil)
+	processor := NewProcessor(ctx, zap.NewNop(), nil, nil, &core.ClusterData{}, false, notifiers, "", nil, time.Hour, github.New(server.URL, "secret"), "production", true)
+	app := &model.TuberApp{Name: "potatoes"}
+
+	done := make(chan struct{})
+	go func() {
+		notifications := notifiers.Release(app, notify.NewReleaseID(app.Name), "potatoes:master", notify.Changes{})
+		processor.trackDeployment(zap.NewNop(), report.Scope{}, app, tagInfo{repo: "freshly/potatoes", newSHA: "abc123"}, notifications)
+		notifications.Notify(zap.NewNop(), notify.Event{Type: notify.ReleaseStarted})
+		notifications.Notify(zap.NewNop(), notify.Event{Type: notify.ReleaseSucceeded})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the release waited on github")
+	}
+}
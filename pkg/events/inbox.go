@@ -0,0 +1,207 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/core"
+	"github.com/freshly/tuber/pkg/metrics"
+	psub "github.com/freshly/tuber/pkg/pubsub"
+	"github.com/freshly/tuber/pkg/report"
+	"go.uber.org/zap"
+)
+
+const inboxPollInterval = 30 * time.Second
+const inboxRetention = 7 * 24 * time.Hour
+
+// Inbox durably stores incoming image events before they're processed, so a crash mid-release doesn't lose them.
+// Events are de-duplicated by digest and tag, and anything left processing at startup is picked back up.
+type Inbox struct {
+	ctx       context.Context
+	logger    *zap.Logger
+	db        *core.DB
+	processor *Processor
+	wake      chan struct{}
+}
+
+// NewInbox constructs an Inbox feeding the given Processor
+func NewInbox(ctx context.Context, logger *zap.Logger, db *core.DB, processor *Processor) *Inbox {
+	return &Inbox{
+		ctx:       ctx,
+		logger:    logger.With(zap.String("context", "inbox")),
+		db:        db,
+		processor: processor,
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// Accept persists a message as a pending event. Listeners ack only after Accept returns without error.
+func (i *Inbox) Accept(message psub.Message) error {
+	id := model.InboxEventID(message.Digest, message.Tag)
+	inserted, err := i.db.InsertInboxEvent(&model.InboxEvent{
+		ID:     id,
+		Digest: message.Digest,
+		Tag:    message.Tag,
+		Status: model.InboxEventPending,
+		Trace:  message.Trace,
+	})
+	if err != nil {
+		return err
+	}
+	if !inserted {
+		metrics.PubsubMessagesIgnored.WithLabelValues(metrics.IgnoredDuplicate).Inc()
+		i.logger.Debug("duplicate event ignored", zap.String("id", id), zap.String("tag", message.Tag), zap.String("digest", message.Digest))
+		return nil
+	}
+
+	i.notify()
+	return nil
+}
+
+// Process accepts a message for callers that can't act on a failed Accept
+func (i *Inbox) Process(message psub.Message) {
+	err := i.Accept(message)
+	if err != nil {
+		i.logger.Error("failed to store event", zap.Error(err), zap.String("tag", message.Tag), zap.String("digest", message.Digest))
+		report.Error(err, report.Scope{"context": "inbox accept", "tag": message.Tag, "digest": message.Digest})
+	}
+}
+
+// Replay sets a finished event back to pending and processes it again. Checking and marking it happen together,
+// so an event replayed twice at once is only processed again once.
+func (i *Inbox) Replay(id string) (*model.InboxEvent, error) {
+	event, err := i.db.UpdateInboxEvent(id, func(event *model.InboxEvent) error {
+		switch event.Status {
+		case model.InboxEventProcessing:
+			return fmt.Errorf("event %s is currently processing", id)
+		case model.InboxEventPending:
+			return fmt.Errorf("event %s is already waiting to be processed", id)
+		}
+		event.Status = model.InboxEventPending
+		event.Error = ""
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	i.notify()
+	return event, nil
+}
+
+func (i *Inbox) notify() {
+	select {
+	case i.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start processes pending events until the Inbox's context is cancelled
+func (i *Inbox) Start() {
+	i.resumeInterrupted()
+
+	ticker := time.NewTicker(inboxPollInterval)
+	defer ticker.Stop()
+
+	for {
+		i.drain()
+		i.prune()
+
+		select {
+		case <-i.ctx.Done():
+			i.logger.Debug("inbox stopped")
+			return
+		case <-i.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// events marked processing when tuber last stopped never finished, so they go back in line
+func (i *Inbox) resumeInterrupted() {
+	interrupted, err := i.db.InboxEvents(model.InboxEventProcessing)
+	if err != nil {
+		i.logger.Error("failed to load interrupted events", zap.Error(err))
+		report.Error(err, report.Scope{"context": "inbox resume"})
+		return
+	}
+
+	for _, event := range interrupted {
+		i.logger.Info("resuming interrupted event", zap.String("id", event.ID), zap.String("tag", event.Tag))
+		event.Status = model.InboxEventPending
+		err = i.db.SaveInboxEvent(event)
+		if err != nil {
+			i.logger.Error("failed to resume interrupted event", zap.Error(err), zap.String("id", event.ID))
+		}
+	}
+}
+
+func (i *Inbox) drain() {
+	pending, err := i.db.InboxEvents(model.InboxEventPending)
+	if err != nil {
+		i.logger.Error("failed to load pending events", zap.Error(err))
+		report.Error(err, report.Scope{"context": "inbox drain"})
+		return
+	}
+
+	for _, event := range pending {
+		event.Status = model.InboxEventProcessing
+		event.Attempts++
+		err = i.db.SaveInboxEvent(event)
+		if err != nil {
+			i.logger.Error("failed to mark event processing", zap.Error(err), zap.String("id", event.ID))
+			continue
+		}
+
+		go i.process(event)
+	}
+}
+
+func (i *Inbox) process(event *model.InboxEvent) {
+	status := model.InboxEventDone
+	var message string
+
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic processing event: %v", r)
+			}
+		}()
+		return i.processor.process(psub.Message{Digest: event.Digest, Tag: event.Tag, Trace: event.Trace})
+	}()
+	if err != nil {
+		status = model.InboxEventFailed
+		message = err.Error()
+	}
+
+	event.Status = status
+	event.Error = message
+	err = i.db.SaveInboxEvent(event)
+	if err != nil {
+		i.logger.Error("failed to save event status", zap.Error(err), zap.String("id", event.ID), zap.String("status", status))
+		report.Error(err, report.Scope{"context": "inbox save status", "id": event.ID})
+	}
+}
+
+// finished events are kept long enough to catch redeliveries, then removed
+func (i *Inbox) prune() {
+	for _, status := range []string{model.InboxEventDone, model.InboxEventFailed} {
+		events, err := i.db.InboxEvents(status)
+		if err != nil {
+			i.logger.Error("failed to load events for pruning", zap.Error(err))
+			return
+		}
+		for _, event := range events {
+			updatedAt, parseErr := event.ParsedUpdatedAt()
+			if parseErr != nil || time.Since(updatedAt) < inboxRetention {
+				continue
+			}
+			err = i.db.DeleteInboxEvent(event)
+			if err != nil {
+				i.logger.Error("failed to prune event", zap.Error(err), zap.String("id", event.ID))
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package events
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/core"
+	"github.com/freshly/tuber/pkg/db"
+	"github.com/freshly/tuber/pkg/notify"
+	psub "github.com/freshly/tuber/pkg/pubsub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func testDB(t *testing.T) *core.DB {
+	database, err := db.NewDefaultDB(filepath.Join(t.TempDir(), "db"), model.TuberApp{}.DBRoot(), model.InboxEvent{}.DBRoot(), model.PendingRelease{}.DBRoot(), model.WebhookDelivery{}.DBRoot(), model.FreezeWindow{}.DBRoot(), model.ClusterState{}.DBRoot())
+	require.NoError(t, err)
+	t.Cleanup(database.Close)
+	return core.NewDB(database)
+}
+
+func TestInbox(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	database := testDB(t)
+	processor := NewProcessor(ctx, zap.NewNop(), database, nil, nil, false, notify.New(nil, nil, nil, nil), "", nil, time.Hour, nil, "", false)
+	inbox := NewInbox(ctx, zap.NewNop(), database, processor)
+
+	message := psub.Message{Digest: "gcr.io/freshly-docker/potatoes@sha256:abc", Tag: "gcr.io/freshly-docker/potatoes:master"}
+	require.NoError(t, inbox.Accept(message))
+	require.NoError(t, inbox.Accept(message))
+
+	pending, err := database.InboxEvents(model.InboxEventPending)
+	require.NoError(t, err)
+	require.Len(t, pending, 1, "duplicate digest and tag should be de-duplicated")
+
+	id := pending[0].ID
+	assert.Equal(t, model.InboxEventID(message.Digest, message.Tag), id)
+
+	waitForStatus := func(status string, attempts int) {
+		assert.Eventually(t, func() bool {
+			event, findErr := database.InboxEvent(id)
+			return findErr == nil && event.Status == status && event.Attempts == attempts
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	go inbox.Start()
+	waitForStatus(model.InboxEventDone, 1)
+
+	_, err = inbox.Replay(id)
+	require.NoError(t, err)
+	waitForStatus(model.InboxEventDone, 2)
+
+	_, err = inbox.Replay("nope")
+	assert.Error(t, err)
+}
+
+func TestInsertInboxEventConcurrently(t *testing.T) {
+	database := testDB(t)
+
+	var inserted int32
+	var wg sync.WaitGroup
+	for n := 0; n < 20; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := database.InsertInboxEvent(&model.InboxEvent{ID: "redelivered", Status: model.InboxEventPending})
+			assert.NoError(t, err)
+			if ok {
+				atomic.AddInt32(&inserted, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), inserted, "concurrent redeliveries should only be accepted once")
+}
+
+func TestReplayConcurrently(t *testing.T) {
+	database := testDB(t)
+	require.NoError(t, database.SaveInboxEvent(&model.InboxEvent{ID: "done", Digest: "d", Tag: "t", Status: model.InboxEventDone, Error: "release failed"}))
+	inbox := NewInbox(context.Background(), zap.NewNop(), database, nil)
+
+	var replayed int32
+	var wg sync.WaitGroup
+	for n := 0; n < 20; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := inbox.Replay("done")
+			if err == nil {
+				atomic.AddInt32(&replayed, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), replayed, "concurrent replays should only replay an event once")
+
+	event, err := database.InboxEvent("done")
+	require.NoError(t, err)
+	assert.Equal(t, model.InboxEventPending, event.Status)
+	assert.Empty(t, event.Error)
+}
+
+func TestInboxResumesInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	database := testDB(t)
+	interrupted := &model.InboxEvent{ID: "interrupted", Digest: "d", Tag: "t", Status: model.InboxEventProcessing, Attempts: 1}
+	require.NoError(t, database.SaveInboxEvent(interrupted))
+
+	processor := NewProcessor(ctx, zap.NewNop(), database, nil, nil, false, notify.New(nil, nil, nil, nil), "", nil, time.Hour, nil, "", false)
+	go NewInbox(ctx, zap.NewNop(), database, processor).Start()
+
+	assert.Eventually(t, func() bool {
+		event, err := database.InboxEvent("interrupted")
+		return err == nil && event.Status == model.InboxEventDone && event.Attempts == 2
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,236 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/gcr"
+	"github.com/freshly/tuber/pkg/notify"
+	"go.uber.org/zap"
+	"google.golang.org/api/option"
+)
+
+// LifecycleSchemaVersion is bumped on any breaking change to LifecycleEvent
+const LifecycleSchemaVersion = 1
+
+// localPublisherKept is how many of the most recent events a LocalPublisher keeps
+const localPublisherKept = 1000
+
+// Lifecycle event types
+const (
+	LifecycleStarted            = notify.ReleaseStarted
+	LifecyclePrereleaseFinished = notify.ReleasePrereleaseFinished
+	LifecycleCanaryStarted      = notify.ReleaseCanaryStarted
+	LifecycleCanaryDeployed     = notify.ReleaseCanaryDeployed
+	LifecycleSucceeded          = notify.ReleaseSucceeded
+	LifecycleFailed             = notify.ReleaseFailed
+	LifecycleRolledBack         = notify.ReleaseRolledBack
+	LifecycleSkipped            = notify.ReleaseSkipped
+)
+
+// LifecycleEvent is published to the tuber lifecycle events topic at each step of a release.
+// appName, commitSha, repo and branch are the fields the completed message has.
+type LifecycleEvent struct {
+	SchemaVersion   int       `json:"schemaVersion"`
+	Type            string    `json:"type"`
+	ReleaseID       string    `json:"releaseId,omitempty"`
+	AppName         string    `json:"appName"`
+	CommitSha       string    `json:"commitSha"`
+	Repo            string    `json:"repo"`
+	Branch          string    `json:"branch"`
+	Tag             string    `json:"tag"`
+	Digest          string    `json:"digest"`
+	Reason          string    `json:"reason,omitempty"`
+	DurationSeconds float64   `json:"durationSeconds,omitempty"`
+	Time            time.Time `json:"time"`
+}
+
+// Publisher sends lifecycle events somewhere downstream systems can consume them
+type Publisher interface {
+	Publish(ctx context.Context, event LifecycleEvent) error
+}
+
+// Message is the completed message, published to the tuber events topic once a release succeeds
+type Message struct {
+	AppName   string `json:"appName"`
+	CommitSha string `json:"commitSha"`
+	Repo      string `json:"repo"`
+	Branch    string `json:"branch"`
+}
+
+// CompletedPublisher publishes the completed message for succeeded releases, leaving every other lifecycle event out,
+// for the tuber events topic's existing subscribers
+type CompletedPublisher struct {
+	logger *zap.Logger
+	topic  *pubsub.Topic
+}
+
+func NewCompletedPublisher(ctx context.Context, logger *zap.Logger, project string, topic string, creds []byte) (*CompletedPublisher, error) {
+	client, err := pubsub.NewClient(ctx, project, option.WithCredentialsJSON(creds))
+	if err != nil {
+		return nil, err
+	}
+	return &CompletedPublisher{logger: logger.With(zap.String("topic", topic)), topic: client.Topic(topic)}, nil
+}
+
+func (c *CompletedPublisher) Publish(ctx context.Context, event LifecycleEvent) error {
+	message, ok := completedMessage(event)
+	if !ok {
+		return nil
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	awaitPublished(ctx, c.logger, c.topic.Publish(ctx, &pubsub.Message{Data: data}), event.Type)
+	return nil
+}
+
+// awaitPublished logs a failed publish once pubsub has tried it. Pubsub batches publishes, so they're waited on in the background
+// rather than holding up the release until the batch goes out.
+func awaitPublished(ctx context.Context, logger *zap.Logger, res *pubsub.PublishResult, eventType string) {
+	go func() {
+		_, err := res.Get(ctx)
+		if err != nil {
+			logger.Error("failed to publish lifecycle event", zap.Error(err), zap.String("lifecycleEvent", eventType))
+		}
+	}()
+}
+
+// completedMessage is the completed message for a succeeded release, if there's git info to send
+func completedMessage(event LifecycleEvent) (Message, bool) {
+	if event.Type != LifecycleSucceeded || event.Branch == "" || event.CommitSha == "" {
+		return Message{}, false
+	}
+	return Message{AppName: event.AppName, CommitSha: event.CommitSha, Repo: event.Repo, Branch: event.Branch}, true
+}
+
+// PubsubPublisher publishes lifecycle events to a pubsub topic. The type and schemaVersion are also set as message attributes,
+// so subscriptions that only care about some events can filter on attributes.type.
+type PubsubPublisher struct {
+	logger *zap.Logger
+	topic  *pubsub.Topic
+}
+
+func NewPubsubPublisher(ctx context.Context, logger *zap.Logger, project string, topic string, creds []byte) (*PubsubPublisher, error) {
+	client, err := pubsub.NewClient(ctx, project, option.WithCredentialsJSON(creds))
+	if err != nil {
+		return nil, err
+	}
+	return &PubsubPublisher{logger: logger.With(zap.String("topic", topic)), topic: client.Topic(topic)}, nil
+}
+
+func (p *PubsubPublisher) Publish(ctx context.Context, event LifecycleEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	res := p.topic.Publish(ctx, &pubsub.Message{
+		Data: data,
+		Attributes: map[string]string{
+			"type":          event.Type,
+			"schemaVersion": strconv.Itoa(event.SchemaVersion),
+		},
+	})
+	awaitPublished(ctx, p.logger, res, event.Type)
+	return nil
+}
+
+// LocalPublisher keeps the most recent lifecycle events in memory, for running without pubsub and for tests
+type LocalPublisher struct {
+	logger *zap.Logger
+	events []LifecycleEvent
+	mutex  sync.Mutex
+}
+
+func NewLocalPublisher(logger *zap.Logger) *LocalPublisher {
+	return &LocalPublisher{logger: logger}
+}
+
+func (l *LocalPublisher) Publish(ctx context.Context, event LifecycleEvent) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.logger.Debug("lifecycle event published", zap.String("lifecycleEvent", event.Type), zap.String("appName", event.AppName))
+	l.events = append(l.events, event)
+	if len(l.events) > localPublisherKept {
+		l.events = append([]LifecycleEvent{}, l.events[len(l.events)-localPublisherKept:]...)
+	}
+	return nil
+}
+
+// Events returns everything published so far
+func (l *LocalPublisher) Events() []LifecycleEvent {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return append([]LifecycleEvent{}, l.events...)
+}
+
+// publish fills in what every lifecycle event has and sends it, logging failures - downstream being unavailable doesn't stop releases
+func (p Processor) publish(logger *zap.Logger, event *Event, app *model.TuberApp, ti tagInfo, lifecycle LifecycleEvent) {
+	if p.publisher == nil {
+		return
+	}
+
+	lifecycle.SchemaVersion = LifecycleSchemaVersion
+	lifecycle.AppName = app.Name
+	lifecycle.CommitSha = ti.newSHA
+	lifecycle.Repo = ti.repo
+	lifecycle.Branch = ti.branch
+	lifecycle.Tag = event.tag
+	lifecycle.Digest = event.digest
+	if lifecycle.Time.IsZero() {
+		lifecycle.Time = time.Now().UTC()
+	}
+
+	err := p.publisher.Publish(p.ctx, lifecycle)
+	if err != nil {
+		logger.Error("failed to publish lifecycle event", zap.Error(err), zap.String("lifecycleEvent", lifecycle.Type))
+	}
+}
+
+// publishSkipped publishes a skipped event. Skips happen before the image is looked at for a release,
+// so it's looked at here for the commit - falling back to the event's tag for the branch if it can't be.
+// Looking at the image can be slow, so it's never called holding the app's release lock.
+func (p Processor) publishSkipped(event *Event, app *model.TuberApp, reason string) {
+	if p.publisher == nil {
+		return
+	}
+
+	var ti tagInfo
+	yamls, err := gcr.GetTuberLayer(event.logger, event.digest, p.creds)
+	if err == nil && githubRepo(app, yamls) != "" {
+		ti, err = getTagInfo(app, yamls)
+	}
+	if err != nil || ti.newSHA == "" {
+		event.logger.Debug("skipped release's commit could not be found", zap.Error(err))
+		ti.repo = app.GithubRepo
+		ti.branch, _ = gcr.TagFromRef(event.tag)
+	}
+	p.publish(event.logger, event, app, ti, LifecycleEvent{Type: LifecycleSkipped, Reason: reason})
+}
+
+// trackLifecycle publishes a lifecycle event for each step of a release, from the release's notifications
+func (p Processor) trackLifecycle(event *Event, app *model.TuberApp, ti tagInfo, startTime time.Time, notifications *notify.Release) {
+	notifications.Observe(func(logger *zap.Logger, notification notify.Event) {
+		lifecycle := LifecycleEvent{Type: notification.Type, ReleaseID: notification.Release}
+		switch notification.Type {
+		case LifecycleStarted, LifecyclePrereleaseFinished, LifecycleCanaryStarted, LifecycleCanaryDeployed, LifecycleRolledBack:
+		case LifecycleSucceeded:
+			lifecycle.DurationSeconds = time.Since(startTime).Seconds()
+		case LifecycleFailed:
+			lifecycle.DurationSeconds = time.Since(startTime).Seconds()
+			lifecycle.Reason = notification.Detail
+		default:
+			return
+		}
+		p.publish(logger, event, app, ti, lifecycle)
+	})
+}
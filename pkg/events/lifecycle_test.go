@@ -0,0 +1,91 @@
+package events
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestLifecycleEvents(t *testing.T) {
+	publisher := NewLocalPublisher(zap.NewNop())
+	notifiers := notify.New(nil, nil, nil, nil)
+	processor := NewProcessor(context.Background(), zap.NewNop(), testDB(t), nil, nil, false, notifiers, "", publisher, time.Hour, nil, "", false)
+
+	app := &model.TuberApp{Name: "potatoes"}
+	event := NewEvent(zap.NewNop(), "gcr.io/freshly-docker/potatoes@sha256:abc", "gcr.io/freshly-docker/potatoes:master")
+	ti := tagInfo{branch: "master", newSHA: "new", repo: "freshly/potatoes"}
+
+	notifications := notifiers.Release(app, notify.NewReleaseID(app.Name), event.tag, notify.Changes{})
+	processor.trackLifecycle(event, app, ti, time.Now().Add(-time.Minute), notifications)
+	notifications.Notify(zap.NewNop(), notify.Event{Type: notify.ReleaseStarted, Message: "release starting"})
+	notifications.Notify(zap.NewNop(), notify.Event{Type: notify.ReleaseWarning, Message: "not a lifecycle event"})
+	notifications.Notify(zap.NewNop(), notify.Event{Type: notify.ReleaseRolledBack, Message: "rolled back"})
+	notifications.Notify(zap.NewNop(), notify.Event{Type: notify.ReleaseFailed, Message: "release failed", Detail: "deployment timed out"})
+
+	published := publisher.Events()
+	require.Len(t, published, 3)
+	assert.Equal(t, []string{LifecycleStarted, LifecycleRolledBack, LifecycleFailed}, []string{published[0].Type, published[1].Type, published[2].Type})
+
+	failed := published[2]
+	assert.Equal(t, LifecycleSchemaVersion, failed.SchemaVersion)
+	assert.Equal(t, "potatoes", failed.AppName)
+	assert.Equal(t, "new", failed.CommitSha)
+	assert.Equal(t, "freshly/potatoes", failed.Repo)
+	assert.Equal(t, "master", failed.Branch)
+	assert.Equal(t, "gcr.io/freshly-docker/potatoes@sha256:abc", failed.Digest)
+	assert.Equal(t, "deployment timed out", failed.Reason)
+	assert.Equal(t, published[0].ReleaseID, failed.ReleaseID)
+	assert.GreaterOrEqual(t, failed.DurationSeconds, 60.0)
+}
+
+func TestLifecycleSkippedWhilePaused(t *testing.T) {
+	database := testDB(t)
+	publisher := NewLocalPublisher(zap.NewNop())
+	processor := NewProcessor(context.Background(), zap.NewNop(), database, nil, nil, false, notify.New(nil, nil, nil, nil), "", publisher, time.Hour, nil, "", false)
+
+	app := &model.TuberApp{Name: "potatoes", Paused: true, GithubRepo: "freshly/potatoes"}
+	require.NoError(t, database.SaveApp(app))
+
+	require.NoError(t, processor.ReleaseApp(NewEvent(zap.NewNop(), "digest", "gcr.io/freshly-docker/potatoes:master"), app))
+
+	published := publisher.Events()
+	require.Len(t, published, 1)
+	assert.Equal(t, LifecycleSkipped, published[0].Type)
+	assert.Equal(t, "app is paused", published[0].Reason)
+	assert.Equal(t, "master", published[0].Branch)
+	assert.Equal(t, "freshly/potatoes", published[0].Repo)
+}
+
+func TestCompletedMessage(t *testing.T) {
+	succeeded := LifecycleEvent{Type: LifecycleSucceeded, AppName: "potatoes", CommitSha: "abc", Repo: "freshly/potatoes", Branch: "master"}
+	message, ok := completedMessage(succeeded)
+	assert.True(t, ok)
+	assert.Equal(t, Message{AppName: "potatoes", CommitSha: "abc", Repo: "freshly/potatoes", Branch: "master"}, message)
+
+	failed := succeeded
+	failed.Type = LifecycleFailed
+	_, ok = completedMessage(failed)
+	assert.False(t, ok, "only succeeded releases send the completed message")
+
+	noCommit := succeeded
+	noCommit.CommitSha = ""
+	_, ok = completedMessage(noCommit)
+	assert.False(t, ok)
+}
+
+func TestLocalPublisherCap(t *testing.T) {
+	publisher := NewLocalPublisher(zap.NewNop())
+	for i := 0; i < localPublisherKept+10; i++ {
+		require.NoError(t, publisher.Publish(context.Background(), LifecycleEvent{ReleaseID: strconv.Itoa(i)}))
+	}
+	published := publisher.Events()
+	require.Len(t, published, localPublisherKept)
+	assert.Equal(t, "10", published[0].ReleaseID)
+}
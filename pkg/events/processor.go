@@ -2,70 +2,85 @@ package events
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"strings"
 	"sync"
 	"time"
 
-	"cloud.google.com/go/pubsub"
 	"github.com/freshly/tuber/graph/model"
 	"github.com/freshly/tuber/pkg/core"
 	"github.com/freshly/tuber/pkg/gcr"
+	"github.com/freshly/tuber/pkg/github"
+	"github.com/freshly/tuber/pkg/metrics"
+	"github.com/freshly/tuber/pkg/notify"
 	psub "github.com/freshly/tuber/pkg/pubsub"
 	"github.com/freshly/tuber/pkg/report"
-	"github.com/freshly/tuber/pkg/slack"
+	"github.com/freshly/tuber/pkg/tracing"
 	"github.com/getsentry/sentry-go"
-	"google.golang.org/api/option"
 
 	"go.uber.org/zap"
 )
 
+// maxReleaseCommits caps how many commits are listed in a release's notifications
+const maxReleaseCommits = 10
+
 // Processor processes events
 type Processor struct {
-	ctx                context.Context
-	logger             *zap.Logger
-	creds              []byte
-	ClusterData        *core.ClusterData
-	reviewAppsEnabled  bool
-	locks              *map[string]*sync.Cond
-	slackClient        *slack.Client
-	db                 *core.DB
-	sentryBearerToken  string
-	tuberEventsProject string
-	tuberEventsTopic   string
+	ctx               context.Context
+	logger            *zap.Logger
+	creds             []byte
+	ClusterData       *core.ClusterData
+	reviewAppsEnabled bool
+	locks             *map[string]*sync.Cond
+	notifiers         *notify.Notifiers
+	db                *core.DB
+	sentryBearerToken string
+	publisher         Publisher
+	approvalTTL       time.Duration
+	githubClient      *github.Client
+	githubEnvironment string
+	githubProduction  bool
 }
 
 // NewProcessor constructs a Processor
-func NewProcessor(ctx context.Context, logger *zap.Logger, db *core.DB, creds []byte, clusterData *core.ClusterData, reviewAppsEnabled bool, slackClient *slack.Client, sentryBearerToken string, tuberEventsProject string, tuberEventsTopic string) *Processor {
+func NewProcessor(ctx context.Context, logger *zap.Logger, db *core.DB, creds []byte, clusterData *core.ClusterData, reviewAppsEnabled bool, notifiers *notify.Notifiers, sentryBearerToken string, publisher Publisher, approvalTTL time.Duration, githubClient *github.Client, githubEnvironment string, githubProduction bool) *Processor {
 	l := make(map[string]*sync.Cond)
 
 	return &Processor{
-		ctx:                ctx,
-		logger:             logger,
-		creds:              creds,
-		ClusterData:        clusterData,
-		reviewAppsEnabled:  reviewAppsEnabled,
-		locks:              &l,
-		slackClient:        slackClient,
-		db:                 db,
-		sentryBearerToken:  sentryBearerToken,
-		tuberEventsProject: tuberEventsProject,
-		tuberEventsTopic:   tuberEventsTopic,
+		ctx:               ctx,
+		logger:            logger,
+		creds:             creds,
+		ClusterData:       clusterData,
+		reviewAppsEnabled: reviewAppsEnabled,
+		locks:             &l,
+		notifiers:         notifiers,
+		db:                db,
+		sentryBearerToken: sentryBearerToken,
+		publisher:         publisher,
+		approvalTTL:       approvalTTL,
+		githubClient:      githubClient,
+		githubEnvironment: githubEnvironment,
+		githubProduction:  githubProduction,
 	}
 }
 
 type Event struct {
-	digest     string
-	tag        string
-	logger     *zap.Logger
-	errorScope report.Scope
+	ctx            context.Context
+	digest         string
+	tag            string
+	logger         *zap.Logger
+	errorScope     report.Scope
+	overrideFreeze bool
+	requestedBy    string
+	approved       bool
+	releaseID      string
 }
 
 func NewEvent(logger *zap.Logger, digest string, tag string) *Event {
 	logger = logger.With(zap.String("tag", tag), zap.String("digest", digest))
 	scope := report.Scope{"tag": tag, "digest": digest}
 	return &Event{
+		ctx:        context.Background(),
 		digest:     digest,
 		tag:        tag,
 		logger:     logger,
@@ -73,23 +88,63 @@ func NewEvent(logger *zap.Logger, digest string, tag string) *Event {
 	}
 }
 
-// ProcessMessage receives a pubsub message, filters it against TuberApps, and triggers releases for matching apps
+// WithReleaseID sets the id the release's notifications go out under, so whoever started it can follow it
+func (e *Event) WithReleaseID(releaseID string) *Event {
+	e.releaseID = releaseID
+	return e
+}
+
+// WithContext makes the event's spans children of the span in ctx
+func (e *Event) WithContext(ctx context.Context) *Event {
+	e.ctx = ctx
+	return e
+}
+
+// OverrideFreeze lets the event release apps during an active freeze window
+func (e *Event) OverrideFreeze() *Event {
+	e.overrideFreeze = true
+	return e
+}
+
+// WithRequester records who asked for the release, for approval requests
+func (e *Event) WithRequester(requestedBy string) *Event {
+	e.requestedBy = requestedBy
+	return e
+}
+
+// WithApproval lets the event release apps that require approval
+func (e *Event) WithApproval() *Event {
+	e.approved = true
+	return e
+}
+
+// Process receives a pubsub message, filters it against TuberApps, and triggers releases for matching apps
 func (p Processor) Process(message psub.Message) {
-	event := NewEvent(p.logger, message.Digest, message.Tag)
+	_ = p.process(message)
+}
+
+// process returns an error if the event couldn't be matched to apps or any matching app's release failed
+func (p Processor) process(message psub.Message) (err error) {
+	ctx, span := tracing.Start(tracing.Extract(p.ctx, message.Trace), "events.process", tracing.Digest(message.Digest), tracing.Tag(message.Tag))
+	defer func() { tracing.End(span, err) }()
+
+	event := NewEvent(p.logger, message.Digest, message.Tag).WithContext(ctx)
 
 	apps, err := p.db.AppsForTag(event.tag)
 	if err != nil {
 		event.logger.Error("failed to look up tuber apps", zap.Error(err))
 		report.Error(err, event.errorScope.WithContext("tuber apps lookup"))
-		return
+		return err
 	}
 
 	if len(apps) == 0 {
+		metrics.PubsubMessagesIgnored.WithLabelValues(metrics.IgnoredNoApps).Inc()
 		event.logger.Debug("ignored event")
-		return
+		return nil
 	}
 
 	wg := sync.WaitGroup{}
+	errs := make(chan error, len(apps))
 
 	for _, a := range apps {
 		wg.Add(1)
@@ -97,16 +152,32 @@ func (p Processor) Process(message psub.Message) {
 		go func(app *model.TuberApp) {
 			defer sentry.Recover()
 			defer wg.Done()
-			p.ReleaseApp(event, app)
+			if releaseErr := p.ReleaseApp(event, app); releaseErr != nil {
+				errs <- fmt.Errorf("%s: %v", app.Name, releaseErr)
+			}
 		}(a)
 	}
 	wg.Wait()
+	close(errs)
+
+	var failures []string
+	for releaseErr := range errs {
+		failures = append(failures, releaseErr.Error())
+	}
+	if len(failures) != 0 {
+		return fmt.Errorf("%s", strings.Join(failures, "; "))
+	}
+	return nil
 }
 
-func (p Processor) ReleaseApp(event *Event, app *model.TuberApp) {
+func (p Processor) ReleaseApp(event *Event, app *model.TuberApp) error {
 	// todo: the one in start _does not help mid-release panics_, errors package needs this functionality
 	defer sentry.Recover()
 
+	if event.releaseID == "" {
+		event.releaseID = notify.NewReleaseID(app.Name)
+	}
+
 	if _, ok := (*p.locks)[app.Name]; !ok {
 		var mutex sync.Mutex
 		(*p.locks)[app.Name] = sync.NewCond(&mutex)
@@ -118,23 +189,76 @@ func (p Processor) ReleaseApp(event *Event, app *model.TuberApp) {
 	if err != nil {
 		event.logger.Error("app could not be reloaded", zap.Error(err))
 		report.Error(err, event.errorScope.WithContext("reload prior to paused check for release"))
-		p.slackClient.Message(event.logger, ":double_vertical_bar: release skipped for "+app.Name+" as it could not be reloaded", app.SlackChannel)
+		p.notifiers.Notify(event.logger, app, notify.Event{Type: notify.ReleaseSkipped, Tag: event.tag, Release: event.releaseID, Message: "release skipped as the app could not be reloaded"})
 		cond.L.Unlock()
-		return
+		return err
 	}
 
 	if reloadedApp.Paused {
-		p.slackClient.Message(event.logger, ":double_vertical_bar: release skipped for "+reloadedApp.Name+" as it is paused", reloadedApp.SlackChannel)
+		p.notifiers.Notify(event.logger, reloadedApp, notify.Event{
+			Type:    notify.ReleaseSkipped,
+			Tag:     event.tag,
+			Release: event.releaseID,
+			Message: "release skipped as the app is paused",
+			Actions: []string{notify.ActionResume, notify.ActionRetry},
+		})
+		cond.L.Unlock()
+		p.publishSkipped(event, reloadedApp, "app is paused")
+		metrics.Release(reloadedApp.Name, metrics.OutcomeSkipped)
 		event.logger.Warn("deployments are paused for this app; skipping", zap.String("appName", reloadedApp.Name))
+		return nil
+	}
+
+	clusterState, err := p.db.ClusterState()
+	if err != nil {
+		event.logger.Error("cluster state could not be loaded", zap.Error(err))
+		report.Error(err, event.errorScope.WithContext("cluster paused check for release"))
+		p.notifiers.Notify(event.logger, reloadedApp, notify.Event{Type: notify.ReleaseSkipped, Tag: event.tag, Release: event.releaseID, Message: "release skipped as cluster state could not be loaded"})
+		cond.L.Unlock()
+		return err
+	}
+
+	if clusterState.Paused {
+		p.notifiers.Notify(event.logger, reloadedApp, notify.Event{Type: notify.ReleaseClusterPaused, Tag: event.tag, Release: event.releaseID, Message: "release skipped as releases are paused cluster-wide by " + clusterState.PausedBy + ": " + clusterState.PausedReason})
 		cond.L.Unlock()
-		return
+		p.publishSkipped(event, reloadedApp, "releases are paused cluster-wide: "+clusterState.PausedReason)
+		metrics.Release(reloadedApp.Name, metrics.OutcomeSkipped)
+		event.logger.Warn("releases are paused cluster-wide; skipping", zap.String("appName", reloadedApp.Name), zap.String("pausedBy", clusterState.PausedBy))
+		return nil
 	}
-	p.StartRelease(event, reloadedApp)
+
+	if !event.overrideFreeze {
+		window, freezeErr := p.db.ActiveFreezeWindow(reloadedApp.Name, time.Now())
+		if freezeErr != nil {
+			event.logger.Error("freeze windows could not be checked", zap.Error(freezeErr))
+			report.Error(freezeErr, event.errorScope.WithContext("freeze window check for release"))
+		}
+		if window == nil && freezeErr != nil {
+			p.notifiers.Notify(event.logger, reloadedApp, notify.Event{Type: notify.ReleaseSkipped, Tag: event.tag, Release: event.releaseID, Message: "release skipped as freeze windows could not be checked"})
+			cond.L.Unlock()
+			return freezeErr
+		}
+		if window != nil {
+			message := "release skipped during freeze window " + window.Name
+			if window.Reason != "" {
+				message += ": " + window.Reason
+			}
+			p.notifiers.Notify(event.logger, reloadedApp, notify.Event{Type: notify.ReleaseFrozen, Tag: event.tag, Release: event.releaseID, Message: message})
+			cond.L.Unlock()
+			p.publishSkipped(event, reloadedApp, "freeze window "+window.Name)
+			metrics.Release(reloadedApp.Name, metrics.OutcomeSkipped)
+			event.logger.Warn("deployments are frozen for this app; skipping", zap.String("appName", reloadedApp.Name), zap.String("freezeWindow", window.Name))
+			return nil
+		}
+	}
+
+	err = p.StartRelease(event, reloadedApp)
 	cond.L.Unlock()
 	cond.Signal()
+	return err
 }
 
-func (p Processor) StartRelease(event *Event, app *model.TuberApp) {
+func (p Processor) StartRelease(event *Event, app *model.TuberApp) (err error) {
 	logger := event.logger.With(
 		zap.String("name", app.Name),
 		zap.String("imageTag", app.ImageTag),
@@ -148,27 +272,47 @@ func (p Processor) StartRelease(event *Event, app *model.TuberApp) {
 
 	logger.Info("release starting")
 
+	if event.releaseID == "" {
+		event.releaseID = notify.NewReleaseID(app.Name)
+	}
+
+	ctx, span := tracing.Start(event.ctx, "release", tracing.App(app.Name), tracing.Digest(event.digest), tracing.Tag(event.tag))
+	defer func() { tracing.End(span, err) }()
+
+	_, layerSpan := tracing.Start(ctx, "gcr.tuber_layer")
 	yamls, err := gcr.GetTuberLayer(logger, event.digest, p.creds)
+	tracing.End(layerSpan, err)
 	if err != nil {
-		p.slackClient.Message(logger, ":skull_and_crossbones: image or tuber layer not found for "+app.Name, app.SlackChannel)
+		p.notifiers.Notify(logger, app, notify.Event{Type: notify.ReleaseFailed, Severity: notify.SeverityError, Tag: event.tag, Release: event.releaseID, Message: "image or tuber layer not found"})
+		metrics.Release(app.Name, metrics.OutcomeFailed)
 		logger.Error("failed to find tuber layer", zap.Error(err))
 		report.Error(err, errorScope.WithContext("find tuber layer"))
-		return
+		return err
 	}
 	logger.Debug("current tags detected from gcr digest: " + strings.Join(yamls.Tags, ", ") + " :<-")
 
 	var ti tagInfo
-	if app.GithubRepo != "" {
+	if githubRepo(app, yamls) != "" {
 		var tagErr error
 		ti, tagErr = getTagInfo(app, yamls)
 		if tagErr != nil {
-			logger.Error("error prevented git diffs and release events for a release", zap.Error(err))
+			logger.Error("error prevented git diffs and release events for a release", zap.Error(tagErr))
 			// report.Error(err, errorScope.WithContext("error prevented git diffs and release events for a release"))
 		}
 	}
 
+	changes := p.changes(ctx, logger, ti)
+
+	if app.RequireApproval && !event.approved {
+		return p.requestApproval(logger, errorScope, event, app, changes)
+	}
+
+	notifications := p.notifiers.Release(app, event.releaseID, event.tag, changes)
+	p.trackDeployment(logger, errorScope, app, ti, notifications)
 	startTime := time.Now()
+	p.trackLifecycle(event, app, ti, startTime, notifications)
 	err = core.Release(
+		ctx,
 		p.db,
 		yamls,
 		logger,
@@ -176,110 +320,124 @@ func (p Processor) StartRelease(event *Event, app *model.TuberApp) {
 		app,
 		event.digest,
 		p.ClusterData,
-		p.slackClient,
-		ti.diffText,
+		notifications,
 		p.sentryBearerToken,
 	)
 
 	if err != nil {
 		logger.Warn("release failed", zap.Error(err), zap.Duration("duration", time.Since(startTime)))
-		p.slackClient.Message(logger, "<!here> :loudspeaker: release failed for *"+app.Name+"*\n```"+err.Error()+"```", app.SlackChannel)
-		return
+		metrics.Release(app.Name, metrics.OutcomeFailed)
+		notifications.Notify(logger, notify.Event{
+			Type:     notify.ReleaseFailed,
+			Severity: notify.SeverityError,
+			Message:  "release failed",
+			Detail:   err.Error(),
+			Actions:  []string{notify.ActionRollback, notify.ActionRetry},
+		})
+		return err
 	}
 
-	p.slackClient.Message(logger, ":checkered_flag: *"+app.Name+"*: release complete"+ti.diffText, app.SlackChannel)
+	notifications.Notify(logger, notify.Event{Type: notify.ReleaseSucceeded, Message: "release complete"})
+	metrics.Release(app.Name, metrics.OutcomeSucceeded)
 	logger.Info("release complete", zap.Duration("duration", time.Since(startTime)))
-
-	logger.Debug("completed event taginfo", zap.String("branch", ti.branch), zap.String("newsha", ti.newSHA))
-	if ti.hasEventData() {
-		logger.Info("posting completed event")
-		err = p.postCompleted(app, ti)
-		if err != nil {
-			logger.Error("error prevented sending release completed event", zap.Error(err))
-			// report.Error(err, errorScope.WithContext("error prevented sending release completed event"))
-		}
-	}
+	return nil
 }
 
 type tagInfo struct {
 	branch   string
+	oldSHA   string
 	newSHA   string
-	diffText string
+	repo     string
+	diffLink string
 }
 
-func (t tagInfo) hasEventData() bool {
-	return t.branch != "" && t.newSHA != ""
+// githubRepo prefers the app's configured repo, falling back to the image's source label when it points at github
+func githubRepo(app *model.TuberApp, yamls *gcr.AppYamls) string {
+	if app.GithubRepo != "" {
+		return app.GithubRepo
+	}
+
+	source := strings.TrimSuffix(yamls.Source, ".git")
+	for _, prefix := range []string{"https://github.com/", "http://github.com/", "github.com/"} {
+		if strings.HasPrefix(source, prefix) {
+			return strings.Trim(strings.TrimPrefix(source, prefix), "/")
+		}
+	}
+	return ""
+}
+
+// shaFromTags is the fallback for unlabeled images - whichever tag isn't the branch is assumed to be the commit sha
+func shaFromTags(tags []string, branch string) string {
+	for _, tag := range tags {
+		// if you're pushing more than branch and commit sha, just.. stop that for now, or label your images
+		if branch != tag {
+			return tag
+		}
+	}
+	return ""
 }
 
 func getTagInfo(app *model.TuberApp, yamls *gcr.AppYamls) (tagInfo, error) {
-	branch, err := gcr.TagFromRef(app.ImageTag)
+	tagBranch, err := gcr.TagFromRef(app.ImageTag)
 	if err != nil {
 		return tagInfo{}, err
 	}
 
-	var newSHA string
-	for _, tag := range yamls.Tags {
-		// if you're pushing more than branch and commit sha, just.. stop that for now
-		if branch != tag {
-			newSHA = tag
-			break
-		}
+	branch := yamls.Branch
+	if branch == "" {
+		branch = tagBranch
 	}
 
+	newSHA := yamls.Revision
 	if newSHA == "" {
-		return tagInfo{}, fmt.Errorf("no git sha found in tags of incoming image")
+		newSHA = shaFromTags(yamls.Tags, tagBranch)
 	}
 
-	var oldSHA string
-	for _, tag := range app.CurrentTags {
-		if branch != tag {
-			oldSHA = tag
-			break
-		}
+	if newSHA == "" {
+		return tagInfo{}, fmt.Errorf("no git sha found in labels or tags of incoming image")
 	}
 
-	var diffText string
+	oldSHA := app.CurrentRevision
+	if oldSHA == "" {
+		oldSHA = shaFromTags(app.CurrentTags, tagBranch)
+	}
+
+	repo := githubRepo(app, yamls)
+
+	var diffLink string
 	if oldSHA != "" {
-		diffText = fmt.Sprintf(" - <%s|Compare Diff>", "https://github.com/"+app.GithubRepo+"/compare/"+oldSHA+"..."+newSHA)
+		diffLink = "https://github.com/" + repo + "/compare/" + oldSHA + "..." + newSHA
 	}
 
 	return tagInfo{
 		branch:   branch,
+		oldSHA:   oldSHA,
 		newSHA:   newSHA,
-		diffText: diffText,
+		repo:     repo,
+		diffLink: diffLink,
 	}, nil
 }
 
-type Message struct {
-	AppName   string `json:"appName"`
-	CommitSha string `json:"commitSha"`
-	Repo      string `json:"repo"`
-	Branch    string `json:"branch"`
-}
-
-func (p Processor) postCompleted(app *model.TuberApp, t tagInfo) error {
-	client, err := pubsub.NewClient(p.ctx, p.tuberEventsProject, option.WithCredentialsJSON(p.creds))
-	if err != nil {
-		return err
+// changes lists a release's newest commits from github's compare api, falling back to just the diff link without it
+func (p Processor) changes(ctx context.Context, logger *zap.Logger, ti tagInfo) notify.Changes {
+	changes := notify.Changes{DiffLink: ti.diffLink}
+	if p.githubClient == nil || ti.diffLink == "" {
+		return changes
 	}
 
-	topic := client.Topic(p.tuberEventsTopic)
-
-	msg := Message{
-		AppName:   app.Name,
-		CommitSha: t.newSHA,
-		Repo:      app.GithubRepo,
-		Branch:    t.branch,
-	}
-	marshalled, err := json.Marshal(&msg)
+	_, span := tracing.Start(ctx, "github.compare")
+	comparison, err := p.githubClient.Compare(ti.repo, ti.oldSHA, ti.newSHA)
+	tracing.End(span, err)
 	if err != nil {
-		return err
+		logger.Warn("commits could not be listed from github, linking the diff instead", zap.Error(err))
+		return changes
 	}
 
-	res := topic.Publish(p.ctx, &pubsub.Message{Data: marshalled})
-	_, err = res.Get(p.ctx)
-	if err != nil {
-		return err
+	for i := len(comparison.Commits) - 1; i >= 0 && len(changes.Commits) < maxReleaseCommits; i-- {
+		commit := comparison.Commits[i]
+		changes.Commits = append(changes.Commits, notify.Commit{SHA: commit.SHA, Subject: commit.Subject, Author: commit.Author, URL: commit.URL})
 	}
-	return nil
+	changes.CommitCount = comparison.TotalCommits
+
+	return changes
 }
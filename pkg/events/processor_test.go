@@ -0,0 +1,96 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/gcr"
+	"github.com/freshly/tuber/pkg/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestGetTagInfo(t *testing.T) {
+	testCases := []struct {
+		name     string
+		app      *model.TuberApp
+		yamls    *gcr.AppYamls
+		expected tagInfo
+		errors   bool
+	}{
+		{
+			name:  "two tags",
+			app:   &model.TuberApp{ImageTag: "gcr.io/freshly-docker/potatoes:master", GithubRepo: "freshly/potatoes", CurrentTags: []string{"master", "old"}},
+			yamls: &gcr.AppYamls{Tags: []string{"master", "new"}},
+			expected: tagInfo{
+				branch:   "master",
+				oldSHA:   "old",
+				newSHA:   "new",
+				repo:     "freshly/potatoes",
+				diffLink: "https://github.com/freshly/potatoes/compare/old...new",
+			},
+		},
+		{
+			name:  "labels win over extra tags",
+			app:   &model.TuberApp{ImageTag: "gcr.io/freshly-docker/potatoes:master", GithubRepo: "freshly/potatoes", CurrentTags: []string{"latest", "master"}, CurrentRevision: "old"},
+			yamls: &gcr.AppYamls{Tags: []string{"latest", "master", "new"}, Revision: "new", Branch: "main"},
+			expected: tagInfo{
+				branch:   "main",
+				oldSHA:   "old",
+				newSHA:   "new",
+				repo:     "freshly/potatoes",
+				diffLink: "https://github.com/freshly/potatoes/compare/old...new",
+			},
+		},
+		{
+			name:  "repo from source label",
+			app:   &model.TuberApp{ImageTag: "ghcr.io/freshly/potatoes:master"},
+			yamls: &gcr.AppYamls{Revision: "new", Source: "https://github.com/freshly/potatoes.git"},
+			expected: tagInfo{
+				branch: "master",
+				newSHA: "new",
+				repo:   "freshly/potatoes",
+			},
+		},
+		{
+			name:   "no sha",
+			app:    &model.TuberApp{ImageTag: "gcr.io/freshly-docker/potatoes:master", GithubRepo: "freshly/potatoes"},
+			yamls:  &gcr.AppYamls{Tags: []string{"master"}},
+			errors: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := getTagInfo(tc.app, tc.yamls)
+			if tc.errors {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestReleaseAppKeepsReleaseID(t *testing.T) {
+	database := testDB(t)
+	notifiers := notify.New(nil, nil, nil, nil)
+	processor := NewProcessor(context.Background(), zap.NewNop(), database, nil, nil, false, notifiers, "", nil, time.Hour, nil, "", false)
+	app := &model.TuberApp{Name: "potatoes", Paused: true}
+	require.NoError(t, database.SaveApp(app))
+
+	events, stop := notifiers.Watch("potatoes")
+	defer stop()
+	assert.Equal(t, notify.WatchStarted, (<-events).Type)
+
+	require.NoError(t, processor.ReleaseApp(NewEvent(zap.NewNop(), "", "potatoes:master").WithReleaseID("potatoes-1"), app))
+
+	skipped := <-events
+	assert.Equal(t, notify.ReleaseSkipped, skipped.Type)
+	assert.Equal(t, "potatoes-1", skipped.Release, "whoever started the release should be able to follow it, even when it's skipped")
+}
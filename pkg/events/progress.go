@@ -0,0 +1,53 @@
+package events
+
+import (
+	"context"
+	"strings"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/core"
+	"github.com/freshly/tuber/pkg/notify"
+	"go.uber.org/zap"
+)
+
+// Watch streams an app's release progress as it happens, until stop is called
+func (p Processor) Watch(appName string) (<-chan notify.Event, func()) {
+	return p.notifiers.Watch(appName)
+}
+
+// WatchRollback follows the rollout of a manual rollback, reporting its progress to anyone watching the app.
+// It only runs while someone is watching - rollbacks nobody follows aren't watched at all.
+func (p Processor) WatchRollback(app *model.TuberApp, releaseID string, resources []*model.Resource) {
+	watched, ok := p.notifiers.Watched(app.Name)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithCancel(p.ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-watched.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	logger := p.logger.With(zap.String("appName", app.Name), zap.String("action", "rollback"))
+	notifications := p.notifiers.Release(app, releaseID, app.ImageTag, notify.Changes{})
+
+	errs := core.WatchRollout(ctx, logger, app, resources, notifications)
+	if ctx.Err() != nil {
+		logger.Debug("stopped following rollback, nobody is watching")
+		return
+	}
+	if len(errs) != 0 {
+		var failures []string
+		for _, err := range errs {
+			logger.Warn("rollback rollout failed", zap.Error(err))
+			failures = append(failures, err.Error())
+		}
+		notifications.Progress(notify.Event{Type: notify.RollbackFailed, Severity: notify.SeverityError, Message: "rollback failed to roll out", Detail: strings.Join(failures, "; ")})
+		return
+	}
+	notifications.Progress(notify.Event{Type: notify.RollbackSucceeded, Message: "rollback complete"})
+}
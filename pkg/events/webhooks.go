@@ -0,0 +1,213 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/core"
+	"github.com/freshly/tuber/pkg/notify"
+	"go.uber.org/zap"
+)
+
+// Webhook delivery defaults - 5 attempts backing off from 2s is about 30s of retrying
+const (
+	DefaultWebhookAttempts = 5
+	DefaultWebhookBackoff  = 2 * time.Second
+)
+
+// webhookTrimInterval is how many deliveries an app gets between trims of its delivery log, so the log isn't reloaded on every attempt
+const webhookTrimInterval = 20
+
+// Headers sent with each webhook delivery. The signature is github style, sha256=<hex hmac of the body>.
+const (
+	WebhookEventHeader     = "X-Tuber-Event"
+	WebhookDeliveryHeader  = "X-Tuber-Delivery"
+	WebhookSignatureHeader = "X-Tuber-Signature-256"
+)
+
+// LifecycleEventTypes are the events webhooks can subscribe to
+var LifecycleEventTypes = []string{
+	LifecycleStarted,
+	LifecyclePrereleaseFinished,
+	LifecycleCanaryStarted,
+	LifecycleCanaryDeployed,
+	LifecycleSucceeded,
+	LifecycleFailed,
+	LifecycleRolledBack,
+	LifecycleSkipped,
+}
+
+// Publishers publishes to several publishers, e.g. pubsub and app webhooks
+type Publishers []Publisher
+
+func (p Publishers) Publish(ctx context.Context, event LifecycleEvent) error {
+	var failures []error
+	for _, publisher := range p {
+		err := publisher.Publish(ctx, event)
+		if err != nil {
+			failures = append(failures, err)
+		}
+	}
+	if len(failures) != 0 {
+		return fmt.Errorf("%d of %d publishers failed: %v", len(failures), len(p), failures)
+	}
+	return nil
+}
+
+// WebhookPublisher delivers lifecycle events to the webhooks an app subscribes to.
+// Deliveries happen in the background, retrying with exponential backoff, and are recorded in the app's delivery log.
+// Deliveries to internal addresses are refused unless hosts allows them, and redirects aren't followed.
+type WebhookPublisher struct {
+	ctx      context.Context
+	logger   *zap.Logger
+	db       *core.DB
+	hosts    *notify.WebhookHosts
+	attempts int
+	backoff  time.Duration
+
+	mu        sync.Mutex
+	delivered map[string]int
+}
+
+func NewWebhookPublisher(ctx context.Context, logger *zap.Logger, db *core.DB, hosts *notify.WebhookHosts, attempts int, backoff time.Duration) *WebhookPublisher {
+	return &WebhookPublisher{
+		ctx:       ctx,
+		logger:    logger.With(zap.String("context", "webhooks")),
+		db:        db,
+		hosts:     hosts,
+		attempts:  attempts,
+		backoff:   backoff,
+		delivered: map[string]int{},
+	}
+}
+
+func (w *WebhookPublisher) Publish(ctx context.Context, event LifecycleEvent) error {
+	app, err := w.db.App(event.AppName)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	for _, subscription := range app.Webhooks {
+		if subscription.Wants(event.Type) {
+			go w.deliver(*subscription, event, body)
+		}
+	}
+	return nil
+}
+
+func (w *WebhookPublisher) deliver(subscription model.WebhookSubscription, event LifecycleEvent, body []byte) {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+
+	delivery := &model.WebhookDelivery{
+		ID:        hex.EncodeToString(id),
+		AppName:   event.AppName,
+		URL:       subscription.URL,
+		Event:     event.Type,
+		ReleaseID: event.ReleaseID,
+		Status:    model.WebhookDeliveryPending,
+	}
+	delivery.CreatedAt = time.Now().UTC().Format(delivery.TimestampFormat())
+	logger := w.logger.With(zap.String("appName", delivery.AppName), zap.String("webhookDelivery", delivery.ID), zap.String("lifecycleEvent", event.Type))
+
+	backoff := w.backoff
+	for attempt := 1; ; attempt++ {
+		delivery.Attempts = attempt
+		delivery.StatusCode, delivery.Error = w.post(subscription, delivery.ID, event.Type, body)
+
+		switch {
+		case delivery.Error == "":
+			delivery.Status = model.WebhookDeliverySucceeded
+		case attempt >= w.attempts:
+			delivery.Status = model.WebhookDeliveryFailed
+		}
+		w.save(logger, delivery)
+
+		if delivery.Status != model.WebhookDeliveryPending {
+			logger.Debug("webhook delivery finished", zap.String("status", delivery.Status), zap.Int("attempts", attempt))
+			w.trim(logger, delivery.AppName)
+			return
+		}
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-w.ctx.Done():
+			return
+		}
+	}
+}
+
+// post makes one delivery attempt, returning the response status and any error as a string for the delivery log
+func (w *WebhookPublisher) post(subscription model.WebhookSubscription, deliveryID string, eventType string, body []byte) (int, string) {
+	req, err := http.NewRequestWithContext(w.ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err.Error()
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, eventType)
+	req.Header.Set(WebhookDeliveryHeader, deliveryID)
+	if subscription.Signed() {
+		req.Header.Set(WebhookSignatureHeader, WebhookSignature(subscription.Secret, body))
+	}
+
+	res, err := w.hosts.Client(subscription.URL).Do(req)
+	if err != nil {
+		return 0, err.Error()
+	}
+	res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, "webhook returned " + res.Status
+	}
+	return res.StatusCode, ""
+}
+
+func (w *WebhookPublisher) save(logger *zap.Logger, delivery *model.WebhookDelivery) {
+	delivery.UpdatedAt = time.Now().UTC().Format(delivery.TimestampFormat())
+	err := w.db.SaveWebhookDelivery(delivery)
+	if err != nil {
+		logger.Error("failed to save webhook delivery", zap.Error(err))
+	}
+}
+
+// trim trims an app's delivery log once every webhookTrimInterval finished deliveries
+func (w *WebhookPublisher) trim(logger *zap.Logger, appName string) {
+	w.mu.Lock()
+	w.delivered[appName]++
+	due := w.delivered[appName] >= webhookTrimInterval
+	if due {
+		w.delivered[appName] = 0
+	}
+	w.mu.Unlock()
+
+	if !due {
+		return
+	}
+	err := w.db.TrimWebhookDeliveries(appName)
+	if err != nil {
+		logger.Error("failed to trim webhook deliveries", zap.Error(err))
+	}
+}
+
+// WebhookSignature is what receivers should compare the signature header against, with hmac.Equal
+func WebhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
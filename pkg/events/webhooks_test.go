@@ -0,0 +1,64 @@
+package events
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestWebhookDelivery(t *testing.T) {
+	var requests int32
+	signatures := make(chan bool, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, LifecycleSucceeded, r.Header.Get(WebhookEventHeader))
+		signatures <- r.Header.Get(WebhookSignatureHeader) == WebhookSignature("shh", body)
+
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	database := testDB(t)
+	require.NoError(t, database.SaveApp(&model.TuberApp{
+		Name: "potatoes",
+		Webhooks: []*model.WebhookSubscription{
+			{URL: server.URL, Events: []string{LifecycleSucceeded}, Secret: "shh"},
+			{URL: server.URL + "/failures", Events: []string{LifecycleFailed}},
+		},
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	publisher := NewWebhookPublisher(ctx, zap.NewNop(), database, notify.NewWebhookHosts([]string{"127.0.0.1"}), 3, time.Millisecond)
+	require.NoError(t, publisher.Publish(ctx, LifecycleEvent{Type: LifecycleSucceeded, AppName: "potatoes", ReleaseID: "potatoes-1"}))
+
+	assert.True(t, <-signatures, "first attempt should be signed")
+	assert.True(t, <-signatures, "retry should be signed")
+
+	var deliveries []*model.WebhookDelivery
+	require.Eventually(t, func() bool {
+		var err error
+		deliveries, err = database.WebhookDeliveries("potatoes")
+		require.NoError(t, err)
+		return len(deliveries) == 1 && deliveries[0].Status != model.WebhookDeliveryPending
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, model.WebhookDeliverySucceeded, deliveries[0].Status)
+	assert.Equal(t, 2, deliveries[0].Attempts)
+	assert.Equal(t, http.StatusOK, deliveries[0].StatusCode)
+	assert.Equal(t, "potatoes-1", deliveries[0].ReleaseID)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests), "the release.failed subscription shouldn't have been sent a release.succeeded event")
+}
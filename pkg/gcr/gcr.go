@@ -8,34 +8,19 @@ import (
 	"strings"
 	"time"
 
-	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
-	"github.com/google/go-containerregistry/pkg/v1/google"
-	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"go.uber.org/zap"
 )
 
 const megabyte = 1_000_000
 const maxSize = megabyte * 1
 
-func DigestFromTag(tag string, creds []byte) (string, error) {
-	ref, err := name.ParseReference(tag)
-	if err != nil {
-		return "", err
-	}
-
-	img, err := remote.Image(ref, remote.WithAuth(google.NewJSONKeyAuthenticator(string(creds))))
-	if err != nil {
-		return "", err
-	}
-
-	digest, err := img.Digest()
-	if err != nil {
-		return "", err
-	}
+var tagRetryInterval = 5 * time.Second
 
-	return ref.Context().Digest(digest.String()).String(), nil
+// DigestFromTag resolves a tag to a full digest reference, authenticating with the default registry keychain
+func DigestFromTag(tag string, creds []byte) (string, error) {
+	return DefaultRegistry(creds).DigestFromTag(tag)
 }
 
 func SwapTags(imageTag string, tag string) (string, error) {
@@ -61,23 +46,37 @@ func TagFromRef(imageTag string) (string, error) {
 	return ref.Identifier(), nil
 }
 
+// Image config labels tuber reads release metadata from, when present.
+// The revision and source labels are the standard OCI annotations most build tooling already sets.
+const (
+	RevisionLabel = "org.opencontainers.image.revision"
+	SourceLabel   = "org.opencontainers.image.source"
+	BranchLabel   = "tuber/branch"
+)
+
 type AppYamls struct {
 	Prerelease  []string
 	Release     []string
 	PostRelease []string
 	Tags        []string
+	Revision    string
+	Source      string
+	Branch      string
 }
 
-// GetTuberLayer downloads yamls for an image
+// GetTuberLayer downloads yamls for an image, authenticating with the default registry keychain
 func GetTuberLayer(logger *zap.Logger, tagOrDigest string, creds []byte) (*AppYamls, error) {
+	return DefaultRegistry(creds).GetTuberLayer(logger, tagOrDigest)
+}
+
+// GetTuberLayer downloads yamls for an image
+func (r *Registry) GetTuberLayer(logger *zap.Logger, tagOrDigest string) (*AppYamls, error) {
 	ref, err := name.ParseReference(tagOrDigest)
 	if err != nil {
 		return nil, err
 	}
 
-	auth := google.NewJSONKeyAuthenticator(string(creds))
-
-	img, err := remote.Image(ref, remote.WithAuth(auth))
+	img, err := r.Image(ref)
 	if err != nil {
 		return nil, err
 	}
@@ -90,7 +89,31 @@ func GetTuberLayer(logger *zap.Logger, tagOrDigest string, creds []byte) (*AppYa
 		return nil, err
 	}
 
-	tags, err := getTwoTags(logger, ref.Context(), auth, img)
+	config, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	labels := config.Config.Labels
+	yamls.Revision = labels[RevisionLabel]
+	yamls.Source = labels[SourceLabel]
+	yamls.Branch = labels[BranchLabel]
+
+	// labeled images don't need tags to identify their commit, so there's nothing worth waiting on,
+	// and only the tags their labels suggest need resolving
+	if yamls.Revision != "" {
+		digest, digestErr := img.Digest()
+		if digestErr != nil {
+			return nil, digestErr
+		}
+		yamls.Tags, err = r.TagsForDigest(ref.Context(), digest, labeledTags(ref, yamls)...)
+		if err != nil {
+			return nil, err
+		}
+		return yamls, nil
+	}
+
+	tags, err := r.getTwoTags(logger, ref.Context(), img)
 	if err != nil {
 		return nil, err
 	}
@@ -100,29 +123,50 @@ func GetTuberLayer(logger *zap.Logger, tagOrDigest string, creds []byte) (*AppYa
 	return yamls, nil
 }
 
-func getTwoTags(logger *zap.Logger, repository name.Repository, auth authn.Authenticator, img v1.Image) ([]string, error) {
+// labeledTags are the tags a labeled image is likely pushed with - the tag it was referenced by, its commit, and its branch
+func labeledTags(ref name.Reference, yamls *AppYamls) []string {
 	var tags []string
-	for i := 1; i <= 3; i++ {
-		repoImages, err := google.List(repository, google.WithAuth(auth))
-		if err != nil {
-			return nil, err
-		}
-		if repoImages == nil {
-			return nil, fmt.Errorf("no repo images found")
-		}
+	if tag, ok := ref.(name.Tag); ok {
+		tags = append(tags, tag.TagStr())
+	}
+	tags = append(tags, yamls.Revision)
+	if len(yamls.Revision) > 7 {
+		tags = append(tags, yamls.Revision[:7])
+	}
+	if yamls.Branch != "" {
+		tags = append(tags, yamls.Branch, strings.ReplaceAll(yamls.Branch, "/", "-"))
+	}
+	return tags
+}
+
+// getTwoTags waits for an unlabeled image's branch and commit tags, which gcr can add a little after the push.
+// Elsewhere, finding tags means resolving every tag in the repository, so it's only done once.
+func (r *Registry) getTwoTags(logger *zap.Logger, repository name.Repository, img v1.Image) ([]string, error) {
+	digest, err := img.Digest()
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := 1
+	if isGoogleHost(repository.RegistryStr()) {
+		attempts = 3
+	}
 
-		digest, err := img.Digest()
+	var tags []string
+	for i := 1; i <= attempts; i++ {
+		tags, err = r.TagsForDigest(repository, digest)
 		if err != nil {
 			return nil, err
 		}
 
-		tags = repoImages.Manifests[digest.String()].Tags
 		if len(tags) == 2 {
 			logger.Debug("get two tags attempt " + fmt.Sprintf("%d", i) + " nailed it")
 			return tags, nil
 		}
 		logger.Debug("get two tags attempt " + fmt.Sprintf("%d", i) + " had " + strings.Join(tags, ", ") + " <- yeah those")
-		time.Sleep(5 * time.Second)
+		if i < attempts {
+			time.Sleep(tagRetryInterval)
+		}
 	}
 	return tags, nil
 }
@@ -0,0 +1,147 @@
+package gcr
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/google"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+// Registry reads images from any OCI distribution registry - GCR, Artifact Registry, Docker Hub, GHCR, or self hosted.
+// Credentials are resolved per registry host through its keychain.
+type Registry struct {
+	keychain authn.Keychain
+	options  []remote.Option
+}
+
+// NewRegistry constructs a Registry resolving credentials from the given keychain.
+// Additional remote options (transports, mostly) are passed along to every registry call.
+func NewRegistry(keychain authn.Keychain, options ...remote.Option) *Registry {
+	if keychain == nil {
+		keychain = authn.DefaultKeychain
+	}
+	return &Registry{
+		keychain: keychain,
+		options:  options,
+	}
+}
+
+// DefaultRegistry authenticates Google hosted registries with tuber's service account credentials,
+// and everything else with basic or token auth from the docker config keychain (respects DOCKER_CONFIG).
+func DefaultRegistry(creds []byte) *Registry {
+	return NewRegistry(authn.NewMultiKeychain(googleKeychain{creds: creds}, authn.DefaultKeychain))
+}
+
+type googleKeychain struct {
+	creds []byte
+}
+
+// Resolve only answers for GCR and Artifact Registry hosts, so the multi keychain falls through for the rest
+func (k googleKeychain) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	if len(k.creds) == 0 || !isGoogleHost(target.RegistryStr()) {
+		return authn.Anonymous, nil
+	}
+	return google.NewJSONKeyAuthenticator(string(k.creds)), nil
+}
+
+func isGoogleHost(host string) bool {
+	return host == "gcr.io" || strings.HasSuffix(host, ".gcr.io") || strings.HasSuffix(host, "-docker.pkg.dev")
+}
+
+func (r *Registry) remoteOptions() []remote.Option {
+	return append([]remote.Option{remote.WithAuthFromKeychain(r.keychain)}, r.options...)
+}
+
+// Image pulls the image for a tag or digest reference
+func (r *Registry) Image(ref name.Reference) (v1.Image, error) {
+	return remote.Image(ref, r.remoteOptions()...)
+}
+
+// DigestFromTag resolves a tag reference to a full digest reference
+func (r *Registry) DigestFromTag(tag string) (string, error) {
+	ref, err := name.ParseReference(tag)
+	if err != nil {
+		return "", err
+	}
+
+	desc, err := remote.Head(ref, r.remoteOptions()...)
+	if err != nil {
+		return "", err
+	}
+
+	return ref.Context().Digest(desc.Digest.String()).String(), nil
+}
+
+// Tags lists every tag in a repository through the standard distribution tags list api
+func (r *Registry) Tags(repository name.Repository) ([]string, error) {
+	return remote.List(repository, r.remoteOptions()...)
+}
+
+// TagsForDigest returns the tags in a repository currently pointing at a digest. Google hosted registries list every tag's digest in one call.
+// Other registries resolve each tag separately, so only the candidate tags are resolved when there are any, and every tag when there aren't.
+func (r *Registry) TagsForDigest(repository name.Repository, digest v1.Hash, candidates ...string) ([]string, error) {
+	if isGoogleHost(repository.RegistryStr()) {
+		tags, listed, err := r.googleTagsForDigest(repository, digest)
+		if err != nil {
+			return nil, err
+		}
+		if listed {
+			return tags, nil
+		}
+	}
+
+	tags := candidates
+	if len(tags) == 0 {
+		var err error
+		tags, err = r.Tags(repository)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var matches []string
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+
+		desc, err := remote.Head(repository.Tag(tag), r.remoteOptions()...)
+		if err != nil {
+			if isNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("resolving tag %s: %v", tag, err)
+		}
+		if desc.Digest == digest {
+			matches = append(matches, tag)
+		}
+	}
+
+	return matches, nil
+}
+
+// googleTagsForDigest reads a digest's tags from the manifest map google's tags list includes. listed is false if the registry left it out.
+func (r *Registry) googleTagsForDigest(repository name.Repository, digest v1.Hash) (tags []string, listed bool, err error) {
+	list, err := google.List(repository, google.WithAuthFromKeychain(r.keychain))
+	if err != nil {
+		return nil, false, err
+	}
+	if list.Manifests == nil {
+		return nil, false, nil
+	}
+	return list.Manifests[digest.String()].Tags, true, nil
+}
+
+// a tag can be deleted between listing and resolving it
+func isNotFound(err error) bool {
+	transportErr, ok := err.(*transport.Error)
+	return ok && transportErr.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,149 @@
+package gcr
+
+import (
+	"archive/tar"
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func tuberImage(t *testing.T, files map[string]string) v1.Image {
+	var buf bytes.Buffer
+	archive := tar.NewWriter(&buf)
+	for fileName, contents := range files {
+		require.NoError(t, archive.WriteHeader(&tar.Header{Name: fileName, Mode: 0644, Size: int64(len(contents))}))
+		_, err := archive.Write([]byte(contents))
+		require.NoError(t, err)
+	}
+	require.NoError(t, archive.Close())
+
+	layer, err := tarball.LayerFromReader(&buf)
+	require.NoError(t, err)
+
+	img, err := mutate.AppendLayers(empty.Image, layer)
+	require.NoError(t, err)
+	return img
+}
+
+func pushImage(t *testing.T, img v1.Image, refs ...string) {
+	for _, r := range refs {
+		ref, err := name.ParseReference(r)
+		require.NoError(t, err)
+		require.NoError(t, remote.Write(ref, img))
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+
+	repo := strings.TrimPrefix(server.URL, "http://") + "/freshly/potatoes"
+
+	img := tuberImage(t, map[string]string{
+		".tuber/deployment.yaml":          "kind: Deployment",
+		".tuber/prerelease/migrate.yaml":  "kind: Pod",
+		".tuber/postrelease/canary.yaml":  "kind: Deployment",
+		"app/not-tuber/deployment.yaml":   "kind: Nope",
+		".tuber/not-a-yaml-at-all.txt":    "nope",
+		".tuber/subdir/configmap.yaml":    "kind: ConfigMap",
+		".tuberish/anything/deploy.yaml":  "kind: Nope",
+		".tuber/postrelease/service.yaml": "kind: Service",
+	})
+	pushImage(t, img, repo+":master", repo+":abc123")
+
+	other := tuberImage(t, map[string]string{".tuber/deployment.yaml": "kind: Other"})
+	pushImage(t, other, repo+":some-other-branch")
+
+	digest, err := img.Digest()
+	require.NoError(t, err)
+
+	r := NewRegistry(authn.NewMultiKeychain(googleKeychain{}, authn.DefaultKeychain))
+
+	t.Run("digest from tag", func(t *testing.T) {
+		resolved, err := r.DigestFromTag(repo + ":master")
+		require.NoError(t, err)
+		assert.Equal(t, repo+"@"+digest.String(), resolved)
+	})
+
+	t.Run("tags for digest", func(t *testing.T) {
+		repository, err := name.NewRepository(repo)
+		require.NoError(t, err)
+
+		tags, err := r.TagsForDigest(repository, digest)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"master", "abc123"}, tags)
+
+		tags, err = r.TagsForDigest(repository, digest, "abc123", "some-other-branch", "missing")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"abc123"}, tags, "only candidates are resolved")
+	})
+
+	t.Run("tuber layer", func(t *testing.T) {
+		yamls, err := r.GetTuberLayer(zap.NewNop(), repo+"@"+digest.String())
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"kind: Deployment", "kind: ConfigMap"}, yamls.Release)
+		assert.ElementsMatch(t, []string{"kind: Pod"}, yamls.Prerelease)
+		assert.ElementsMatch(t, []string{"kind: Deployment", "kind: Service"}, yamls.PostRelease)
+		assert.ElementsMatch(t, []string{"master", "abc123"}, yamls.Tags)
+	})
+
+	t.Run("labeled image", func(t *testing.T) {
+		labeled, err := mutate.Config(tuberImage(t, map[string]string{".tuber/deployment.yaml": "kind: Labeled"}), v1.Config{
+			Labels: map[string]string{
+				RevisionLabel: "def456",
+				SourceLabel:   "https://github.com/freshly/potatoes",
+				BranchLabel:   "feature/labels",
+			},
+		})
+		require.NoError(t, err)
+		pushImage(t, labeled, repo+":feature-labels", repo+":def456", repo+":latest")
+
+		labeledDigest, err := labeled.Digest()
+		require.NoError(t, err)
+
+		yamls, err := r.GetTuberLayer(zap.NewNop(), repo+"@"+labeledDigest.String())
+		require.NoError(t, err)
+		assert.Equal(t, "def456", yamls.Revision)
+		assert.Equal(t, "https://github.com/freshly/potatoes", yamls.Source)
+		assert.Equal(t, "feature/labels", yamls.Branch)
+		assert.ElementsMatch(t, []string{"feature-labels", "def456"}, yamls.Tags, "only the tags its labels suggest are resolved")
+	})
+
+	t.Run("missing tag", func(t *testing.T) {
+		_, err := r.DigestFromTag(repo + ":nope")
+		assert.Error(t, err)
+	})
+}
+
+func TestIsGoogleHost(t *testing.T) {
+	testCases := []struct {
+		host     string
+		expected bool
+	}{
+		{host: "gcr.io", expected: true},
+		{host: "us.gcr.io", expected: true},
+		{host: "us-central1-docker.pkg.dev", expected: true},
+		{host: "index.docker.io", expected: false},
+		{host: "ghcr.io", expected: false},
+		{host: "registry.example.com", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.host, func(t *testing.T) {
+			assert.Equal(t, tc.expected, isGoogleHost(tc.host))
+		})
+	}
+}
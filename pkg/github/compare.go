@@ -0,0 +1,60 @@
+package github
+
+import (
+	"strings"
+)
+
+// Commit is a summary of one commit in a comparison
+type Commit struct {
+	SHA     string
+	Subject string
+	Author  string
+	URL     string
+}
+
+// Comparison is the commits between two refs, oldest first. Github returns at most 250 of them, TotalCommits is the real count.
+type Comparison struct {
+	Commits      []Commit
+	TotalCommits int
+}
+
+type compareResponse struct {
+	TotalCommits int `json:"total_commits"`
+	Commits      []struct {
+		SHA     string `json:"sha"`
+		HTMLURL string `json:"html_url"`
+		Commit  struct {
+			Message string `json:"message"`
+			Author  struct {
+				Name string `json:"name"`
+			} `json:"author"`
+		} `json:"commit"`
+		Author *struct {
+			Login string `json:"login"`
+		} `json:"author"`
+	} `json:"commits"`
+}
+
+// Compare lists the commits in head that aren't in base. Authors are github logins where the commit is linked to a user, otherwise git author names.
+func (c *Client) Compare(repo string, base string, head string) (*Comparison, error) {
+	var compared compareResponse
+	err := c.do("GET", "/repos/"+repo+"/compare/"+base+"..."+head, nil, &compared)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := &Comparison{TotalCommits: compared.TotalCommits}
+	for _, commit := range compared.Commits {
+		author := commit.Commit.Author.Name
+		if commit.Author != nil && commit.Author.Login != "" {
+			author = commit.Author.Login
+		}
+		comparison.Commits = append(comparison.Commits, Commit{
+			SHA:     commit.SHA,
+			Subject: strings.SplitN(commit.Commit.Message, "\n", 2)[0],
+			Author:  author,
+			URL:     commit.HTMLURL,
+		})
+	}
+	return comparison, nil
+}
@@ -0,0 +1,34 @@
+package github
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/freshly/potatoes/compare/old...new", r.URL.Path)
+		_, _ = w.Write([]byte(`{
+			"total_commits": 300,
+			"commits": [
+				{"sha": "aaa", "html_url": "https://github.com/freshly/potatoes/commit/aaa", "commit": {"message": "mash them\n\nproperly this time", "author": {"name": "Jane Doe"}}, "author": {"login": "jdoe"}},
+				{"sha": "bbb", "html_url": "https://github.com/freshly/potatoes/commit/bbb", "commit": {"message": "boil them", "author": {"name": "Someone Unlinked"}}, "author": null}
+			]
+		}`))
+	}))
+	defer server.Close()
+
+	comparison, err := New(server.URL, "secret").Compare("freshly/potatoes", "old", "new")
+	require.NoError(t, err)
+	assert.Equal(t, &Comparison{
+		TotalCommits: 300,
+		Commits: []Commit{
+			{SHA: "aaa", Subject: "mash them", Author: "jdoe", URL: "https://github.com/freshly/potatoes/commit/aaa"},
+			{SHA: "bbb", Subject: "boil them", Author: "Someone Unlinked", URL: "https://github.com/freshly/potatoes/commit/bbb"},
+		},
+	}, comparison)
+}
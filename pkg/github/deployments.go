@@ -0,0 +1,124 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Deployment states tuber moves a deployment through
+const (
+	StateInProgress = "in_progress"
+	StateSuccess    = "success"
+	StateFailure    = "failure"
+	StateInactive   = "inactive"
+)
+
+// Deployment is a github deployment of one app's release
+type Deployment struct {
+	client         *Client
+	Repo           string
+	ID             int64
+	App            string
+	Environment    string
+	EnvironmentURL string
+}
+
+type deploymentPayload struct {
+	App string `json:"app"`
+}
+
+type deploymentRequest struct {
+	Ref                   string            `json:"ref"`
+	Environment           string            `json:"environment"`
+	Description           string            `json:"description"`
+	AutoMerge             bool              `json:"auto_merge"`
+	RequiredContexts      []string          `json:"required_contexts"`
+	TransientEnvironment  bool              `json:"transient_environment"`
+	ProductionEnvironment bool              `json:"production_environment"`
+	Payload               deploymentPayload `json:"payload"`
+}
+
+// payloads are whatever whoever created the deployment sent, not necessarily an object
+type deploymentResponse struct {
+	ID      int64           `json:"id"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+func (d deploymentResponse) app() string {
+	var payload deploymentPayload
+	_ = json.Unmarshal(d.Payload, &payload)
+	return payload.App
+}
+
+type statusRequest struct {
+	State          string `json:"state"`
+	Description    string `json:"description,omitempty"`
+	Environment    string `json:"environment,omitempty"`
+	EnvironmentURL string `json:"environment_url,omitempty"`
+	AutoInactive   bool   `json:"auto_inactive"`
+}
+
+// CreateDeployment starts a deployment of a commit to an environment. Commit statuses aren't required,
+// tuber only deploys what's already been built.
+func (c *Client) CreateDeployment(repo string, ref string, environment string, environmentURL string, app string, transient bool, production bool) (*Deployment, error) {
+	var created deploymentResponse
+	err := c.do("POST", "/repos/"+repo+"/deployments", deploymentRequest{
+		Ref:                   ref,
+		Environment:           environment,
+		Description:           "tuber release of " + app,
+		RequiredContexts:      []string{},
+		TransientEnvironment:  transient,
+		ProductionEnvironment: production,
+		Payload:               deploymentPayload{App: app},
+	}, &created)
+	if err != nil {
+		return nil, err
+	}
+	if created.ID == 0 {
+		return nil, fmt.Errorf("github did not create a deployment for %s", ref)
+	}
+
+	return &Deployment{
+		client:         c,
+		Repo:           repo,
+		ID:             created.ID,
+		App:            app,
+		Environment:    environment,
+		EnvironmentURL: environmentURL,
+	}, nil
+}
+
+// SetStatus adds a status to the deployment. Inactivating older deployments is left to InactivatePrevious,
+// since github's own auto-inactivation can't tell apart apps that share a repo and environment.
+func (d *Deployment) SetStatus(state string, description string) error {
+	if utf8.RuneCountInString(description) > 140 {
+		description = string([]rune(description)[:137]) + "..."
+	}
+	return d.client.do("POST", "/repos/"+d.Repo+"/deployments/"+strconv.FormatInt(d.ID, 10)+"/statuses", statusRequest{
+		State:          state,
+		Description:    description,
+		Environment:    d.Environment,
+		EnvironmentURL: d.EnvironmentURL,
+	}, nil)
+}
+
+// InactivatePrevious marks the most recent earlier deployment of the same app to the same environment inactive
+func (d *Deployment) InactivatePrevious() error {
+	var deployments []deploymentResponse
+	err := d.client.do("GET", "/repos/"+d.Repo+"/deployments?environment="+url.QueryEscape(d.Environment)+"&per_page=30", nil, &deployments)
+	if err != nil {
+		return err
+	}
+
+	for _, deployment := range deployments {
+		if deployment.ID == d.ID || deployment.app() != d.App {
+			continue
+		}
+		previous := Deployment{client: d.client, Repo: d.Repo, ID: deployment.ID, Environment: d.Environment}
+		return previous.SetStatus(StateInactive, "superseded by a newer release")
+	}
+	return nil
+}
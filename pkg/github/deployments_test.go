@@ -0,0 +1,109 @@
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedStatus struct {
+	deployment string
+	status     statusRequest
+}
+
+func githubStandIn(t *testing.T) (*httptest.Server, *[]recordedStatus, *deploymentRequest) {
+	var mutex sync.Mutex
+	var statuses []recordedStatus
+	var created deploymentRequest
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/freshly/potatoes/deployments", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token secret", r.Header.Get("Authorization"))
+		switch r.Method {
+		case http.MethodPost:
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id": 3, "payload": {"app": "potatoes"}}`))
+		case http.MethodGet:
+			assert.Equal(t, "production", r.URL.Query().Get("environment"))
+			_, _ = w.Write([]byte(`[
+				{"id": 3, "payload": {"app": "potatoes"}},
+				{"id": 2, "payload": {"app": "potatoes-worker"}},
+				{"id": 1, "payload": {"app": "potatoes"}},
+				{"id": 0, "payload": ""}
+			]`))
+		}
+	})
+	for _, id := range []string{"1", "3"} {
+		id := id
+		mux.HandleFunc("/repos/freshly/potatoes/deployments/"+id+"/statuses", func(w http.ResponseWriter, r *http.Request) {
+			var status statusRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&status))
+			mutex.Lock()
+			statuses = append(statuses, recordedStatus{deployment: id, status: status})
+			mutex.Unlock()
+			w.WriteHeader(http.StatusCreated)
+		})
+	}
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &statuses, &created
+}
+
+func TestDeployment(t *testing.T) {
+	server, statuses, created := githubStandIn(t)
+	client := New(server.URL+"/", "secret")
+
+	deployment, err := client.CreateDeployment("freshly/potatoes", "abc123", "production", "https://potatoes.example.com", "potatoes", false, true)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), deployment.ID)
+	assert.Equal(t, "abc123", created.Ref)
+	assert.Equal(t, "production", created.Environment)
+	assert.Equal(t, "potatoes", created.Payload.App)
+	assert.True(t, created.ProductionEnvironment)
+
+	require.NoError(t, deployment.SetStatus(StateInProgress, "release starting"))
+	require.NoError(t, deployment.SetStatus(StateSuccess, "release complete"))
+	require.NoError(t, deployment.InactivatePrevious())
+
+	require.Len(t, *statuses, 3)
+	assert.Equal(t, recordedStatus{deployment: "3", status: statusRequest{State: StateInProgress, Description: "release starting", Environment: "production", EnvironmentURL: "https://potatoes.example.com"}}, (*statuses)[0])
+	assert.Equal(t, StateSuccess, (*statuses)[1].status.State)
+	assert.Equal(t, "1", (*statuses)[2].deployment, "the previous deployment of the same app should be inactivated, not another app's")
+	assert.Equal(t, StateInactive, (*statuses)[2].status.State)
+}
+
+func TestAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"message": "Conflict: Commit status checks failed for master."}`))
+	}))
+	defer server.Close()
+
+	_, err := New(server.URL, "secret").CreateDeployment("freshly/potatoes", "master", "production", "", "potatoes", false, true)
+	require.Error(t, err)
+	assert.Equal(t, APIError{StatusCode: http.StatusConflict, Message: "Conflict: Commit status checks failed for master."}, err)
+}
+
+func TestDeploymentDescriptions(t *testing.T) {
+	server, statuses, created := githubStandIn(t)
+	client := New(server.URL, "secret")
+
+	deployment, err := client.CreateDeployment("freshly/potatoes", "abc123", "production", "", "potatoes", false, false)
+	require.NoError(t, err)
+	assert.False(t, created.ProductionEnvironment, "only configured environments are production ones")
+
+	require.NoError(t, deployment.SetStatus(StateFailure, strings.Repeat("🥔", 200)))
+	require.Len(t, *statuses, 1)
+	description := (*statuses)[0].status.Description
+	assert.True(t, utf8.ValidString(description), "descriptions should be cut between characters, not bytes")
+	assert.Equal(t, 140, utf8.RuneCountInString(description))
+}
@@ -0,0 +1,149 @@
+package k8s
+
+import (
+	"context"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// subresources are the parts of a resource kubectl auth can-i style subjects name after a slash, instead of a resource name
+var subresources = map[string]bool{"log": true, "exec": true, "attach": true, "portforward": true, "status": true, "scale": true}
+
+// appsGroupResources are the resources tuber checks that are in the apps api group, rather than the core one
+var appsGroupResources = map[string]bool{"deployments": true, "statefulsets": true, "daemonsets": true, "replicasets": true}
+
+// ResourceAttributes is what an access review asks about - a verb on a kind of resource in a namespace, or on a specific one or its subresource.
+// An empty namespace asks about every namespace.
+type ResourceAttributes struct {
+	Namespace   string `json:"namespace,omitempty"`
+	Verb        string `json:"verb"`
+	Group       string `json:"group,omitempty"`
+	Resource    string `json:"resource"`
+	Subresource string `json:"subresource,omitempty"`
+	Name        string `json:"name,omitempty"`
+}
+
+// ParseResourceAttributes reads a kubectl auth can-i style subject, like deployments, secret/name, or pods/log
+func ParseResourceAttributes(namespace string, verb string, subject string) ResourceAttributes {
+	attributes := ResourceAttributes{Namespace: namespace, Verb: verb}
+
+	parts := strings.SplitN(subject, "/", 2)
+	attributes.Resource = parts[0]
+	if attributes.Resource != "*" && !strings.HasSuffix(attributes.Resource, "s") {
+		attributes.Resource += "s"
+	}
+	if len(parts) == 2 {
+		if subresources[parts[1]] {
+			attributes.Subresource = parts[1]
+		} else {
+			attributes.Name = parts[1]
+		}
+	}
+
+	switch {
+	case attributes.Resource == "*":
+		attributes.Group = "*"
+	case appsGroupResources[attributes.Resource]:
+		attributes.Group = "apps"
+	}
+	return attributes
+}
+
+// ResourceRule is a rule from a SelfSubjectRulesReview
+type ResourceRule struct {
+	Verbs         []string `json:"verbs"`
+	APIGroups     []string `json:"apiGroups"`
+	Resources     []string `json:"resources"`
+	ResourceNames []string `json:"resourceNames"`
+}
+
+// Rules are everything a caller can do in a namespace. Incomplete rules leave out what some authorizers allow,
+// so anything they don't allow needs an access review to be sure.
+type Rules struct {
+	ResourceRules []ResourceRule `json:"resourceRules"`
+	Incomplete    bool           `json:"incomplete"`
+}
+
+// Allows is true when a rule allows the attributes, matching the way rbac does
+func (r Rules) Allows(attributes ResourceAttributes) bool {
+	resource := attributes.Resource
+	if attributes.Subresource != "" {
+		resource += "/" + attributes.Subresource
+	}
+
+	for _, rule := range r.ResourceRules {
+		if !matches(rule.Verbs, attributes.Verb) || !matches(rule.APIGroups, attributes.Group) {
+			continue
+		}
+		if len(rule.ResourceNames) != 0 && (attributes.Name == "" || !matches(rule.ResourceNames, attributes.Name)) {
+			continue
+		}
+		for _, ruleResource := range rule.Resources {
+			if ruleResource == "*" || ruleResource == resource ||
+				(attributes.Subresource != "" && (ruleResource == attributes.Resource+"/*" || ruleResource == "*/"+attributes.Subresource)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matches(values []string, value string) bool {
+	for _, v := range values {
+		if v == "*" || v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// AccessReview asks the api server whether the caller can do something, with a SelfSubjectAccessReview
+func AccessReview(ctx context.Context, attributes ResourceAttributes, caller Caller) (bool, error) {
+	client, err := caller.client()
+	if err != nil {
+		return false, err
+	}
+
+	review, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &authorizationv1.ResourceAttributes{
+			Namespace:   attributes.Namespace,
+			Verb:        attributes.Verb,
+			Group:       attributes.Group,
+			Resource:    attributes.Resource,
+			Subresource: attributes.Subresource,
+			Name:        attributes.Name,
+		}},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+	return review.Status.Allowed, nil
+}
+
+// RulesReview gets everything the caller can do in a namespace in one request, with a SelfSubjectRulesReview
+func RulesReview(ctx context.Context, namespace string, caller Caller) (*Rules, error) {
+	client, err := caller.client()
+	if err != nil {
+		return nil, err
+	}
+
+	review, err := client.AuthorizationV1().SelfSubjectRulesReviews().Create(ctx, &authorizationv1.SelfSubjectRulesReview{
+		Spec: authorizationv1.SelfSubjectRulesReviewSpec{Namespace: namespace},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	rules := &Rules{Incomplete: review.Status.Incomplete}
+	for _, rule := range review.Status.ResourceRules {
+		rules.ResourceRules = append(rules.ResourceRules, ResourceRule{
+			Verbs:         rule.Verbs,
+			APIGroups:     rule.APIGroups,
+			Resources:     rule.Resources,
+			ResourceNames: rule.ResourceNames,
+		})
+	}
+	return rules, nil
+}
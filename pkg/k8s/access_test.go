@@ -0,0 +1,33 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseResourceAttributes(t *testing.T) {
+	assert.Equal(t, ResourceAttributes{Namespace: "potatoes", Verb: "update", Group: "apps", Resource: "deployments"}, ParseResourceAttributes("potatoes", "update", "deployments"))
+	assert.Equal(t, ResourceAttributes{Namespace: "potatoes", Verb: "get", Resource: "secrets", Name: "potatoes-env"}, ParseResourceAttributes("potatoes", "get", "secret/potatoes-env"))
+	assert.Equal(t, ResourceAttributes{Namespace: "potatoes", Verb: "get", Resource: "pods", Subresource: "log"}, ParseResourceAttributes("potatoes", "get", "pods/log"))
+	assert.Equal(t, ResourceAttributes{Verb: "*", Group: "*", Resource: "*"}, ParseResourceAttributes("", "*", "*"))
+}
+
+func TestRulesAllows(t *testing.T) {
+	rules := Rules{ResourceRules: []ResourceRule{
+		{Verbs: []string{"get", "list"}, APIGroups: []string{"apps"}, Resources: []string{"deployments"}},
+		{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"secrets"}, ResourceNames: []string{"potatoes-env"}},
+		{Verbs: []string{"*"}, APIGroups: []string{""}, Resources: []string{"pods/*"}},
+	}}
+
+	assert.True(t, rules.Allows(ParseResourceAttributes("potatoes", "get", "deployments")))
+	assert.False(t, rules.Allows(ParseResourceAttributes("potatoes", "update", "deployments")))
+	assert.True(t, rules.Allows(ParseResourceAttributes("potatoes", "get", "secret/potatoes-env")))
+	assert.False(t, rules.Allows(ParseResourceAttributes("potatoes", "get", "secret/other-secret")))
+	assert.True(t, rules.Allows(ParseResourceAttributes("potatoes", "get", "pods/log")))
+	assert.False(t, rules.Allows(ParseResourceAttributes("potatoes", "get", "pods")), "pods/* is only subresources")
+	assert.False(t, rules.Allows(ParseResourceAttributes("potatoes", "*", "*")))
+
+	admin := Rules{ResourceRules: []ResourceRule{{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}}}}
+	assert.True(t, admin.Allows(ParseResourceAttributes("potatoes", "*", "*")))
+}
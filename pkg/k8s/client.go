@@ -0,0 +1,54 @@
+package k8s
+
+import (
+	"sync"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Caller is who an api request is made as - someone's access token, or an identity to impersonate when they don't have one
+type Caller struct {
+	Token       string
+	Impersonate string
+}
+
+var baseConfig struct {
+	sync.Once
+	config *rest.Config
+	err    error
+}
+
+// restConfig is tuber's own connection to the cluster - its service account in the cluster, or the current kubeconfig context outside it
+func restConfig() (*rest.Config, error) {
+	baseConfig.Do(func() {
+		baseConfig.config, baseConfig.err = rest.InClusterConfig()
+		if baseConfig.err == rest.ErrNotInCluster {
+			loader := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{})
+			baseConfig.config, baseConfig.err = loader.ClientConfig()
+		}
+	})
+	return baseConfig.config, baseConfig.err
+}
+
+// client connects to the cluster as the caller, in place of tuber's own credentials
+func (c Caller) client() (kubernetes.Interface, error) {
+	base, err := restConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	config := rest.CopyConfig(base)
+	if c.Impersonate != "" {
+		config.Impersonate = rest.ImpersonationConfig{UserName: c.Impersonate}
+	} else {
+		config.BearerToken = c.Token
+		config.BearerTokenFile = ""
+		config.AuthProvider = nil
+		config.ExecProvider = nil
+		config.Username = ""
+		config.Password = ""
+	}
+	return kubernetes.NewForConfig(config)
+}
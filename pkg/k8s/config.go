@@ -1,6 +1,7 @@
 package k8s
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
@@ -26,17 +27,17 @@ type ClusterConfig struct {
 }
 
 // GetConfig returns `kubectl config view`
-func GetConfig() (*ClusterConfig, error) {
+func GetConfig(ctx context.Context) (*ClusterConfig, error) {
 	var config configParser
 
-	out, err := kubectl([]string{"config", "view", "-o", "json"}...)
+	out, err := kubectl(ctx, []string{"config", "view", "-o", "json"}...)
 	if err != nil {
 		return &ClusterConfig{}, err
 	}
 
 	json.Unmarshal(out, &config)
 
-	clusterName, err := CurrentCluster()
+	clusterName, err := CurrentCluster(ctx)
 	if err != nil {
 		return &ClusterConfig{}, err
 	}
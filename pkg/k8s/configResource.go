@@ -1,6 +1,7 @@
 package k8s
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -27,7 +28,7 @@ type ConfigResource struct {
 }
 
 // Save persists updates to a configmap to k8s
-func (c *ConfigResource) Save(namespace string) (err error) {
+func (c *ConfigResource) Save(ctx context.Context, namespace string) (err error) {
 	config := c.config
 	config.Data = c.Data
 
@@ -38,14 +39,14 @@ func (c *ConfigResource) Save(namespace string) (err error) {
 		return
 	}
 
-	Apply(jsondata, namespace)
+	Apply(ctx, jsondata, namespace)
 
 	return
 }
 
 // GetConfigResource returns a ConfigResource struct with a Data element containing config map entries
-func GetConfigResource(name string, namespace string, kind string) (config *ConfigResource, err error) {
-	result, err := Get(strings.ToLower(kind), name, namespace, "-o", "json")
+func GetConfigResource(ctx context.Context, name string, namespace string, kind string) (config *ConfigResource, err error) {
+	result, err := Get(ctx, strings.ToLower(kind), name, namespace, "-o", "json")
 	if err != nil {
 		return nil, fmt.Errorf("could not kubectl get %s %s: %v", kind, name, err)
 	}
@@ -77,8 +78,8 @@ func GetConfigResource(name string, namespace string, kind string) (config *Conf
 }
 
 // GetConfigResourceWithToken is a step toward a larger refactor
-func GetConfigResourceWithToken(name string, namespace string, kind string, token string) (config *ConfigResource, err error) {
-	result, err := Get(strings.ToLower(kind), name, namespace, "-o", "json", "--token="+token)
+func GetConfigResourceWithToken(ctx context.Context, name string, namespace string, kind string, token string) (config *ConfigResource, err error) {
+	result, err := Get(ctx, strings.ToLower(kind), name, namespace, "-o", "json", "--token="+token)
 	if err != nil {
 		return nil, fmt.Errorf("could not kubectl get %s %s: %v", kind, name, err)
 	}
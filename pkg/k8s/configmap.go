@@ -1,8 +1,10 @@
 package k8s
 
+import "context"
+
 // PatchConfigMap gets, patches, and saves a configmap
-func PatchConfigMap(mapName string, namespace string, key string, value string) (err error) {
-	config, err := GetConfigResource(mapName, namespace, "ConfigMap")
+func PatchConfigMap(ctx context.Context, mapName string, namespace string, key string, value string) (err error) {
+	config, err := GetConfigResource(ctx, mapName, namespace, "ConfigMap")
 
 	if err != nil {
 		return
@@ -10,12 +12,12 @@ func PatchConfigMap(mapName string, namespace string, key string, value string)
 
 	config.Data[key] = value
 
-	return config.Save(namespace)
+	return config.Save(ctx, namespace)
 }
 
 // RemoveConfigMapEntry removes an entry, from a configmap
-func RemoveConfigMapEntry(mapName string, namespace string, key string) (err error) {
-	config, err := GetConfigResource(mapName, namespace, "ConfigMap")
+func RemoveConfigMapEntry(ctx context.Context, mapName string, namespace string, key string) (err error) {
+	config, err := GetConfigResource(ctx, mapName, namespace, "ConfigMap")
 
 	if err != nil {
 		return
@@ -23,5 +25,5 @@ func RemoveConfigMapEntry(mapName string, namespace string, key string) (err err
 
 	delete(config.Data, key)
 
-	return config.Save(namespace)
+	return config.Save(ctx, namespace)
 }
@@ -1,17 +1,20 @@
 package k8s
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"os/exec"
 	"strings"
 	"time"
 
+	"github.com/freshly/tuber/pkg/metrics"
+	"github.com/freshly/tuber/pkg/tracing"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
 
-func runKubectl(cmd *exec.Cmd) ([]byte, error) {
+func runKubectl(ctx context.Context, cmd *exec.Cmd) ([]byte, error) {
 	if viper.GetBool("TUBER_DEBUG") {
 		logger, zapErr := zap.NewDevelopment()
 		if zapErr != nil {
@@ -20,12 +23,19 @@ func runKubectl(cmd *exec.Cmd) ([]byte, error) {
 		logger.Debug(strings.Join(cmd.Args, " "))
 	}
 
+	verb := kubectlVerb(cmd.Args[1:])
+	_, span := tracing.Start(ctx, "kubectl "+verb)
+	start := time.Now()
 	out, err := cmd.CombinedOutput()
+	metrics.KubectlDuration.WithLabelValues(verb).Observe(time.Since(start).Seconds())
 
 	if err != nil || cmd.ProcessState.ExitCode() != 0 {
+		metrics.KubectlErrors.WithLabelValues(verb).Inc()
 		err = newK8sError(out, err)
+		tracing.End(span, err)
 		return nil, err
 	}
+	span.End()
 
 	if viper.GetBool("TUBER_DEBUG") {
 		logger, zapErr := zap.NewDevelopment()
@@ -38,8 +48,23 @@ func runKubectl(cmd *exec.Cmd) ([]byte, error) {
 	return out, nil
 }
 
-func kubectl(args ...string) ([]byte, error) {
-	return runKubectl(exec.Command("kubectl", args...))
+// kubectlVerb is the command being run, with the subcommand for commands like rollout that are nothing without one
+func kubectlVerb(args []string) string {
+	var words []string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+		words = append(words, arg)
+		if arg != "rollout" && arg != "auth" && arg != "config" {
+			break
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+func kubectl(ctx context.Context, args ...string) ([]byte, error) {
+	return runKubectl(ctx, exec.Command("kubectl", args...))
 }
 
 func kubectlIO(args ...string) error {
@@ -59,7 +84,7 @@ func kubectlIO(args ...string) error {
 	return cmd.Run()
 }
 
-func pipeToKubectl(data []byte, args ...string) (out []byte, err error) {
+func pipeToKubectl(ctx context.Context, data []byte, args ...string) (out []byte, err error) {
 	cmd := exec.Command("kubectl", args...)
 	stdin, err := cmd.StdinPipe()
 
@@ -77,70 +102,77 @@ func pipeToKubectl(data []byte, args ...string) (out []byte, err error) {
 		return
 	}
 
-	return runKubectl(cmd)
+	return runKubectl(ctx, cmd)
 }
 
 // Apply `kubectl apply` data to a given namespace. Specify output or any other flags as args.
 // Uses a stdin pipe to include the content of the data slice
-func Apply(data []byte, namespace string, args ...string) (err error) {
+func Apply(ctx context.Context, data []byte, namespace string, args ...string) (err error) {
 	apply := []string{"apply", "-n", namespace, "-f", "-"}
-	_, err = pipeToKubectl(data, append(apply, args...)...)
+	_, err = pipeToKubectl(ctx, data, append(apply, args...)...)
 	return
 }
 
 // Get `kubectl get` a resource. Specify output or any other flags as args
-func Get(kind string, name string, namespace string, args ...string) ([]byte, error) {
+func Get(ctx context.Context, kind string, name string, namespace string, args ...string) ([]byte, error) {
 	get := []string{"get", kind, name, "-n", namespace}
-	return kubectl(append(get, args...)...)
+	return kubectl(ctx, append(get, args...)...)
 }
 
 // GetCollection gets for plural resource types break if given even an empty name
-func GetCollection(kind string, namespace string, args ...string) ([]byte, error) {
+func GetCollection(ctx context.Context, kind string, namespace string, args ...string) ([]byte, error) {
 	get := []string{"get", kind, "-n", namespace}
-	return kubectl(append(get, args...)...)
+	return kubectl(ctx, append(get, args...)...)
 }
 
 // Delete `kubectl delete` a resource. Specify output or any other flags as args
-func Delete(kind string, name string, namespace string, args ...string) (err error) {
+func Delete(ctx context.Context, kind string, name string, namespace string, args ...string) (err error) {
 	deleteArgs := []string{"delete", kind, name, "-n", namespace}
-	_, err = kubectl(append(deleteArgs, args...)...)
+	_, err = kubectl(ctx, append(deleteArgs, args...)...)
 	return
 }
 
 // Create `kubectl create` a resource.
 // Some resources take multiple args (like secrets), so both the resource type and any flags are the variadic
-func Create(namespace string, resourceAndArgs ...string) (err error) {
+func Create(ctx context.Context, namespace string, resourceAndArgs ...string) (err error) {
 	create := []string{"create", "-n", namespace}
-	_, err = kubectl(append(create, resourceAndArgs...)...)
+	_, err = kubectl(ctx, append(create, resourceAndArgs...)...)
+	return
+}
+
+// CreateFromData `kubectl create` a resource from a manifest. Unlike Apply, it doesn't keep a copy in an annotation, so big resources fit.
+func CreateFromData(ctx context.Context, data []byte, namespace string, args ...string) (err error) {
+	create := []string{"create", "-n", namespace, "-f", "-"}
+	_, err = pipeToKubectl(ctx, data, append(create, args...)...)
 	return
 }
 
 // Restart runs a rollout restart on a given resource type for a namespace
-// For example, `Restart("deployments", "some-app")` will restart _all_ deployments in that namespace
-func Restart(resource string, namespace string, args ...string) (err error) {
+// For example, `Restart(ctx, "deployments", "some-app")` will restart _all_ deployments in that namespace
+func Restart(ctx context.Context, resource string, namespace string, args ...string) (err error) {
 	restart := []string{"rollout", "restart", resource, "-n", namespace}
-	_, err = kubectl(append(restart, args...)...)
+	_, err = kubectl(ctx, append(restart, args...)...)
 	return
 }
 
 // RolloutStatus waits and watches a rollout's progress
-func RolloutStatus(kind string, name string, namespace string, timeout time.Duration, args ...string) error {
+func RolloutStatus(ctx context.Context, kind string, name string, namespace string, timeout time.Duration, args ...string) error {
 	status := []string{"rollout", "status", kind, name, "-n", namespace, "--timeout", timeout.String()}
-	_, err := kubectl(append(status, args...)...)
+	_, err := kubectl(ctx, append(status, args...)...)
 	return err
 }
 
 // RolloutUndo runs undo on a rollout
-func RolloutUndo(kind string, name string, namespace string, args ...string) error {
+func RolloutUndo(ctx context.Context, kind string, name string, namespace string, args ...string) error {
 	status := []string{"rollout", "undo", kind, name, "-n", namespace}
-	_, err := kubectl(append(status, args...)...)
+	_, err := kubectl(ctx, append(status, args...)...)
 	return err
 }
 
 // Exists tells you if a given resource already exists. Errors if a get call fails for any reason other than Not Found
-func Exists(kind string, name string, namespace string, args ...string) (bool, error) {
+func Exists(ctx context.Context, kind string, name string, namespace string, args ...string) (bool, error) {
 	get := []string{"get", kind, name, "-n", namespace}
-	_, err := kubectl(append(get, args...)...)
+	_, err := kubectl(ctx, append(get, args...)...)
 	if err, ok := err.(NotFoundError); ok {
 		if ok {
 			return false, nil
@@ -175,9 +207,9 @@ type List struct {
 }
 
 // ListKind returns a List resource, with an Items slice of raw yamls
-func ListKind(kind string, namespace string, args ...string) (List, error) {
+func ListKind(ctx context.Context, kind string, namespace string, args ...string) (List, error) {
 	get := []string{"get", kind, "-n", namespace, "-o", "json"}
-	out, err := kubectl(append(get, args...)...)
+	out, err := kubectl(ctx, append(get, args...)...)
 	if err != nil {
 		return List{}, err
 	}
@@ -198,25 +230,15 @@ func ListKind(kind string, namespace string, args ...string) (List, error) {
 }
 
 // UseCluster switch current configured kubectl cluster
-func UseCluster(cluster string) error {
-	_, err := kubectl([]string{"config", "use-context", cluster}...)
+func UseCluster(ctx context.Context, cluster string) error {
+	_, err := kubectl(ctx, []string{"config", "use-context", cluster}...)
 	return err
 }
 
 // CanI is for authorization checks
-func CanI(namespace string, verb string, objectType string, args ...string) (bool, error) {
+func CanI(ctx context.Context, namespace string, verb string, objectType string, args ...string) (bool, error) {
 	canDeploy := []string{"auth", "can-i", verb, objectType, "-n", namespace}
-	out, err := kubectl(append(canDeploy, args...)...)
-	if err != nil {
-		return false, err
-	}
-
-	return strings.Trim(string(out), "\r\n") == "yes", nil
-}
-
-func CanIAllNamespaces(verb string, objectType string, args ...string) (bool, error) {
-	canDeploy := []string{"auth", "can-i", verb, objectType, "--all-namespaces"}
-	out, err := kubectl(append(canDeploy, args...)...)
+	out, err := kubectl(ctx, append(canDeploy, args...)...)
 	if err != nil {
 		return false, err
 	}
@@ -225,8 +247,8 @@ func CanIAllNamespaces(verb string, objectType string, args ...string) (bool, er
 }
 
 // CurrentCluster the current configured kubectl cluster
-func CurrentCluster() (string, error) {
-	out, err := kubectl([]string{"config", "current-context"}...)
+func CurrentCluster(ctx context.Context) (string, error) {
+	out, err := kubectl(ctx, []string{"config", "current-context"}...)
 	if err != nil {
 		return "", err
 	}
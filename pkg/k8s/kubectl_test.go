@@ -0,0 +1,24 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKubectlVerb(t *testing.T) {
+	testCases := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"apply", "-n", "potatoes", "-f", "-"}, "apply"},
+		{[]string{"get", "deployment", "potatoes", "-n", "potatoes"}, "get"},
+		{[]string{"rollout", "status", "deployment", "potatoes", "-n", "potatoes", "--timeout", "5m0s"}, "rollout status"},
+		{[]string{"auth", "can-i", "get", "deployments", "--all-namespaces"}, "auth can-i"},
+		{[]string{"config", "current-context"}, "config current-context"},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, kubectlVerb(tc.args))
+	}
+}
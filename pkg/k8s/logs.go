@@ -0,0 +1,151 @@
+package k8s
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxLogRequests is how many pods' logs are read at once when following a workload
+const maxLogRequests = "20"
+
+// DefaultLogTail is how many lines of each container's logs are read when no tail is given, so a chatty pod's whole history isn't read into memory
+const DefaultLogTail = 1000
+
+// nameRe matches DNS-1123 subdomains - every pod, container, and workload name, and every kind
+var nameRe = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+
+// ValidateName checks a caller's name for a resource is one kubectl can't mistake for a flag
+func ValidateName(name string) error {
+	if len(name) > 253 || !nameRe.MatchString(name) {
+		return fmt.Errorf("%q is not a valid kubernetes name", name)
+	}
+	return nil
+}
+
+// LogOptions picks whose logs to get - a pod, or every pod a selector matches - and how far back
+type LogOptions struct {
+	Pod       string
+	Selector  string
+	Container string
+	Since     time.Duration
+	Tail      int
+}
+
+// LogLine is one line of a container's logs
+type LogLine struct {
+	Pod       string
+	Container string
+	Message   string
+}
+
+// WorkloadSelector is a workload's label selector, in the key=value,key=value form kubectl's -l takes
+func WorkloadSelector(ctx context.Context, kind string, name string, namespace string, args ...string) (string, error) {
+	template := `{{range $k, $v := $.spec.selector.matchLabels}}{{$k}}={{$v}},{{end}}`
+	out, err := Get(ctx, kind, name, namespace, append([]string{"-o", "go-template", "--template", template}, args...)...)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(out), ","), nil
+}
+
+// Logs gets the logs of every container the options pick, with each line labeled with its pod and container
+func Logs(ctx context.Context, namespace string, options LogOptions, args ...string) ([]LogLine, error) {
+	out, err := kubectl(ctx, append(logsArgs(namespace, options, false), args...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []LogLine
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, parseLogLine(scanner.Text()))
+	}
+	return lines, scanner.Err()
+}
+
+// StreamLogs follows logs, sending each line as it's written until ctx is done or kubectl exits
+func StreamLogs(ctx context.Context, namespace string, options LogOptions, lines chan<- LogLine, args ...string) error {
+	cmd := exec.CommandContext(ctx, "kubectl", append(logsArgs(namespace, options, true), args...)...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		select {
+		case lines <- parseLogLine(scanner.Text()):
+		case <-ctx.Done():
+		}
+	}
+
+	err = cmd.Wait()
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err != nil {
+		return newK8sError([]byte(stderr.String()), err)
+	}
+	return nil
+}
+
+func logsArgs(namespace string, options LogOptions, follow bool) []string {
+	args := []string{"logs", "-n", namespace, "--prefix"}
+	if options.Pod == "" {
+		args = append(args, "-l", options.Selector, "--max-log-requests", maxLogRequests)
+	}
+	if options.Container != "" {
+		args = append(args, "-c", options.Container)
+	} else {
+		args = append(args, "--all-containers")
+	}
+	if options.Since != 0 {
+		args = append(args, "--since", options.Since.String())
+	}
+	tail := options.Tail
+	if tail == 0 {
+		tail = DefaultLogTail
+	}
+	args = append(args, "--tail", strconv.Itoa(tail))
+	if follow {
+		args = append(args, "-f")
+	}
+	if options.Pod != "" {
+		args = append(args, "--", options.Pod)
+	}
+	return args
+}
+
+// parseLogLine splits off the [pod/name/container] prefix kubectl adds with --prefix
+func parseLogLine(line string) LogLine {
+	if !strings.HasPrefix(line, "[pod/") {
+		return LogLine{Message: line}
+	}
+
+	end := strings.Index(line, "] ")
+	if end == -1 {
+		return LogLine{Message: line}
+	}
+
+	source := strings.SplitN(strings.TrimPrefix(line[:end], "[pod/"), "/", 2)
+	logLine := LogLine{Pod: source[0], Message: line[end+2:]}
+	if len(source) == 2 {
+		logLine.Container = source[1]
+	}
+	return logLine
+}
@@ -0,0 +1,34 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLogLine(t *testing.T) {
+	assert.Equal(t, LogLine{Pod: "potatoes-5d8f-abcde", Container: "potatoes", Message: "GET /health 200"}, parseLogLine("[pod/potatoes-5d8f-abcde/potatoes] GET /health 200"))
+	assert.Equal(t, LogLine{Pod: "potatoes-5d8f-abcde", Container: "istio-proxy", Message: "[info] [envoy] ready"}, parseLogLine("[pod/potatoes-5d8f-abcde/istio-proxy] [info] [envoy] ready"))
+	assert.Equal(t, LogLine{Message: "error: container is waiting to start"}, parseLogLine("error: container is waiting to start"))
+}
+
+func TestLogsArgs(t *testing.T) {
+	assert.Equal(t,
+		[]string{"logs", "-n", "potatoes", "--prefix", "-l", "app=potatoes", "--max-log-requests", maxLogRequests, "--all-containers", "--since", "10m0s", "--tail", "1000", "-f"},
+		logsArgs("potatoes", LogOptions{Selector: "app=potatoes", Since: 10 * time.Minute}, true),
+	)
+	assert.Equal(t,
+		[]string{"logs", "-n", "potatoes", "--prefix", "-c", "potatoes", "--tail", "100", "--", "potatoes-5d8f-abcde"},
+		logsArgs("potatoes", LogOptions{Pod: "potatoes-5d8f-abcde", Container: "potatoes", Tail: 100}, false),
+	)
+}
+
+func TestValidateName(t *testing.T) {
+	assert.Nil(t, ValidateName("potatoes-5d8f-abcde"))
+	assert.Nil(t, ValidateName("deployments.apps"))
+	assert.NotNil(t, ValidateName("--namespace=kube-system"))
+	assert.NotNil(t, ValidateName("-n"))
+	assert.NotNil(t, ValidateName("Potatoes"))
+	assert.NotNil(t, ValidateName(""))
+}
@@ -2,6 +2,7 @@ package k8s
 
 import (
 	"bufio"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -13,7 +14,7 @@ import (
 )
 
 // CreateTuberCredentials creates a secret based on the contents of a file
-func CreateTuberCredentials(path string, namespace string) (err error) {
+func CreateTuberCredentials(ctx context.Context, path string, namespace string) (err error) {
 	dat, err := ioutil.ReadFile(path)
 
 	if err != nil {
@@ -43,11 +44,11 @@ func CreateTuberCredentials(path string, namespace string) (err error) {
 		return
 	}
 
-	return Apply(jsondata, namespace)
+	return Apply(ctx, jsondata, namespace)
 }
 
-func GetSecret(namespace string, secretName string) (*ConfigResource, error) {
-	config, err := GetConfigResource(secretName, namespace, "Secret")
+func GetSecret(ctx context.Context, namespace string, secretName string) (*ConfigResource, error) {
+	config, err := GetConfigResource(ctx, secretName, namespace, "Secret")
 	if err != nil {
 		return nil, err
 	}
@@ -65,7 +66,7 @@ func GetSecret(namespace string, secretName string) (*ConfigResource, error) {
 }
 
 // CreateEnvFromFile replaces an apps env with data in a local file
-func CreateEnvFromFile(name string, path string) (err error) {
+func CreateEnvFromFile(ctx context.Context, name string, path string) (err error) {
 	var out []byte
 
 	if path == "-" {
@@ -89,19 +90,19 @@ func CreateEnvFromFile(name string, path string) (err error) {
 		stringifiedData[k] = base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%v", v)))
 	}
 
-	config, err := GetConfigResource(name+"-env", name, "Secret")
+	config, err := GetConfigResource(ctx, name+"-env", name, "Secret")
 
 	if err != nil {
 		return
 	}
 
 	config.Data = stringifiedData
-	return config.Save(name)
+	return config.Save(ctx, name)
 }
 
 // PatchSecret gets, patches, and saves a secret
-func PatchSecret(mapName string, namespace string, key string, value string) (err error) {
-	config, err := GetConfigResource(mapName, namespace, "Secret")
+func PatchSecret(ctx context.Context, mapName string, namespace string, key string, value string) (err error) {
+	config, err := GetConfigResource(ctx, mapName, namespace, "Secret")
 
 	if err != nil {
 		return
@@ -115,12 +116,12 @@ func PatchSecret(mapName string, namespace string, key string, value string) (er
 		config.Data[key] = value
 	}
 
-	return config.Save(namespace)
+	return config.Save(ctx, namespace)
 }
 
 // RemoveSecretEntry removes an entry, from a secret
-func RemoveSecretEntry(mapName string, namespace string, key string) (err error) {
-	config, err := GetConfigResource(mapName, namespace, "Secret")
+func RemoveSecretEntry(ctx context.Context, mapName string, namespace string, key string) (err error) {
+	config, err := GetConfigResource(ctx, mapName, namespace, "Secret")
 
 	if err != nil {
 		return
@@ -128,10 +129,10 @@ func RemoveSecretEntry(mapName string, namespace string, key string) (err error)
 
 	delete(config.Data, key)
 
-	return config.Save(namespace)
+	return config.Save(ctx, namespace)
 }
 
 // CreateEnv creates a Secret for a new TuberApp, to store env vars
-func CreateEnv(appName string) error {
-	return Create(appName, "secret", "generic", appName+"-env")
+func CreateEnv(ctx context.Context, appName string) error {
+	return Create(ctx, appName, "secret", "generic", appName+"-env")
 }
@@ -0,0 +1,206 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+// recentEvents is how far back warning events are reported, and maxEvents caps how many each workload reports
+const (
+	recentEvents = time.Hour
+	maxEvents    = 10
+)
+
+// Workload is the live status of a deployment, statefulset or daemonset, with its pods and recent warnings
+type Workload struct {
+	Kind            string
+	Name            string
+	DesiredReplicas int
+	ReadyReplicas   int
+	UpdatedReplicas int
+	Images          []string
+	Pods            []Pod
+	Warnings        []Event
+}
+
+// Pod is a workload's pod
+type Pod struct {
+	Name      string
+	Phase     string
+	Ready     bool
+	Restarts  int
+	CreatedAt time.Time
+}
+
+// Event is a warning event about a workload, or one of its pods or replicasets
+type Event struct {
+	Reason   string
+	Message  string
+	Object   string
+	Count    int
+	LastSeen time.Time
+}
+
+type workloadItem struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name              string            `json:"name"`
+		Labels            map[string]string `json:"labels"`
+		CreationTimestamp time.Time         `json:"creationTimestamp"`
+		OwnerReferences   []struct {
+			Kind string `json:"kind"`
+			Name string `json:"name"`
+		} `json:"ownerReferences"`
+	} `json:"metadata"`
+	Spec struct {
+		Replicas *int `json:"replicas"`
+		Selector struct {
+			MatchLabels map[string]string `json:"matchLabels"`
+		} `json:"selector"`
+	} `json:"spec"`
+	Status struct {
+		ReadyReplicas          int    `json:"readyReplicas"`
+		UpdatedReplicas        int    `json:"updatedReplicas"`
+		DesiredNumberScheduled int    `json:"desiredNumberScheduled"`
+		NumberReady            int    `json:"numberReady"`
+		UpdatedNumberScheduled int    `json:"updatedNumberScheduled"`
+		Phase                  string `json:"phase"`
+		ContainerStatuses      []struct {
+			Ready        bool   `json:"ready"`
+			RestartCount int    `json:"restartCount"`
+			ImageID      string `json:"imageID"`
+		} `json:"containerStatuses"`
+	} `json:"status"`
+	Type           string    `json:"type"`
+	Reason         string    `json:"reason"`
+	Message        string    `json:"message"`
+	Count          int       `json:"count"`
+	LastTimestamp  time.Time `json:"lastTimestamp"`
+	EventTime      time.Time `json:"eventTime"`
+	InvolvedObject struct {
+		Kind string `json:"kind"`
+		Name string `json:"name"`
+	} `json:"involvedObject"`
+}
+
+// Workloads gets the live status of every deployment, statefulset and daemonset in a namespace
+func Workloads(ctx context.Context, namespace string, args ...string) ([]Workload, error) {
+	get := []string{"get", "deployments,statefulsets,daemonsets,replicasets,pods,events", "-n", namespace, "-o", "json"}
+	out, err := kubectl(ctx, append(get, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	return parseWorkloads(out, time.Now())
+}
+
+func parseWorkloads(out []byte, now time.Time) ([]Workload, error) {
+	var list struct {
+		Items []workloadItem `json:"items"`
+	}
+	err := json.Unmarshal(out, &list)
+	if err != nil {
+		return nil, err
+	}
+
+	var workloadItems, pods, events []workloadItem
+	// replicaSetOwners is the deployment that owns each replicaset, so replicaset events go to the right deployment
+	replicaSetOwners := map[string]string{}
+	for _, item := range list.Items {
+		switch item.Kind {
+		case "Deployment", "StatefulSet", "DaemonSet":
+			workloadItems = append(workloadItems, item)
+		case "ReplicaSet":
+			for _, owner := range item.Metadata.OwnerReferences {
+				if owner.Kind == "Deployment" {
+					replicaSetOwners[item.Metadata.Name] = owner.Name
+				}
+			}
+		case "Pod":
+			pods = append(pods, item)
+		case "Event":
+			if item.LastTimestamp.IsZero() {
+				item.LastTimestamp = item.EventTime
+			}
+			if item.Type == "Warning" && now.Sub(item.LastTimestamp) <= recentEvents {
+				events = append(events, item)
+			}
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].LastTimestamp.After(events[j].LastTimestamp)
+	})
+
+	var workloads []Workload
+	for _, item := range workloadItems {
+		workload := Workload{Kind: item.Kind, Name: item.Metadata.Name}
+		if item.Kind == "DaemonSet" {
+			workload.DesiredReplicas = item.Status.DesiredNumberScheduled
+			workload.ReadyReplicas = item.Status.NumberReady
+			workload.UpdatedReplicas = item.Status.UpdatedNumberScheduled
+		} else {
+			workload.DesiredReplicas = 1
+			if item.Spec.Replicas != nil {
+				workload.DesiredReplicas = *item.Spec.Replicas
+			}
+			workload.ReadyReplicas = item.Status.ReadyReplicas
+			workload.UpdatedReplicas = item.Status.UpdatedReplicas
+		}
+
+		podNames := map[string]bool{}
+		images := map[string]bool{}
+		for _, pod := range pods {
+			if !selects(item.Spec.Selector.MatchLabels, pod.Metadata.Labels) {
+				continue
+			}
+			podNames[pod.Metadata.Name] = true
+
+			p := Pod{Name: pod.Metadata.Name, Phase: pod.Status.Phase, Ready: len(pod.Status.ContainerStatuses) != 0, CreatedAt: pod.Metadata.CreationTimestamp}
+			for _, container := range pod.Status.ContainerStatuses {
+				p.Ready = p.Ready && container.Ready
+				p.Restarts += container.RestartCount
+				if container.ImageID != "" && !images[container.ImageID] {
+					images[container.ImageID] = true
+					workload.Images = append(workload.Images, strings.TrimPrefix(container.ImageID, "docker-pullable://"))
+				}
+			}
+			workload.Pods = append(workload.Pods, p)
+		}
+
+		for _, event := range events {
+			if len(workload.Warnings) == maxEvents {
+				break
+			}
+			object := event.InvolvedObject
+			if !(object.Kind == item.Kind && object.Name == item.Metadata.Name) && !(object.Kind == "Pod" && podNames[object.Name]) &&
+				!(object.Kind == "ReplicaSet" && item.Kind == "Deployment" && replicaSetOwners[object.Name] == item.Metadata.Name) {
+				continue
+			}
+			workload.Warnings = append(workload.Warnings, Event{
+				Reason:   event.Reason,
+				Message:  event.Message,
+				Object:   strings.ToLower(object.Kind) + "/" + object.Name,
+				Count:    event.Count,
+				LastSeen: event.LastTimestamp,
+			})
+		}
+
+		workloads = append(workloads, workload)
+	}
+	return workloads, nil
+}
+
+// selects is true when a workload's selector matches a pod's labels
+func selects(selector map[string]string, labels map[string]string) bool {
+	if len(selector) == 0 {
+		return false
+	}
+	for key, value := range selector {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
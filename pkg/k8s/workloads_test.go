@@ -0,0 +1,65 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const workloadsJSON = `{"items": [
+	{"kind": "Deployment", "metadata": {"name": "potatoes"}, "spec": {"replicas": 2, "selector": {"matchLabels": {"app": "potatoes"}}},
+		"status": {"readyReplicas": 1, "updatedReplicas": 2}},
+	{"kind": "Deployment", "metadata": {"name": "potatoes-worker"}, "spec": {"replicas": 1, "selector": {"matchLabels": {"app": "potatoes-worker"}}},
+		"status": {"readyReplicas": 0, "updatedReplicas": 1}},
+	{"kind": "ReplicaSet", "metadata": {"name": "potatoes-5d8f", "ownerReferences": [{"kind": "Deployment", "name": "potatoes"}]}},
+	{"kind": "ReplicaSet", "metadata": {"name": "potatoes-worker-7c9b", "ownerReferences": [{"kind": "Deployment", "name": "potatoes-worker"}]}},
+	{"kind": "DaemonSet", "metadata": {"name": "potatoes-agent"}, "spec": {"selector": {"matchLabels": {"app": "potatoes-agent"}}},
+		"status": {"desiredNumberScheduled": 3, "numberReady": 3, "updatedNumberScheduled": 3}},
+	{"kind": "Pod", "metadata": {"name": "potatoes-5d8f-abcde", "labels": {"app": "potatoes"}, "creationTimestamp": "2021-06-01T11:00:00Z"},
+		"status": {"phase": "Running", "containerStatuses": [{"ready": true, "restartCount": 0, "imageID": "docker-pullable://gcr.io/freshly-docker/potatoes@sha256:abc"}]}},
+	{"kind": "Pod", "metadata": {"name": "potatoes-5d8f-fghij", "labels": {"app": "potatoes"}, "creationTimestamp": "2021-06-01T11:50:00Z"},
+		"status": {"phase": "Running", "containerStatuses": [{"ready": false, "restartCount": 4, "imageID": "docker-pullable://gcr.io/freshly-docker/potatoes@sha256:abc"}]}},
+	{"kind": "Pod", "metadata": {"name": "carrots-1234", "labels": {"app": "carrots"}}, "status": {"phase": "Pending"}},
+	{"kind": "Event", "type": "Warning", "reason": "BackOff", "message": "Back-off restarting failed container", "count": 4,
+		"lastTimestamp": "2021-06-01T11:58:00Z", "involvedObject": {"kind": "Pod", "name": "potatoes-5d8f-fghij"}},
+	{"kind": "Event", "type": "Warning", "reason": "FailedCreate", "message": "quota exceeded", "count": 1,
+		"lastTimestamp": null, "eventTime": "2021-06-01T11:59:00.000000Z", "involvedObject": {"kind": "ReplicaSet", "name": "potatoes-5d8f"}},
+	{"kind": "Event", "type": "Warning", "reason": "FailedCreate", "message": "quota exceeded", "count": 1,
+		"lastTimestamp": "2021-06-01T11:57:00Z", "involvedObject": {"kind": "ReplicaSet", "name": "potatoes-worker-7c9b"}},
+	{"kind": "Event", "type": "Warning", "reason": "BackOff", "message": "old news", "count": 1,
+		"lastTimestamp": "2021-06-01T09:00:00Z", "involvedObject": {"kind": "Pod", "name": "potatoes-5d8f-fghij"}},
+	{"kind": "Event", "type": "Normal", "reason": "Scheduled", "message": "assigned", "count": 1,
+		"lastTimestamp": "2021-06-01T11:50:00Z", "involvedObject": {"kind": "Pod", "name": "potatoes-5d8f-fghij"}}
+]}`
+
+func TestParseWorkloads(t *testing.T) {
+	workloads, err := parseWorkloads([]byte(workloadsJSON), time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, workloads, 3)
+
+	deployment := workloads[0]
+	assert.Equal(t, "potatoes", deployment.Name)
+	assert.Equal(t, 2, deployment.DesiredReplicas)
+	assert.Equal(t, 1, deployment.ReadyReplicas)
+	assert.Equal(t, 2, deployment.UpdatedReplicas)
+	assert.Equal(t, []string{"gcr.io/freshly-docker/potatoes@sha256:abc"}, deployment.Images)
+	require.Len(t, deployment.Pods, 2, "only pods its selector matches")
+	assert.True(t, deployment.Pods[0].Ready)
+	assert.False(t, deployment.Pods[1].Ready)
+	assert.Equal(t, 4, deployment.Pods[1].Restarts)
+	require.Len(t, deployment.Warnings, 2, "only recent warnings about it, its pods and replicasets")
+	assert.Equal(t, "replicaset/potatoes-5d8f", deployment.Warnings[0].Object)
+	assert.Equal(t, "pod/potatoes-5d8f-fghij", deployment.Warnings[1].Object)
+
+	worker := workloads[1]
+	require.Len(t, worker.Warnings, 1, "replicaset events go to the deployment that owns the replicaset, not one whose name prefixes it")
+	assert.Equal(t, "replicaset/potatoes-worker-7c9b", worker.Warnings[0].Object)
+
+	daemonSet := workloads[2]
+	assert.Equal(t, "DaemonSet", daemonSet.Kind)
+	assert.Equal(t, 3, daemonSet.DesiredReplicas)
+	assert.Empty(t, daemonSet.Pods)
+	assert.Empty(t, daemonSet.Warnings)
+}
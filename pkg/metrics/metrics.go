@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "tuber"
+
+// Release outcomes
+const (
+	OutcomeSucceeded = "succeeded"
+	OutcomeFailed    = "failed"
+	OutcomeSkipped   = "skipped"
+)
+
+// Release phases
+const (
+	PhasePrerelease  = "prerelease"
+	PhaseApply       = "apply"
+	PhaseRollout     = "rollout"
+	PhasePostrelease = "postrelease"
+)
+
+// Reasons pubsub messages are ignored
+const (
+	IgnoredUnparseable = "unparseable"
+	IgnoredDuplicate   = "duplicate"
+	IgnoredNoApps      = "no_apps"
+)
+
+// releases take minutes, kubectl calls range from instant gets to rollout status waits
+var phaseBuckets = []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200}
+var kubectlBuckets = []float64{.1, .25, .5, 1, 2.5, 5, 10, 30, 60, 300}
+
+var (
+	Releases = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "releases_total",
+		Help:      "Releases by app and outcome.",
+	}, []string{"app", "outcome"})
+
+	ReleasePhaseDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "release_phase_duration_seconds",
+		Help:      "How long each phase of a release takes.",
+		Buckets:   phaseBuckets,
+	}, []string{"phase"})
+
+	Rollbacks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rollbacks_total",
+		Help:      "Releases rolled back, by app.",
+	}, []string{"app"})
+
+	MonitorFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "monitor_failures_total",
+		Help:      "Releases failed by monitoring, by app.",
+	}, []string{"app"})
+
+	PubsubMessagesReceived = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "pubsub_messages_received_total",
+		Help:      "Pubsub messages received, by subscription.",
+	}, []string{"subscription"})
+
+	PubsubMessagesIgnored = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "pubsub_messages_ignored_total",
+		Help:      "Pubsub messages that didn't lead to a release, by reason.",
+	}, []string{"reason"})
+
+	KubectlDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "kubectl_duration_seconds",
+		Help:      "kubectl invocation latency, by verb.",
+		Buckets:   kubectlBuckets,
+	}, []string{"verb"})
+
+	KubectlErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kubectl_errors_total",
+		Help:      "kubectl invocations that failed, by verb.",
+	}, []string{"verb"})
+
+	SlackFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "slack_send_failures_total",
+		Help:      "Slack messages that failed to post or update.",
+	})
+
+	GraphqlDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "graphql_request_duration_seconds",
+		Help:      "GraphQL request latency, by operation.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+)
+
+// Handler serves everything registered, including the go runtime and process collectors
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Release counts a release's outcome
+func Release(app string, outcome string) {
+	Releases.WithLabelValues(app, outcome).Inc()
+}
+
+// Phase records how long a release phase took, from its start
+func Phase(phase string, start time.Time) {
+	ReleasePhaseDuration.WithLabelValues(phase).Observe(time.Since(start).Seconds())
+}
@@ -1,20 +1,30 @@
 package monitor
 
 import (
+	"context"
 	"time"
 
+	"github.com/freshly/tuber/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
-func Sentry(logger *zap.Logger, url string, bearer string, duration time.Duration) (bool, string) {
+func Sentry(ctx context.Context, logger *zap.Logger, url string, bearer string, duration time.Duration) (bool, string) {
+	ctx, span := tracing.Start(ctx, "monitor.sentry", attribute.String("monitor.url", url))
+	defer span.End()
+
 	timeout := time.Now().Add(duration)
 	for {
 		logger.Debug("pinging sentry at: " + url)
 		if time.Now().After(timeout) {
 			return true, ""
 		}
+		_, check := tracing.Start(ctx, "monitor.sentry.check")
 		healthy, message := checkSentry(logger, url, bearer)
+		check.SetAttributes(attribute.Bool("monitor.healthy", healthy))
+		check.End()
 		if !healthy {
+			span.SetAttributes(attribute.String("monitor.failure", message))
 			return false, message
 		}
 		time.Sleep(30 * time.Second)
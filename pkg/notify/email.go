@@ -0,0 +1,127 @@
+package notify
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Email sends events over smtp. Auth is only used if a username is set.
+type Email struct {
+	addr     string
+	from     string
+	username string
+	password string
+}
+
+func NewEmail(addr string, from string, username string, password string) *Email {
+	return &Email{
+		addr:     addr,
+		from:     from,
+		username: username,
+		password: password,
+	}
+}
+
+// Notify sends the way smtp.SendMail does, but gives up on servers that don't answer within deliveryTimeout
+func (e *Email) Notify(logger *zap.Logger, to string, event Event) error {
+	host, _, err := net.SplitHostPort(e.addr)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.DialTimeout("tcp", e.addr, deliveryTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = conn.SetDeadline(time.Now().Add(deliveryTimeout))
+	if err != nil {
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		err = client.StartTLS(&tls.Config{ServerName: host})
+		if err != nil {
+			return err
+		}
+	}
+	if e.username != "" {
+		err = client.Auth(smtp.PlainAuth("", e.username, e.password, host))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = client.Mail(e.from)
+	if err != nil {
+		return err
+	}
+	err = client.Rcpt(to)
+	if err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(emailMessage(e.from, to, event))
+	if err != nil {
+		return err
+	}
+	err = w.Close()
+	if err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func emailMessage(from string, to string, event Event) []byte {
+	// subjects are a single header line, so anything from the event can't be allowed to end it early
+	subject := strings.NewReplacer("\r", " ", "\n", " ").Replace("[tuber] " + event.App + ": " + event.Message)
+
+	lines := []string{
+		"From: " + from,
+		"To: " + to,
+		"Subject: " + subject,
+		"Date: " + event.Time.Format("Mon, 02 Jan 2006 15:04:05 -0700"),
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		event.App + ": " + event.Message,
+	}
+	if event.Tag != "" {
+		lines = append(lines, "", "Tag: "+event.Tag)
+	}
+	if event.Detail != "" {
+		lines = append(lines, "", event.Detail)
+	}
+	if len(event.Commits) != 0 {
+		lines = append(lines, "", "Commits:")
+		for _, commit := range event.Commits {
+			lines = append(lines, "  "+commit.ShortSHA()+" "+commit.Subject+" - "+commit.Author)
+		}
+		if more := event.MoreCommits(); more != 0 {
+			lines = append(lines, fmt.Sprintf("  ...and %d more", more))
+		}
+	}
+	if event.DiffLink != "" {
+		lines = append(lines, "", "Compare diff: "+event.DiffLink)
+	}
+	if event.LogLink != "" {
+		lines = append(lines, "", "Logs: "+event.LogLink)
+	}
+	lines = append(lines, "", fmt.Sprintf("Event: %s at %s", event.Type, event.Time.Format("2006-01-02 15:04:05 MST")))
+
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
@@ -0,0 +1,142 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+// internalNetworks are addresses webhooks and notifications can't be delivered to - loopback, link-local (like the metadata server),
+// private ranges (like cluster pods and services), and everything else that isn't a public internet address
+var internalNetworks = parseCIDRs(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"::/128",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+)
+
+func parseCIDRs(cidrs ...string) []*net.IPNet {
+	var networks []*net.IPNet
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+func internalIP(ip net.IP) bool {
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+	for _, network := range internalNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookHosts keeps webhooks and url notification targets from reaching tuber's own network. Urls whose hosts resolve to internal addresses
+// are refused, both when they're set and when they're delivered to, unless the host is explicitly allowed. Redirects are never followed.
+type WebhookHosts struct {
+	allowed       map[string]bool
+	client        *http.Client
+	allowedClient *http.Client
+}
+
+// NewWebhookHosts constructs a WebhookHosts, allowing the given hosts even if they're internal
+func NewWebhookHosts(allowed []string) *WebhookHosts {
+	noRedirects := func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	hosts := &WebhookHosts{
+		allowed:       map[string]bool{},
+		allowedClient: &http.Client{Timeout: deliveryTimeout, CheckRedirect: noRedirects},
+	}
+	hosts.client = &http.Client{
+		Timeout:       deliveryTimeout,
+		Transport:     &http.Transport{DialContext: hosts.dialer().DialContext},
+		CheckRedirect: noRedirects,
+	}
+	for _, host := range allowed {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host != "" {
+			hosts.allowed[host] = true
+		}
+	}
+	return hosts
+}
+
+// Client is the client to deliver to a url with - one that refuses internal addresses, unless the url's host is allowed
+func (h *WebhookHosts) Client(rawURL string) *http.Client {
+	if h.Allowed(rawURL) {
+		return h.allowedClient
+	}
+	return h.client
+}
+
+// Allowed is whether a url's host is explicitly allowed, skipping the address checks
+func (h *WebhookHosts) Allowed(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return h.allowed[strings.ToLower(u.Hostname())]
+}
+
+// Check resolves a webhook url's host, erroring if any of its addresses are internal
+func (h *WebhookHosts) Check(ctx context.Context, rawURL string) error {
+	if h.Allowed(rawURL) {
+		return nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil {
+		return fmt.Errorf("could not resolve webhook host %s: %v", u.Hostname(), err)
+	}
+	for _, addr := range addrs {
+		if internalIP(addr.IP) {
+			return fmt.Errorf("webhook host %s resolves to internal address %s", u.Hostname(), addr.IP)
+		}
+	}
+	return nil
+}
+
+// dialer refuses connections to internal addresses, checking the address actually dialed so a host can't pass Check and later resolve somewhere else
+func (h *WebhookHosts) dialer() *net.Dialer {
+	return &net.Dialer{
+		Timeout: deliveryTimeout,
+		Control: func(network string, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || internalIP(ip) {
+				return fmt.Errorf("webhook address %s is internal", host)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,32 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookHostsCheck(t *testing.T) {
+	hosts := NewWebhookHosts([]string{"deploys.tools.svc.cluster.local", ""})
+	ctx := context.Background()
+
+	assert.NotNil(t, hosts.Check(ctx, "http://127.0.0.1:8080/hook"))
+	assert.NotNil(t, hosts.Check(ctx, "http://localhost/hook"))
+	assert.NotNil(t, hosts.Check(ctx, "http://169.254.169.254/computeMetadata/v1/"))
+	assert.NotNil(t, hosts.Check(ctx, "http://10.0.0.1/hook"))
+	assert.NotNil(t, hosts.Check(ctx, "http://[::1]/hook"))
+	assert.Nil(t, hosts.Check(ctx, "https://8.8.8.8/hook"))
+	assert.Nil(t, hosts.Check(ctx, "http://deploys.tools.svc.cluster.local/hook"))
+}
+
+func TestWebhookHostsDialer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &http.Transport{DialContext: NewWebhookHosts(nil).dialer().DialContext}}
+	_, err := client.Get(server.URL)
+	assert.NotNil(t, err, "delivering to an internal address should be refused even if it wasn't checked")
+}
@@ -0,0 +1,364 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/report"
+	"go.uber.org/zap"
+)
+
+// Event types
+const (
+	ReleaseStarted            = "release.started"
+	ReleasePrereleaseFinished = "release.prerelease_finished"
+	ReleaseCanaryStarted      = "release.canary_started"
+	ReleaseCanaryDeployed     = "release.canary_deployed"
+	ReleaseSucceeded          = "release.succeeded"
+	ReleaseFailed             = "release.failed"
+	ReleaseRolledBack         = "release.rolled_back"
+	ReleaseMonitorFailed      = "release.monitor_failed"
+	ReleaseWarning            = "release.warning"
+	ReleaseSkipped            = "release.skipped"
+	ReleaseFrozen             = "release.frozen"
+	ReleaseClusterPaused      = "release.cluster_paused"
+	ReleaseAwaitingApproval   = "release.awaiting_approval"
+	ReleaseApproved           = "release.approved"
+	ReleaseRejected           = "release.rejected"
+	BuildStarted              = "build.started"
+	BuildSucceeded            = "build.succeeded"
+	BuildFailed               = "build.failed"
+)
+
+// Progress event types only go to watchers, never to an app's targets. WatchStarted is the first event each watcher gets.
+const (
+	WatchStarted          = "watch.started"
+	ReleasePhaseStarted   = "release.phase_started"
+	ResourceRollingOut    = "resource.rolling_out"
+	ResourceRolledOut     = "resource.rolled_out"
+	ResourceRolloutFailed = "resource.rollout_failed"
+	MonitorPassed         = "monitor.passed"
+	RollbackSucceeded     = "rollback.succeeded"
+	RollbackFailed        = "rollback.failed"
+)
+
+// watcherBuffer is how many events a watcher can fall behind by before it misses some
+const watcherBuffer = 100
+
+// deliveryQueue is how many notifications a target can fall behind by before new ones are dropped
+const deliveryQueue = 100
+
+// deliveryIdle is how long a target's queue is kept around with nothing to deliver
+const deliveryIdle = time.Minute
+
+// deliveryTimeout caps a single delivery, so a slow backend only holds up its own queue
+const deliveryTimeout = 10 * time.Second
+
+// Severities, for backends that highlight failures
+const (
+	SeverityInfo  = "info"
+	SeverityError = "error"
+)
+
+// Actions a reader can take in response to an event. Backends that support it render them as buttons.
+const (
+	ActionRollback = "rollback"
+	ActionResume   = "resume"
+	ActionRetry    = "retry"
+)
+
+// Event is a structured notification about a release or build. Message is a short plain-text summary,
+// rendered by each backend alongside the app name and links.
+type Event struct {
+	Type     string    `json:"type"`
+	Severity string    `json:"severity"`
+	App      string    `json:"app"`
+	Tag      string    `json:"tag,omitempty"`
+	Release  string    `json:"release,omitempty"`
+	Message  string    `json:"message"`
+	Detail   string    `json:"detail,omitempty"`
+	Phase    string    `json:"phase,omitempty"`
+	Resource string    `json:"resource,omitempty"`
+	LogLink  string    `json:"logLink,omitempty"`
+	Actions  []string  `json:"actions,omitempty"`
+	Time     time.Time `json:"time"`
+	Changes
+}
+
+// Finished is true for the last event of a release or rollback, including releases that never started
+func (e Event) Finished() bool {
+	switch e.Type {
+	case ReleaseSucceeded, ReleaseFailed, ReleaseSkipped, ReleaseFrozen, ReleaseClusterPaused, ReleaseAwaitingApproval, ReleaseRejected, RollbackSucceeded, RollbackFailed:
+		return true
+	}
+	return false
+}
+
+// Succeeded is true for the last event of a release or rollback that went out
+func (e Event) Succeeded() bool {
+	return e.Type == ReleaseSucceeded || e.Type == RollbackSucceeded
+}
+
+// Changes are what a release brings in. Commits is capped, CommitCount is how many there really are.
+type Changes struct {
+	DiffLink    string   `json:"diffLink,omitempty"`
+	Commits     []Commit `json:"commits,omitempty"`
+	CommitCount int      `json:"commitCount,omitempty"`
+}
+
+// Commit is one commit in a release's changes
+type Commit struct {
+	SHA     string `json:"sha"`
+	Subject string `json:"subject"`
+	Author  string `json:"author"`
+	URL     string `json:"url,omitempty"`
+}
+
+// MoreCommits is how many commits were left out of Commits
+func (c Changes) MoreCommits() int {
+	if c.CommitCount <= len(c.Commits) {
+		return 0
+	}
+	return c.CommitCount - len(c.Commits)
+}
+
+// ShortSHA is the abbreviated sha git shows by default
+func (c Commit) ShortSHA() string {
+	if len(c.SHA) > 7 {
+		return c.SHA[:7]
+	}
+	return c.SHA
+}
+
+// Notifier is a backend that delivers events to one kind of target - a slack channel, a url, an email address
+type Notifier interface {
+	Notify(logger *zap.Logger, target string, event Event) error
+}
+
+// Notifiers fans events out to every target configured on an app.
+// Every app gets its slack channel (or the catch-all channel), plus any of its notification targets.
+type Notifiers struct {
+	backends    map[string]Notifier
+	queuesMutex sync.Mutex
+	queues      map[model.NotificationTarget]chan delivery
+	mutex       sync.Mutex
+	watchers    map[string]*appWatchers
+}
+
+// delivery is one event on its way to a target
+type delivery struct {
+	logger *zap.Logger
+	event  Event
+}
+
+// appWatchers are everyone watching one app. ctx is cancelled when the last of them stops watching.
+type appWatchers struct {
+	channels map[chan Event]bool
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+// New builds Notifiers from the slack client and whatever other backends are configured. Nil backends are skipped.
+func New(slackNotifier Notifier, webhookNotifier Notifier, teamsNotifier Notifier, emailNotifier Notifier) *Notifiers {
+	backends := map[string]Notifier{}
+	for name, backend := range map[string]Notifier{
+		model.NotificationSlack:   slackNotifier,
+		model.NotificationWebhook: webhookNotifier,
+		model.NotificationTeams:   teamsNotifier,
+		model.NotificationEmail:   emailNotifier,
+	} {
+		if backend != nil {
+			backends[name] = backend
+		}
+	}
+	return &Notifiers{backends: backends, queues: map[model.NotificationTarget]chan delivery{}, watchers: map[string]*appWatchers{}}
+}
+
+// Notify queues an event for each of an app's targets, without waiting on any of them - a slow target shouldn't hold up a release.
+// Each target delivers from its own queue, in the order events were sent, so a slow target only holds up its own notifications.
+// Delivery failures are logged and reported, never returned - a notification failing shouldn't fail a release.
+func (n *Notifiers) Notify(logger *zap.Logger, app *model.TuberApp, event Event) {
+	event = withDefaults(app, event)
+	n.broadcast(event)
+
+	targets := append([]*model.NotificationTarget{{Type: model.NotificationSlack, Target: app.SlackChannel}}, app.NotificationTargets...)
+
+	for _, target := range targets {
+		if _, ok := n.backends[target.Type]; !ok {
+			logger.Warn("notification target type is not configured", zap.String("notificationType", target.Type))
+			continue
+		}
+
+		if !n.enqueue(*target, delivery{logger: logger, event: event}) {
+			err := fmt.Errorf("%s notifications are %d behind, dropping %s", target.Type, deliveryQueue, event.Type)
+			logger.Error("failed to queue notification", zap.Error(err), zap.String("notificationType", target.Type), zap.String("eventType", event.Type))
+			report.Error(err, report.Scope{"context": "notify", "notificationType": target.Type, "eventType": event.Type, "appName": event.App})
+		}
+	}
+}
+
+// enqueue adds a delivery to a target's queue, starting the queue if the target doesn't have one. It's false if the queue is full.
+func (n *Notifiers) enqueue(target model.NotificationTarget, d delivery) bool {
+	n.queuesMutex.Lock()
+	defer n.queuesMutex.Unlock()
+
+	queue, ok := n.queues[target]
+	if !ok {
+		queue = make(chan delivery, deliveryQueue)
+		n.queues[target] = queue
+		go n.deliver(target, queue)
+	}
+
+	select {
+	case queue <- d:
+		return true
+	default:
+		return false
+	}
+}
+
+// deliver sends a target's queued events, stopping once the queue has been empty for deliveryIdle
+func (n *Notifiers) deliver(target model.NotificationTarget, queue chan delivery) {
+	backend := n.backends[target.Type]
+	for {
+		select {
+		case d := <-queue:
+			err := backend.Notify(d.logger, target.Target, d.event)
+			if err != nil {
+				d.logger.Error("failed to send notification", zap.Error(err), zap.String("notificationType", target.Type), zap.String("eventType", d.event.Type))
+				report.Error(err, report.Scope{"context": "notify", "notificationType": target.Type, "eventType": d.event.Type, "appName": d.event.App})
+			}
+		case <-time.After(deliveryIdle):
+			n.queuesMutex.Lock()
+			if len(queue) == 0 {
+				delete(n.queues, target)
+				n.queuesMutex.Unlock()
+				return
+			}
+			n.queuesMutex.Unlock()
+		}
+	}
+}
+
+// Watch streams an app's events as they happen, until stop is called. Watchers that fall behind miss events rather than holding up releases.
+func (n *Notifiers) Watch(appName string) (<-chan Event, func()) {
+	watcher := make(chan Event, watcherBuffer)
+	watcher <- Event{Type: WatchStarted, App: appName, Severity: SeverityInfo, Message: "watching " + appName, Time: time.Now().UTC()}
+
+	n.mutex.Lock()
+	watchers := n.watchers[appName]
+	if watchers == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		watchers = &appWatchers{channels: map[chan Event]bool{}, ctx: ctx, cancel: cancel}
+		n.watchers[appName] = watchers
+	}
+	watchers.channels[watcher] = true
+	n.mutex.Unlock()
+
+	var once sync.Once
+	return watcher, func() {
+		once.Do(func() {
+			n.mutex.Lock()
+			delete(watchers.channels, watcher)
+			if len(watchers.channels) == 0 {
+				watchers.cancel()
+				delete(n.watchers, appName)
+			}
+			n.mutex.Unlock()
+			close(watcher)
+		})
+	}
+}
+
+// Watched is cancelled once nobody is watching an app anymore, for work that's only worth doing while someone is.
+// It's false when nobody is watching the app now.
+func (n *Notifiers) Watched(appName string) (context.Context, bool) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	watchers, ok := n.watchers[appName]
+	if !ok {
+		return nil, false
+	}
+	return watchers.ctx, true
+}
+
+func (n *Notifiers) broadcast(event Event) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	watchers, ok := n.watchers[event.App]
+	if !ok {
+		return
+	}
+	for watcher := range watchers.channels {
+		select {
+		case watcher <- event:
+		default:
+		}
+	}
+}
+
+func withDefaults(app *model.TuberApp, event Event) Event {
+	event.App = app.Name
+	if event.Severity == "" {
+		event.Severity = SeverityInfo
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	return event
+}
+
+// Release scopes notifications to a single release, so backends can group them (slack threads them)
+type Release struct {
+	notifiers *Notifiers
+	app       *model.TuberApp
+	id        string
+	tag       string
+	changes   Changes
+	observers []func(*zap.Logger, Event)
+}
+
+// NewReleaseID is a new id for one of an app's releases or rollbacks
+func NewReleaseID(appName string) string {
+	return fmt.Sprintf("%s-%d", appName, time.Now().UnixNano())
+}
+
+// Release starts a group of notifications for an app's release of a tag, under an id from NewReleaseID
+func (n *Notifiers) Release(app *model.TuberApp, id string, tag string, changes Changes) *Release {
+	return &Release{
+		notifiers: n,
+		app:       app,
+		id:        id,
+		tag:       tag,
+		changes:   changes,
+	}
+}
+
+// Observe calls fn with each of the release's events, for anything else following the release's progress
+func (r *Release) Observe(fn func(*zap.Logger, Event)) {
+	r.observers = append(r.observers, fn)
+}
+
+// Notify sends an event as part of the release. Every event links the diff, the commit list only goes out once with release.started.
+func (r *Release) Notify(logger *zap.Logger, event Event) {
+	event.Release = r.id
+	event.Tag = r.tag
+	event.DiffLink = r.changes.DiffLink
+	if event.Type == ReleaseStarted {
+		event.Changes = r.changes
+	}
+	r.notifiers.Notify(logger, r.app, event)
+	for _, observer := range r.observers {
+		observer(logger, event)
+	}
+}
+
+// Progress reports a step of the release to anyone watching the app, without notifying its targets
+func (r *Release) Progress(event Event) {
+	event.Release = r.id
+	event.Tag = r.tag
+	r.notifiers.broadcast(withDefaults(r.app, event))
+}
@@ -0,0 +1,327 @@
+package notify
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/freshly/tuber/graph/model"
+	"github.com/freshly/tuber/pkg/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// testHosts lets notifications reach the httptest servers these tests deliver to
+var testHosts = NewWebhookHosts([]string{"127.0.0.1"})
+
+// smtpStandIn accepts a single message and sends its DATA section down the returned channel
+func smtpStandIn(t *testing.T) (string, chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+		reply("220 localhost")
+
+		var data []string
+		inData := false
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+
+			if inData {
+				if line == "." {
+					inData = false
+					received <- strings.Join(data, "\n")
+					reply("250 ok")
+					continue
+				}
+				data = append(data, line)
+				continue
+			}
+
+			switch strings.ToUpper(strings.SplitN(line, " ", 2)[0]) {
+			case "EHLO", "HELO":
+				reply("250 localhost")
+			case "DATA":
+				inData = true
+				reply("354 go ahead")
+			case "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	return listener.Addr().String(), received
+}
+
+func TestNotifyFansOut(t *testing.T) {
+	webhookEvents := make(chan Event, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event Event
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		webhookEvents <- event
+	}))
+	defer webhook.Close()
+
+	teamsCards := make(chan teamsCard, 1)
+	teams := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var card teamsCard
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&card))
+		teamsCards <- card
+	}))
+	defer teams.Close()
+
+	smtpAddr, emails := smtpStandIn(t)
+
+	notifiers := New(nil, NewWebhook(testHosts), NewTeams(testHosts), NewEmail(smtpAddr, "tuber@example.com", "", ""))
+	app := &model.TuberApp{
+		Name: "potatoes",
+		NotificationTargets: []*model.NotificationTarget{
+			{Type: model.NotificationWebhook, Target: webhook.URL},
+			{Type: model.NotificationTeams, Target: teams.URL},
+			{Type: model.NotificationEmail, Target: "devs@example.com"},
+		},
+	}
+
+	notifiers.Release(app, NewReleaseID(app.Name), "gcr.io/freshly-docker/potatoes:master", Changes{DiffLink: "https://github.com/freshly/potatoes/compare/a...b"}).Notify(zap.NewNop(), Event{
+		Type:     ReleaseFailed,
+		Severity: SeverityError,
+		Message:  "release failed",
+		Detail:   "deployment potatoes timed out",
+	})
+
+	event := <-webhookEvents
+	assert.Equal(t, ReleaseFailed, event.Type)
+	assert.Equal(t, "potatoes", event.App)
+	assert.Equal(t, "gcr.io/freshly-docker/potatoes:master", event.Tag)
+	assert.Equal(t, "https://github.com/freshly/potatoes/compare/a...b", event.DiffLink)
+	assert.NotEmpty(t, event.Release)
+	assert.False(t, event.Time.IsZero())
+
+	card := <-teamsCards
+	assert.Equal(t, "potatoes: release failed", card.Title)
+	assert.Equal(t, teamsColorError, card.ThemeColor)
+	require.Len(t, card.PotentialAction, 1)
+	assert.Equal(t, "https://github.com/freshly/potatoes/compare/a...b", card.PotentialAction[0].Targets[0].URI)
+	assert.Contains(t, card.Text, "<pre>deployment potatoes timed out</pre>")
+
+	email := <-emails
+	assert.Contains(t, email, "To: devs@example.com")
+	assert.Contains(t, email, "Subject: [tuber] potatoes: release failed")
+	assert.Contains(t, email, "deployment potatoes timed out")
+}
+
+func TestWebhookErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	err := NewWebhook(testHosts).Notify(zap.NewNop(), server.URL, Event{Type: BuildStarted})
+	assert.Error(t, err)
+}
+
+func TestWebhookRefusesInternalAddresses(t *testing.T) {
+	delivered := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered = true
+	}))
+	defer server.Close()
+
+	err := NewWebhook(NewWebhookHosts(nil)).Notify(zap.NewNop(), server.URL, Event{Type: BuildStarted})
+	assert.Error(t, err)
+	assert.False(t, delivered)
+}
+
+func TestWebhookDoesNotFollowRedirects(t *testing.T) {
+	redirected := false
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected = true
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	err := NewWebhook(testHosts).Notify(zap.NewNop(), server.URL, Event{Type: BuildStarted})
+	assert.Error(t, err)
+	assert.False(t, redirected)
+}
+
+func TestTeamsEscapesDetail(t *testing.T) {
+	card := teamsMessage(Event{App: "potatoes", Message: "release failed", Detail: "<img src=x onerror=alert(1)>"})
+	assert.Contains(t, card.Text, "<pre>&lt;img src=x onerror=alert(1)&gt;</pre>")
+}
+
+func TestCommitsAreEscaped(t *testing.T) {
+	changes := Changes{Commits: []Commit{{SHA: "abc1234567", Subject: "fix <!channel> & <https://example.com|links>", Author: "Potato <potato@example.com>", URL: "https://github.com/freshly/potatoes/commit/abc1234567"}}}
+
+	assert.Equal(t, "• <https://github.com/freshly/potatoes/commit/abc1234567|abc1234> fix &lt;!channel&gt; &amp; &lt;https://example.com|links&gt; - Potato &lt;potato@example.com&gt;", slackCommits(changes))
+
+	card := teamsMessage(Event{App: "potatoes", Message: "release started", Changes: changes})
+	assert.Contains(t, card.Text, "fix &lt;!channel&gt; &amp; &lt;https://example.com|links&gt; - Potato &lt;potato@example.com&gt;")
+}
+
+func TestWatch(t *testing.T) {
+	webhookEvents := make(chan Event, 10)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event Event
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		webhookEvents <- event
+	}))
+	defer webhook.Close()
+
+	notifiers := New(nil, NewWebhook(testHosts), nil, nil)
+	app := &model.TuberApp{Name: "potatoes", NotificationTargets: []*model.NotificationTarget{{Type: model.NotificationWebhook, Target: webhook.URL}}}
+
+	events, stop := notifiers.Watch("potatoes")
+	others, stopOthers := notifiers.Watch("carrots")
+	defer stopOthers()
+
+	release := notifiers.Release(app, NewReleaseID(app.Name), "gcr.io/freshly-docker/potatoes:master", Changes{})
+	release.Progress(Event{Type: ResourceRolledOut, Resource: "deployment/potatoes", Message: "deployment/potatoes rolled out"})
+	release.Notify(zap.NewNop(), Event{Type: ReleaseSucceeded, Message: "release complete"})
+
+	assert.Equal(t, WatchStarted, (<-events).Type)
+	progress := <-events
+	assert.Equal(t, ResourceRolledOut, progress.Type)
+	assert.Equal(t, "potatoes", progress.App)
+	assert.NotEmpty(t, progress.Release)
+	assert.False(t, progress.Finished())
+	succeeded := <-events
+	assert.Equal(t, ReleaseSucceeded, succeeded.Type)
+	assert.Equal(t, progress.Release, succeeded.Release)
+	assert.True(t, succeeded.Finished())
+	assert.True(t, succeeded.Succeeded())
+
+	assert.Equal(t, ReleaseSucceeded, (<-webhookEvents).Type, "progress shouldn't reach an app's targets")
+	assert.Equal(t, WatchStarted, (<-others).Type)
+	assert.Empty(t, others, "watchers only get their app's events")
+
+	stop()
+	_, open := <-events
+	assert.False(t, open)
+	release.Progress(Event{Type: ResourceRolledOut})
+}
+
+func TestWatched(t *testing.T) {
+	notifiers := New(nil, nil, nil, nil)
+
+	_, ok := notifiers.Watched("potatoes")
+	assert.False(t, ok, "nobody is watching yet")
+
+	_, stop := notifiers.Watch("potatoes")
+	_, stopAgain := notifiers.Watch("potatoes")
+	watched, ok := notifiers.Watched("potatoes")
+	assert.True(t, ok)
+
+	stop()
+	assert.NoError(t, watched.Err(), "someone is still watching")
+	stopAgain()
+	assert.Error(t, watched.Err())
+
+	_, ok = notifiers.Watched("potatoes")
+	assert.False(t, ok)
+}
+
+// stuck is a backend that never finishes delivering
+type stuck chan struct{}
+
+func (s stuck) Notify(*zap.Logger, string, Event) error {
+	<-s
+	return nil
+}
+
+func TestNotifyDoesNotWaitOnBackends(t *testing.T) {
+	backend := make(stuck)
+	defer close(backend)
+
+	notifiers := New(backend, nil, nil, nil)
+	app := &model.TuberApp{Name: "potatoes"}
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < deliveryQueue*2; i++ {
+			notifiers.Notify(zap.NewNop(), app, Event{Type: ReleaseWarning, Message: "slow"})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("notify waited on a stuck backend")
+	}
+}
+
+func TestNotifyDoesNotWaitOnOtherTargets(t *testing.T) {
+	slow := make(chan struct{})
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-slow
+	}))
+	defer slowServer.Close()
+	defer close(slow)
+
+	webhookEvents := make(chan Event, 10)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event Event
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		webhookEvents <- event
+	}))
+	defer webhook.Close()
+
+	notifiers := New(nil, NewWebhook(testHosts), nil, nil)
+	app := &model.TuberApp{Name: "potatoes", NotificationTargets: []*model.NotificationTarget{
+		{Type: model.NotificationWebhook, Target: slowServer.URL},
+		{Type: model.NotificationWebhook, Target: webhook.URL},
+	}}
+	notifiers.Notify(zap.NewNop(), app, Event{Type: ReleaseStarted})
+	notifiers.Notify(zap.NewNop(), app, Event{Type: ReleaseSucceeded})
+
+	for _, eventType := range []string{ReleaseStarted, ReleaseSucceeded} {
+		select {
+		case event := <-webhookEvents:
+			assert.Equal(t, eventType, event.Type)
+		case <-time.After(5 * time.Second):
+			t.Fatal("a slow target held up another target's notifications")
+		}
+	}
+}
+
+func TestSlackForgetsFinishedThreads(t *testing.T) {
+	notifier := NewSlack(slack.New("", false, ""))
+
+	notifier.thread(zap.NewNop(), "potatoes", Event{Type: ReleaseStarted, Release: "potatoes-1"})
+	notifier.thread(zap.NewNop(), "potatoes", Event{Type: ReleaseStarted, Release: "potatoes-2"})
+	assert.Len(t, notifier.threads, 2)
+
+	notifier.thread(zap.NewNop(), "potatoes", Event{Type: ReleaseSkipped, Release: "potatoes-1"})
+	assert.Len(t, notifier.threads, 1, "any finished release's thread is forgotten")
+
+	notifier.threads["potatoes-2/potatoes"].lastUsed = time.Now().Add(-slackThreadTTL - time.Minute)
+	notifier.thread(zap.NewNop(), "potatoes", Event{Type: ReleaseSucceeded, Release: "potatoes-3"})
+	assert.Empty(t, notifier.threads, "threads nobody has used in a while are forgotten")
+}
@@ -0,0 +1,177 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/freshly/tuber/pkg/slack"
+	"go.uber.org/zap"
+)
+
+var slackIcons = map[string]string{
+	ReleaseStarted:            ":game_die:",
+	ReleasePrereleaseFinished: ":heavy_check_mark:",
+	ReleaseCanaryStarted:      ":bird:",
+	ReleaseCanaryDeployed:     ":bird:",
+	ReleaseSucceeded:          ":checkered_flag:",
+	ReleaseFailed:             ":x:",
+	ReleaseRolledBack:         ":rewind:",
+	ReleaseMonitorFailed:      ":loudspeaker:",
+	ReleaseWarning:            ":confused:",
+	ReleaseSkipped:            ":double_vertical_bar:",
+	ReleaseFrozen:             ":snowflake:",
+	ReleaseClusterPaused:      ":octagonal_sign:",
+	ReleaseAwaitingApproval:   ":raised_hand:",
+	ReleaseApproved:           ":white_check_mark:",
+	ReleaseRejected:           ":no_entry_sign:",
+	BuildStarted:              ":package:",
+	BuildSucceeded:            ":white_check_mark:",
+	BuildFailed:               ":bomb:",
+}
+
+// statuses are shown by editing a release thread's parent message, everything else is a reply in the thread
+var slackStatuses = map[string]bool{
+	ReleaseStarted:        true,
+	ReleaseCanaryStarted:  true,
+	ReleaseCanaryDeployed: true,
+	ReleaseSucceeded:      true,
+	ReleaseFailed:         true,
+}
+
+// slackThreadTTL is how long a release's thread is kept without any events, well past the longest release
+const slackThreadTTL = 24 * time.Hour
+
+// Slack posts events to slack channels, threading each release's events under one message kept up to date with its status
+type Slack struct {
+	client  *slack.Client
+	threads map[string]*slackThread
+	mutex   sync.Mutex
+}
+
+type slackThread struct {
+	*slack.Thread
+	lastUsed time.Time
+}
+
+func NewSlack(client *slack.Client) *Slack {
+	return &Slack{
+		client:  client,
+		threads: map[string]*slackThread{},
+	}
+}
+
+func (s *Slack) Notify(logger *zap.Logger, channel string, event Event) error {
+	text := slackIcons[event.Type] + " *" + event.App + "*: " + event.Message
+	if event.DiffLink != "" {
+		text += " - <" + event.DiffLink + "|Compare Diff>"
+	}
+	if event.LogLink != "" {
+		text += " - <" + event.LogLink + "|Logs>"
+	}
+
+	alert := text
+	if event.Severity == SeverityError && event.Release != "" {
+		alert = "<!here> " + alert
+	}
+	if event.Detail != "" {
+		alert += "\n```" + event.Detail + "```"
+	}
+	actions := slackActions(event)
+	commits := slackCommits(event.Changes)
+
+	if event.Release == "" {
+		if commits != "" {
+			alert += "\n" + commits
+		}
+		if len(actions) != 0 {
+			s.client.ActionMessage(logger, alert, channel, actions...)
+			return nil
+		}
+		s.client.Message(logger, alert, channel, slack.MsgOptionDisableLinkUnfurl())
+		return nil
+	}
+
+	thread := s.thread(logger, channel, event)
+	if !slackStatuses[event.Type] {
+		if event.Severity == SeverityError {
+			thread.Alert(alert, actions...)
+		} else {
+			thread.Reply(alert, actions...)
+		}
+		return nil
+	}
+
+	thread.Status(text)
+	if commits != "" {
+		thread.Reply(commits)
+	}
+	if event.Severity == SeverityError {
+		thread.Alert(alert, actions...)
+	}
+	return nil
+}
+
+func slackCommits(changes Changes) string {
+	var lines []string
+	for _, commit := range changes.Commits {
+		sha := "`" + commit.ShortSHA() + "`"
+		if commit.URL != "" {
+			sha = "<" + commit.URL + "|" + commit.ShortSHA() + ">"
+		}
+		lines = append(lines, "• "+sha+" "+slackEscape(commit.Subject)+" - "+slackEscape(commit.Author))
+	}
+	if more := changes.MoreCommits(); more != 0 {
+		lines = append(lines, fmt.Sprintf("...and %d more", more))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// slackEscaper escapes the characters slack reads as markup, so commit subjects can't ping channels or post links of their own
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func slackEscape(text string) string {
+	return slackEscaper.Replace(text)
+}
+
+// thread finds a release's thread, forgetting it once the release has finished.
+// Threads of releases that never finish, like ones tuber restarted during, are forgotten after slackThreadTTL.
+func (s *Slack) thread(logger *zap.Logger, channel string, event Event) *slack.Thread {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	for key, thread := range s.threads {
+		if now.Sub(thread.lastUsed) > slackThreadTTL {
+			delete(s.threads, key)
+		}
+	}
+
+	key := event.Release + "/" + channel
+	thread, ok := s.threads[key]
+	if !ok {
+		thread = &slackThread{Thread: s.client.Thread(logger, channel)}
+		s.threads[key] = thread
+	}
+	thread.lastUsed = now
+	if event.Finished() {
+		delete(s.threads, key)
+	}
+	return thread.Thread
+}
+
+func slackActions(event Event) []slack.Action {
+	var actions []slack.Action
+	for _, action := range event.Actions {
+		switch action {
+		case ActionRollback:
+			actions = append(actions, slack.RollbackButton(event.App))
+		case ActionResume:
+			actions = append(actions, slack.ResumeButton(event.App))
+		case ActionRetry:
+			actions = append(actions, slack.RetryButton(event.App, event.Tag))
+		}
+	}
+	return actions
+}
@@ -0,0 +1,83 @@
+package notify
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const (
+	teamsColorInfo  = "2EB67D"
+	teamsColorError = "E01E5A"
+)
+
+// Teams posts events to microsoft teams incoming webhooks, as message cards
+type Teams struct {
+	hosts *WebhookHosts
+}
+
+func NewTeams(hosts *WebhookHosts) *Teams {
+	return &Teams{hosts: hosts}
+}
+
+type teamsCard struct {
+	Type            string        `json:"@type"`
+	Context         string        `json:"@context"`
+	Summary         string        `json:"summary"`
+	ThemeColor      string        `json:"themeColor"`
+	Title           string        `json:"title"`
+	Text            string        `json:"text,omitempty"`
+	PotentialAction []teamsAction `json:"potentialAction,omitempty"`
+}
+
+type teamsAction struct {
+	Type    string        `json:"@type"`
+	Name    string        `json:"name"`
+	Targets []teamsTarget `json:"targets"`
+}
+
+type teamsTarget struct {
+	OS  string `json:"os"`
+	URI string `json:"uri"`
+}
+
+func (t *Teams) Notify(logger *zap.Logger, url string, event Event) error {
+	return postJSON(t.hosts.Client(url), url, teamsMessage(event))
+}
+
+func teamsMessage(event Event) teamsCard {
+	card := teamsCard{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		Summary:    event.App + ": " + event.Message,
+		ThemeColor: teamsColorInfo,
+		Title:      event.App + ": " + event.Message,
+	}
+	if event.Severity == SeverityError {
+		card.ThemeColor = teamsColorError
+	}
+	var text []string
+	if event.Detail != "" {
+		text = append(text, "<pre>"+html.EscapeString(event.Detail)+"</pre>")
+	}
+	for _, commit := range event.Commits {
+		text = append(text, "- ["+commit.ShortSHA()+"]("+commit.URL+") "+html.EscapeString(commit.Subject)+" - "+html.EscapeString(commit.Author))
+	}
+	if more := event.MoreCommits(); more != 0 {
+		text = append(text, fmt.Sprintf("- ...and %d more", more))
+	}
+	card.Text = strings.Join(text, "\n")
+	if event.DiffLink != "" {
+		card.PotentialAction = append(card.PotentialAction, teamsLink("Compare Diff", event.DiffLink))
+	}
+	if event.LogLink != "" {
+		card.PotentialAction = append(card.PotentialAction, teamsLink("Logs", event.LogLink))
+	}
+	return card
+}
+
+func teamsLink(name string, uri string) teamsAction {
+	return teamsAction{Type: "OpenUri", Name: name, Targets: []teamsTarget{{OS: "default", URI: uri}}}
+}
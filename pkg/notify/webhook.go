@@ -0,0 +1,41 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// Webhook posts events as json to any url that hosts allows
+type Webhook struct {
+	hosts *WebhookHosts
+}
+
+func NewWebhook(hosts *WebhookHosts) *Webhook {
+	return &Webhook{hosts: hosts}
+}
+
+func (w *Webhook) Notify(logger *zap.Logger, url string, event Event) error {
+	return postJSON(w.hosts.Client(url), url, event)
+}
+
+func postJSON(client *http.Client, url string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("notification to %s returned %s", res.Request.URL.Host, res.Status)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package oauth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+var apiTokenCtxKey oauthCtxKey = "apiToken"
+
+// APITokenAuth is what a request authenticated with a tuber api token may do.
+// Apps of * mean every app.
+type APITokenAuth struct {
+	ID     string
+	Name   string
+	Apps   []string
+	Scopes []string
+}
+
+// BearerToken returns a request's Authorization bearer token, if it has one
+func BearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return "", false
+	}
+	return strings.TrimPrefix(header, "Bearer "), true
+}
+
+// WithAPIToken marks a context as authenticated with an api token. Its identity is the token's name.
+func WithAPIToken(ctx context.Context, auth APITokenAuth) context.Context {
+	ctx = context.WithValue(ctx, identityCtxKey, "token:"+auth.Name)
+	return context.WithValue(ctx, apiTokenCtxKey, auth)
+}
+
+// GetAPIToken returns the api token set by WithAPIToken
+func GetAPIToken(ctx context.Context) (APITokenAuth, bool) {
+	auth, ok := ctx.Value(apiTokenCtxKey).(APITokenAuth)
+	return auth, ok
+}
+
+// AllowsApp is true when the token is scoped to an app
+func (a APITokenAuth) AllowsApp(appName string) bool {
+	for _, app := range a.Apps {
+		if app == "*" || app == appName {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows is true when the token is scoped to an app, and has a scope. An empty scope only needs the app.
+func (a APITokenAuth) Allows(appName string, scope string) bool {
+	if !a.AllowsApp(appName) {
+		return false
+	}
+	if scope == "" {
+		return true
+	}
+	for _, s := range a.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
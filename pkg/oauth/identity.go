@@ -0,0 +1,130 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"google.golang.org/api/idtoken"
+)
+
+// iapAssertionHeader is identity-aware proxy's signed jwt, set on requests that came through it
+const iapAssertionHeader = "X-Goog-IAP-JWT-Assertion"
+
+var identityCtxKey oauthCtxKey = "identity"
+var impersonatedCtxKey oauthCtxKey = "impersonated"
+var iapIdentityCtxKey oauthCtxKey = "iapIdentity"
+
+// validateIAPAssertion checks IAP's signature, audience and expiry. It's a var for tests.
+var validateIAPAssertion = idtoken.Validate
+
+var tokenInfoURL = "https://oauth2.googleapis.com/tokeninfo"
+var tokenInfoClient = &http.Client{Timeout: 5 * time.Second}
+
+// emails resolved from access tokens, so tokeninfo is hit once per token.
+// google access tokens last an hour, so entries are dropped after that.
+var tokenEmails sync.Map
+
+const tokenEmailTTL = time.Hour
+
+type tokenEmail struct {
+	email   string
+	expires time.Time
+}
+
+// withIAPIdentity records who identity-aware proxy says made a request. Only IAP's signed assertion is trusted, and only when
+// an IAP audience is configured - its plain headers are easily set by anything that reaches tuber without going through IAP.
+func (a *Authenticator) withIAPIdentity(r *http.Request) *http.Request {
+	assertion := r.Header.Get(iapAssertionHeader)
+	if a.iapAudience == "" || assertion == "" {
+		return r
+	}
+
+	payload, err := validateIAPAssertion(r.Context(), assertion, a.iapAudience)
+	if err != nil {
+		return r
+	}
+	email, ok := payload.Claims["email"].(string)
+	if !ok || email == "" {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), iapIdentityCtxKey, email))
+}
+
+// WithImpersonation marks a context as acting on behalf of an identity that has no access token,
+// like a slack user. Authorization checks impersonate the identity instead of using a token.
+func WithImpersonation(ctx context.Context, identity string) context.Context {
+	ctx = context.WithValue(ctx, identityCtxKey, identity)
+	return context.WithValue(ctx, impersonatedCtxKey, identity)
+}
+
+// Impersonated returns the identity set by WithImpersonation
+func Impersonated(ctx context.Context) (string, bool) {
+	identity, ok := ctx.Value(impersonatedCtxKey).(string)
+	return identity, ok && identity != ""
+}
+
+// Identity returns who made a request, for recording who did what.
+// It's an identity set on the context, like an impersonated slack user or an api token, then the email on the request's google access token,
+// then identity-aware proxy's verified identity.
+func Identity(ctx context.Context) (string, error) {
+	if identity, ok := ctx.Value(identityCtxKey).(string); ok && identity != "" {
+		return identity, nil
+	}
+
+	accessToken, err := GetAccessToken(ctx)
+	if err != nil {
+		if identity, ok := ctx.Value(iapIdentityCtxKey).(string); ok && identity != "" {
+			return identity, nil
+		}
+		return "", err
+	}
+
+	if cached, ok := tokenEmails.Load(accessToken); ok && time.Now().Before(cached.(tokenEmail).expires) {
+		return cached.(tokenEmail).email, nil
+	}
+
+	res, err := tokenInfoClient.Get(tokenInfoURL + "?access_token=" + url.QueryEscape(accessToken))
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("tokeninfo returned %s", res.Status)
+	}
+
+	var info struct {
+		Email string `json:"email"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&info)
+	if err != nil {
+		return "", err
+	}
+	if info.Email == "" {
+		return "", fmt.Errorf("access token has no email scope")
+	}
+
+	now := time.Now()
+	tokenEmails.Range(func(key, value interface{}) bool {
+		if now.After(value.(tokenEmail).expires) {
+			tokenEmails.Delete(key)
+		}
+		return true
+	})
+	tokenEmails.Store(accessToken, tokenEmail{email: info.Email, expires: now.Add(tokenEmailTTL)})
+	return info.Email, nil
+}
+
+// IdentityOrUnknown is Identity for display, where a missing identity shouldn't fail the request
+func IdentityOrUnknown(ctx context.Context) string {
+	identity, err := Identity(ctx)
+	if err != nil {
+		return "unknown"
+	}
+	return identity
+}
@@ -0,0 +1,40 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/idtoken"
+)
+
+func TestIAPIdentity(t *testing.T) {
+	validateIAPAssertion = func(ctx context.Context, assertion string, audience string) (*idtoken.Payload, error) {
+		if assertion != "signed" || audience != "/projects/1/global/backendServices/2" {
+			return nil, fmt.Errorf("invalid assertion")
+		}
+		return &idtoken.Payload{Claims: map[string]interface{}{"email": "someone@freshly.com"}}, nil
+	}
+	defer func() { validateIAPAssertion = idtoken.Validate }()
+
+	auth := NewAuthenticator("", "", "", "", "", "/projects/1/global/backendServices/2")
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-Goog-Authenticated-User-Email", "accounts.google.com:someone-else@freshly.com")
+	_, err := Identity(auth.withIAPIdentity(r).Context())
+	assert.Error(t, err, "the plain header isn't trusted")
+
+	r.Header.Set(iapAssertionHeader, "forged")
+	_, err = Identity(auth.withIAPIdentity(r).Context())
+	assert.Error(t, err)
+
+	r.Header.Set(iapAssertionHeader, "signed")
+	identity, err := Identity(auth.withIAPIdentity(r).Context())
+	assert.NoError(t, err)
+	assert.Equal(t, "someone@freshly.com", identity)
+
+	unconfigured := NewAuthenticator("", "", "", "", "", "")
+	_, err = Identity(unconfigured.withIAPIdentity(r).Context())
+	assert.Error(t, err, "without an audience, iap is ignored")
+}
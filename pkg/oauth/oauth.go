@@ -15,9 +15,11 @@ type Authenticator struct {
 	oauthConfig   *oauth2.Config
 	oauthStateKey string
 	cookiePath    string
+	iapAudience   string
 }
 
-func NewAuthenticator(oauthRedirectUrl string, oauthClientSecret string, oauthClientID string, oauthStateKey string, cookiePath string) *Authenticator {
+// NewAuthenticator builds an Authenticator. iapAudience is the audience of identity-aware proxy's signed header - without it, IAP identities are ignored.
+func NewAuthenticator(oauthRedirectUrl string, oauthClientSecret string, oauthClientID string, oauthStateKey string, cookiePath string, iapAudience string) *Authenticator {
 	config := &oauth2.Config{
 		RedirectURL:  oauthRedirectUrl,
 		ClientID:     oauthClientID,
@@ -29,6 +31,7 @@ func NewAuthenticator(oauthRedirectUrl string, oauthClientSecret string, oauthCl
 		oauthConfig:   config,
 		oauthStateKey: oauthStateKey,
 		cookiePath:    cookiePath,
+		iapAudience:   iapAudience,
 	}
 }
 
@@ -70,7 +73,7 @@ func (a *Authenticator) TrySetHeaderAuthContext(request *http.Request) (*http.Re
 		return request, false
 	}
 	request = request.WithContext(context.WithValue(request.Context(), accessTokenCtxKey, accessTokenHeaderValue))
-	return request, true
+	return a.withIAPIdentity(request), true
 }
 
 // TrySetCookieAuthContext - gqlgen gives us the context in the resolver, but does not expose any way to alter it midflight
@@ -128,6 +131,7 @@ func (a *Authenticator) TrySetCookieAuthContext(w http.ResponseWriter, r *http.R
 	r = r.WithContext(context.WithValue(r.Context(), refreshTokenCtxKey, refreshToken))
 	r = r.WithContext(context.WithValue(r.Context(), accessTokenCtxKey, accessToken))
 	r = r.WithContext(context.WithValue(r.Context(), accessTokenExpirationCtxKey, accessTokenExpiration))
+	r = a.withIAPIdentity(r)
 
 	if refreshed {
 		encodedRefresh, err := sc.Encode(RefreshTokenCookieKey(), refreshToken)
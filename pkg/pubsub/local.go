@@ -0,0 +1,113 @@
+package pubsub
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/freshly/tuber/pkg/report"
+	"go.uber.org/zap"
+)
+
+const localBufferSize = 100
+
+// Local source names, and the admin server paths they're published to
+const (
+	LocalImages = "images"
+	LocalBuilds = "builds"
+)
+
+// ErrLocalSourceFull is returned when a local source's buffer is full, rather than waiting on the processor
+var ErrLocalSourceFull = errors.New("local source is full, try again later")
+
+// LocalSource is an in-memory Source for running tuber without google pubsub.
+// Messages are published to it directly, or over http through ServeHTTP.
+type LocalSource struct {
+	ctx       context.Context
+	logger    *zap.Logger
+	name      string
+	token     string
+	processor MessageProcessor
+	messages  chan Message
+}
+
+// NewLocalSource constructs a LocalSource. Http publishes must send token as a bearer token, and are refused if it's empty.
+func NewLocalSource(ctx context.Context, logger *zap.Logger, name string, token string, processor MessageProcessor) *LocalSource {
+	return &LocalSource{
+		ctx:       ctx,
+		logger:    logger.With(zap.String("context", "localSource"), zap.String("source", name)),
+		name:      name,
+		token:     token,
+		processor: processor,
+		messages:  make(chan Message, localBufferSize),
+	}
+}
+
+// Publish queues a message for the source's processor, or returns ErrLocalSourceFull if too many are already queued
+func (l *LocalSource) Publish(message Message) error {
+	if l.ctx.Err() != nil {
+		return l.ctx.Err()
+	}
+	select {
+	case l.messages <- message:
+		return nil
+	default:
+		return ErrLocalSourceFull
+	}
+}
+
+// Start pipes published messages to the processor the same way a Listener would
+func (l *LocalSource) Start() error {
+	l.logger.Debug("local source starting")
+	accepter, durable := l.processor.(MessageAccepter)
+
+	for {
+		select {
+		case <-l.ctx.Done():
+			l.logger.Debug("local source stopped")
+			return nil
+		case message := <-l.messages:
+			if !durable {
+				go l.processor.Process(message)
+				continue
+			}
+			err := accepter.Accept(message)
+			if err != nil {
+				l.logger.Error("failed to accept local message", zap.Error(err))
+				report.Error(err, report.Scope{"context": "localMessageAccept", "source": l.name})
+			}
+		}
+	}
+}
+
+// ServeHTTP publishes a json message body, in the same format pubsub messages are sent in
+func (l *LocalSource) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if l.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(l.token)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var message Message
+	err := json.NewDecoder(r.Body).Decode(&message)
+	if err != nil {
+		http.Error(w, "invalid message: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = l.Publish(message)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
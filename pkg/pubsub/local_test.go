@@ -0,0 +1,86 @@
+package pubsub
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type testAccepter struct {
+	accepted chan Message
+}
+
+func (t testAccepter) Process(message Message) {}
+
+func (t testAccepter) Accept(message Message) error {
+	t.accepted <- message
+	return nil
+}
+
+func TestLocalSource(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	accepter := testAccepter{accepted: make(chan Message, 1)}
+	source := NewLocalSource(ctx, zap.NewNop(), LocalImages, "secret", accepter)
+	go source.Start()
+
+	tests := []struct {
+		name   string
+		method string
+		token  string
+		body   string
+		status int
+	}{
+		{name: "wrong method", method: http.MethodGet, token: "secret", status: http.StatusMethodNotAllowed},
+		{name: "bad token", method: http.MethodPost, token: "nope", body: `{}`, status: http.StatusUnauthorized},
+		{name: "bad body", method: http.MethodPost, token: "secret", body: `{`, status: http.StatusBadRequest},
+		{name: "accepted", method: http.MethodPost, token: "secret", body: `{"digest":"repo@sha256:abc","tag":"repo:master"}`, status: http.StatusAccepted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/events/local/images", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+			source.ServeHTTP(rec, req)
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+
+	select {
+	case message := <-accepter.accepted:
+		assert.Equal(t, Message{Digest: "repo@sha256:abc", Tag: "repo:master"}, message)
+	case <-time.After(5 * time.Second):
+		t.Fatal("message was never accepted")
+	}
+}
+
+func TestLocalSourceWithoutToken(t *testing.T) {
+	source := NewLocalSource(context.Background(), zap.NewNop(), LocalImages, "", testAccepter{})
+	req := httptest.NewRequest(http.MethodPost, "/events/local/images", strings.NewReader(`{}`))
+	req.Header.Set("Authorization", "Bearer ")
+	rec := httptest.NewRecorder()
+	source.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestLocalSourceFull(t *testing.T) {
+	source := NewLocalSource(context.Background(), zap.NewNop(), LocalImages, "secret", testAccepter{})
+	for i := 0; i < localBufferSize; i++ {
+		assert.Nil(t, source.Publish(Message{}))
+	}
+	assert.Equal(t, ErrLocalSourceFull, source.Publish(Message{}))
+
+	req := httptest.NewRequest(http.MethodPost, "/events/local/images", strings.NewReader(`{}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	source.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
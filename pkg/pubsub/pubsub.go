@@ -6,7 +6,10 @@ import (
 	"errors"
 
 	"github.com/freshly/tuber/pkg/core"
+	"github.com/freshly/tuber/pkg/metrics"
 	"github.com/freshly/tuber/pkg/report"
+	"github.com/freshly/tuber/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
 
 	"cloud.google.com/go/pubsub"
 	"go.uber.org/zap"
@@ -25,10 +28,23 @@ type Listener struct {
 	processor        MessageProcessor
 }
 
+// Source feeds messages to a MessageProcessor until its context is cancelled.
+// Listener reads from a google pubsub subscription, LocalSource from memory.
+type Source interface {
+	Start() error
+}
+
 type MessageProcessor interface {
 	Process(Message)
 }
 
+// MessageAccepter is a MessageProcessor that durably stores messages to process later.
+// Listeners only ack messages once Accept succeeds, and leave them for redelivery otherwise.
+type MessageAccepter interface {
+	MessageProcessor
+	Accept(Message) error
+}
+
 // NewListener is a constructor for Listener with field validation
 func NewListener(ctx context.Context, logger *zap.Logger, pubsubProject string, subscriptionName string,
 	credentials []byte, clusterData *core.ClusterData, processor MessageProcessor) (*Listener, error) {
@@ -66,6 +82,9 @@ type Message struct {
 		BranchName string `json:"BRANCH_NAME"`
 		RepoName   string `json:"REPO_NAME"`
 	} `json:"substitutions"`
+
+	// Trace carries the span the message was received in, through the inbox to processing
+	Trace map[string]string `json:"trace,omitempty"`
 }
 
 // Start starts up the pubsub server and pipes incoming messages to the Listener's events.Processor
@@ -87,17 +106,52 @@ func (l *Listener) Start() error {
 	listenLogger.Debug("pubsub server starting")
 	listenLogger.Debug("subscription options", zap.Reflect("options", subscription.ReceiveSettings))
 
+	accepter, durable := l.processor.(MessageAccepter)
+
+	received := metrics.PubsubMessagesReceived.WithLabelValues(l.subscriptionName)
+
 	err = subscription.Receive(l.ctx, func(ctx context.Context, pubsubMessage *pubsub.Message) {
-		pubsubMessage.Ack()
+		received.Inc()
+		if !durable {
+			pubsubMessage.Ack()
+		}
 		// GCR pubsubMessage.Data
 		// {"action":"INSERT","digest":"gcr.io/freshly-docker/freshly@sha256:17f4431497a07da98bc16e599ef9d38afb9817049b6e98b71b7e321b946a24d4",
 		// "tag":"gcr.io/freshly-docker/freshly:PIG-267-refactor-email-service"}
 
 		var message Message
-		err = json.Unmarshal(pubsubMessage.Data, &message)
-		if err != nil {
-			listenLogger.Warn("failed to unmarshal pubsub message", zap.Error(err))
-			report.Error(err, report.Scope{"context": "messageProcessing"})
+		unmarshalErr := json.Unmarshal(pubsubMessage.Data, &message)
+
+		ctx, span := tracing.Start(ctx, "pubsub.receive",
+			attribute.String("pubsub.subscription", l.subscriptionName),
+			tracing.Digest(message.Digest),
+			tracing.Tag(message.Tag),
+		)
+		defer span.End()
+		message.Trace = tracing.Carrier(ctx)
+
+		if unmarshalErr != nil {
+			// redelivery won't make it parse
+			metrics.PubsubMessagesIgnored.WithLabelValues(metrics.IgnoredUnparseable).Inc()
+			if durable {
+				pubsubMessage.Ack()
+			}
+			span.RecordError(unmarshalErr)
+			listenLogger.Warn("failed to unmarshal pubsub message", zap.Error(unmarshalErr))
+			report.Error(unmarshalErr, report.Scope{"context": "messageProcessing"})
+			return
+		}
+
+		if durable {
+			acceptErr := accepter.Accept(message)
+			if acceptErr != nil {
+				pubsubMessage.Nack()
+				span.RecordError(acceptErr)
+				listenLogger.Error("failed to accept pubsub message, leaving for redelivery", zap.Error(acceptErr))
+				report.Error(acceptErr, report.Scope{"context": "messageAccept"})
+				return
+			}
+			pubsubMessage.Ack()
 			return
 		}
 
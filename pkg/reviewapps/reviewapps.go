@@ -21,18 +21,18 @@ import (
 
 // NewReviewAppSetup replicates a namespace and its roles, rolebindings, and opaque secrets after removing their non-generic metadata.
 // Also renames source app name matches across all relevant resources.
-func NewReviewAppSetup(sourceApp string, reviewApp string) error {
-	err := copyNamespace(sourceApp, reviewApp)
+func NewReviewAppSetup(ctx context.Context, sourceApp string, reviewApp string) error {
+	err := copyNamespace(ctx, sourceApp, reviewApp)
 	if err != nil {
 		return err
 	}
 	for _, kind := range []string{"roles", "rolebindings"} {
-		rolesErr := copyResources(kind, sourceApp, reviewApp)
+		rolesErr := copyResources(ctx, kind, sourceApp, reviewApp)
 		if rolesErr != nil {
 			return rolesErr
 		}
 	}
-	err = copyResources("secrets", sourceApp, reviewApp, "--field-selector", "type=Opaque")
+	err = copyResources(ctx, "secrets", sourceApp, reviewApp, "--field-selector", "type=Opaque")
 	if err != nil {
 		return err
 	}
@@ -70,7 +70,7 @@ func CreateReviewApp(ctx context.Context, db *core.DB, l *zap.Logger, sourceApp
 
 	logger.Info("creating review app resources")
 
-	err = NewReviewAppSetup(sourceApp.Name, reviewAppName)
+	err = NewReviewAppSetup(ctx, sourceApp.Name, reviewAppName)
 	if err != nil {
 		return "", err
 	}
@@ -161,17 +161,18 @@ func CreateReviewApp(ctx context.Context, db *core.DB, l *zap.Logger, sourceApp
 	}
 
 	reviewApp := &model.TuberApp{
-		CloudSourceRepo:   sourceApp.CloudSourceRepo,
-		ImageTag:          imageTag,
-		Name:              reviewAppName,
-		Paused:            false,
-		ReviewApp:         true,
-		SlackChannel:      sourceApp.SlackChannel,
-		SourceAppName:     sourceApp.Name,
-		State:             nil,
-		TriggerID:         triggerID,
-		Vars:              vars,
-		ExcludedResources: reviewAppExclusions,
+		CloudSourceRepo:     sourceApp.CloudSourceRepo,
+		ImageTag:            imageTag,
+		Name:                reviewAppName,
+		Paused:              false,
+		ReviewApp:           true,
+		SlackChannel:        sourceApp.SlackChannel,
+		NotificationTargets: sourceApp.NotificationTargets,
+		SourceAppName:       sourceApp.Name,
+		State:               nil,
+		TriggerID:           triggerID,
+		Vars:                vars,
+		ExcludedResources:   reviewAppExclusions,
 	}
 
 	err = db.SaveApp(reviewApp)
@@ -210,7 +211,7 @@ func DeleteReviewApp(ctx context.Context, db *core.DB, reviewAppName string, cre
 		}
 	}
 
-	return core.DestroyTuberApp(db, app)
+	return core.DestroyTuberApp(ctx, db, app)
 }
 
 // Kubernetes & DNS1123 Rules
@@ -269,8 +270,8 @@ func ReviewAppName(appName string, branch string) string {
 	return makeDNS1123Compatible(fmt.Sprintf("%s-%s", appName, branch))
 }
 
-func copyNamespace(sourceApp string, reviewApp string) error {
-	resource, err := k8s.Get("namespace", sourceApp, sourceApp, "-o", "json")
+func copyNamespace(ctx context.Context, sourceApp string, reviewApp string) error {
+	resource, err := k8s.Get(ctx, "namespace", sourceApp, sourceApp, "-o", "json")
 	if err != nil {
 		return err
 	}
@@ -278,20 +279,20 @@ func copyNamespace(sourceApp string, reviewApp string) error {
 	if err != nil {
 		return err
 	}
-	err = k8s.Apply(resource, reviewApp)
+	err = k8s.Apply(ctx, resource, reviewApp)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func copyResources(kind string, sourceApp string, reviewApp string, args ...string) error {
-	data, err := duplicatedResources(kind, sourceApp, reviewApp, args...)
+func copyResources(ctx context.Context, kind string, sourceApp string, reviewApp string, args ...string) error {
+	data, err := duplicatedResources(ctx, kind, sourceApp, reviewApp, args...)
 	if err != nil {
 		return err
 	}
 	for _, resource := range data {
-		applyErr := k8s.Apply(resource, reviewApp)
+		applyErr := k8s.Apply(ctx, resource, reviewApp)
 		if applyErr != nil {
 			return applyErr
 		}
@@ -299,8 +300,8 @@ func copyResources(kind string, sourceApp string, reviewApp string, args ...stri
 	return nil
 }
 
-func duplicatedResources(kind string, sourceApp string, reviewApp string, args ...string) ([][]byte, error) {
-	list, err := k8s.ListKind(kind, sourceApp, args...)
+func duplicatedResources(ctx context.Context, kind string, sourceApp string, reviewApp string, args ...string) ([][]byte, error) {
+	list, err := k8s.ListKind(ctx, kind, sourceApp, args...)
 	if err != nil {
 		return nil, err
 	}
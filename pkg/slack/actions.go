@@ -0,0 +1,138 @@
+package slack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// Interactive button action ids, handled by the admin server's slack endpoint
+const (
+	ActionRollback = "rollback"
+	ActionResume   = "resume"
+	ActionRetry    = "retry"
+)
+
+// ActionValue is what a button carries back to tuber when it's clicked
+type ActionValue struct {
+	App string `json:"app"`
+	Tag string `json:"tag,omitempty"`
+}
+
+// Action is a button on a message
+type Action struct {
+	ID    string
+	Text  string
+	Style slack.Style
+	Value ActionValue
+}
+
+func RollbackButton(appName string) Action {
+	return Action{ID: ActionRollback, Text: "Roll back", Style: slack.StyleDanger, Value: ActionValue{App: appName}}
+}
+
+func ResumeButton(appName string) Action {
+	return Action{ID: ActionResume, Text: "Resume", Style: slack.StylePrimary, Value: ActionValue{App: appName}}
+}
+
+func RetryButton(appName string, tag string) Action {
+	return Action{ID: ActionRetry, Text: "Retry", Value: ActionValue{App: appName, Tag: tag}}
+}
+
+// ActionMessage sends a message with buttons under it
+func (c *Client) ActionMessage(logger *zap.Logger, message string, channel string, actions ...Action) string {
+	return c.Message(logger, message, channel, actionBlocks(logger, message, actions...))
+}
+
+// actionBlocks lays out a message with buttons under it. The plain message is kept as the notification fallback.
+func actionBlocks(logger *zap.Logger, message string, actions ...Action) slack.MsgOption {
+	var buttons []slack.BlockElement
+	for _, action := range actions {
+		value, err := json.Marshal(action.Value)
+		if err != nil {
+			logger.Error("failed to marshal slack action value", zap.Error(err))
+			continue
+		}
+		button := slack.NewButtonBlockElement(action.ID, string(value), slack.NewTextBlockObject(slack.PlainTextType, action.Text, false, false))
+		button.Style = action.Style
+		buttons = append(buttons, button)
+	}
+
+	blocks := []slack.Block{slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, message, false, false), nil, nil)}
+	if len(buttons) != 0 {
+		blocks = append(blocks, slack.NewActionBlock("", buttons...))
+	}
+
+	return slack.MsgOptionBlocks(blocks...)
+}
+
+// Response is posted to an interaction's response url, to reply to or replace the message it came from
+type Response struct {
+	Text            string `json:"text"`
+	ResponseType    string `json:"response_type,omitempty"`
+	ReplaceOriginal bool   `json:"replace_original"`
+}
+
+var responseClient = &http.Client{Timeout: 10 * time.Second}
+
+// Respond posts to a response url from a button click or slash command
+func Respond(responseURL string, response Response) error {
+	body, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+
+	res, err := responseClient.Post(responseURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack response url returned %s", res.Status)
+	}
+	return nil
+}
+
+// Users maps slack user ids to the identities tuber authorizes as.
+// Explicit overrides win, otherwise the slack profile's email is used (requires the users:read.email scope).
+type Users struct {
+	client    *Client
+	overrides map[string]string
+	cache     sync.Map
+}
+
+func NewUsers(client *Client, overrides map[string]string) *Users {
+	return &Users{client: client, overrides: overrides}
+}
+
+func (u *Users) Identity(userID string) (string, error) {
+	if identity, ok := u.overrides[userID]; ok {
+		return identity, nil
+	}
+
+	if identity, ok := u.cache.Load(userID); ok {
+		return identity.(string), nil
+	}
+
+	if u.client == nil {
+		return "", fmt.Errorf("slack user %s isn't mapped in TUBER_SLACK_USERS, and there's no slack client to look them up with", userID)
+	}
+
+	user, err := u.client.client.GetUserInfo(userID)
+	if err != nil {
+		return "", err
+	}
+	if user.Profile.Email == "" {
+		return "", fmt.Errorf("slack user %s has no email, map them with TUBER_SLACK_USERS", userID)
+	}
+
+	u.cache.Store(userID, user.Profile.Email)
+	return user.Profile.Email, nil
+}
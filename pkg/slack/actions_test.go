@@ -0,0 +1,18 @@
+package slack
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsersWithoutClient(t *testing.T) {
+	users := NewUsers(nil, map[string]string{"U123": "potato@example.com"})
+
+	identity, err := users.Identity("U123")
+	assert.NoError(t, err)
+	assert.Equal(t, "potato@example.com", identity)
+
+	_, err = users.Identity("U456")
+	assert.Error(t, err, "unmapped users can't be looked up without a slack client")
+}
@@ -1,6 +1,10 @@
 package slack
 
 import (
+	"net/http"
+	"time"
+
+	"github.com/freshly/tuber/pkg/metrics"
 	"github.com/slack-go/slack"
 	"go.uber.org/zap"
 )
@@ -15,44 +19,67 @@ type Client struct {
 
 func New(key string, enabled bool, catchAllChannel string) *Client {
 	return &Client{
-		client:          slack.New(key),
+		client:          slack.New(key, slack.OptionHTTPClient(&http.Client{Timeout: 10 * time.Second})),
 		enabled:         enabled,
 		catchAllChannel: catchAllChannel,
 	}
 }
 
-func (c *Client) Message(logger *zap.Logger, message string, channel string, opts ...slack.MsgOption) {
+// Message posts a message, returning its timestamp for threading and updates. The timestamp is empty if nothing was posted.
+func (c *Client) Message(logger *zap.Logger, message string, channel string, opts ...slack.MsgOption) string {
+	_, timestamp := c.post(logger, message, channel, opts...)
+	return timestamp
+}
+
+// post returns the channel id alongside the timestamp, which chat.update needs in place of a channel name
+func (c *Client) post(logger *zap.Logger, message string, channel string, opts ...slack.MsgOption) (string, string) {
 	messageLogger := logger.With(zap.String("slackMessage", message), zap.String("slackChannel", channel))
 	messageLogger.Debug("slack message triggered")
 
 	if !c.enabled {
 		messageLogger.Debug("slack message would have sent but slack is not enabled")
-		return
+		return "", ""
 	}
 
 	if channel == "" {
-		c.send(messageLogger, c.catchAllChannel, message, opts...)
-		return
+		return c.send(messageLogger, c.catchAllChannel, message, opts...)
 	}
 
-	c.send(messageLogger, channel, message, opts...)
+	return c.send(messageLogger, channel, message, opts...)
 }
 
-func (c *Client) send(logger *zap.Logger, channel string, message string, opts ...slack.MsgOption) {
+func (c *Client) send(logger *zap.Logger, channel string, message string, opts ...slack.MsgOption) (string, string) {
 	channelLogger := logger.With(zap.String("slackChannel", channel))
 	channelLogger.Debug("sending slack message")
 
 	opts = append(opts, slack.MsgOptionText(message, false))
 
-	_, _, err := c.client.PostMessage(channel, opts...)
+	channelID, timestamp, err := c.client.PostMessage(channel, opts...)
 	if err != nil {
+		metrics.SlackFailures.Inc()
 		if err.Error() == "channel_not_found" {
 			channelLogger.Error("channel not found, check configured channel and ensure tuber is a member", zap.Error(err))
-			return
+			return "", ""
 		}
 		channelLogger.Error("error sending slack message", zap.Error(err))
-		return
+		return "", ""
 	}
 
 	channelLogger.Debug("posted slack message without error")
+	return channelID, timestamp
+}
+
+// update edits a posted message in place
+func (c *Client) update(logger *zap.Logger, channelID string, timestamp string, message string, opts ...slack.MsgOption) error {
+	if !c.enabled {
+		logger.Debug("slack update would have sent but slack is not enabled", zap.String("slackMessage", message))
+		return nil
+	}
+
+	opts = append(opts, slack.MsgOptionText(message, false))
+	_, _, _, err := c.client.UpdateMessage(channelID, timestamp, opts...)
+	if err != nil {
+		metrics.SlackFailures.Inc()
+	}
+	return err
 }
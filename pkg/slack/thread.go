@@ -0,0 +1,72 @@
+package slack
+
+import (
+	"sync"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// Thread is one conversation per release: a parent message kept up to date with the release's status,
+// with details posted as replies underneath it rather than as separate channel messages.
+type Thread struct {
+	client    *Client
+	logger    *zap.Logger
+	channel   string
+	channelID string
+	timestamp string
+	mutex     sync.Mutex
+}
+
+// Thread starts a conversation in a channel. Nothing is posted until the first Status.
+func (c *Client) Thread(logger *zap.Logger, channel string) *Thread {
+	return &Thread{
+		client:  c,
+		logger:  logger,
+		channel: channel,
+	}
+}
+
+// Status posts the parent message the first time, then edits it in place with chat.update
+func (t *Thread) Status(message string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.timestamp != "" {
+		err := t.client.update(t.logger, t.channelID, t.timestamp, message)
+		if err == nil {
+			return
+		}
+		t.logger.Error("error updating slack thread status, posting a new one", zap.Error(err))
+	}
+
+	t.channelID, t.timestamp = t.client.post(t.logger, message, t.channel)
+}
+
+// Reply posts details under the parent, or to the channel if there's no parent to reply to
+func (t *Thread) Reply(message string, actions ...Action) {
+	t.reply(message, false, actions...)
+}
+
+// Alert is a Reply that's also sent to the channel, for failures people need to see without opening the thread
+func (t *Thread) Alert(message string, actions ...Action) {
+	t.reply(message, true, actions...)
+}
+
+func (t *Thread) reply(message string, broadcast bool, actions ...Action) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	var opts []slack.MsgOption
+	if len(actions) != 0 {
+		opts = append(opts, actionBlocks(t.logger, message, actions...))
+	}
+	if t.timestamp != "" {
+		opts = append(opts, slack.MsgOptionTS(t.timestamp))
+		if broadcast {
+			opts = append(opts, slack.MsgOptionBroadcast())
+		}
+	}
+
+	t.client.post(t.logger, message, t.channel, opts...)
+}
@@ -0,0 +1,190 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/freshly/tuber/pkg/pubsub"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+func parse(sourceName string, r *http.Request, body []byte) ([]pubsub.Message, error) {
+	switch sourceName {
+	case Registry:
+		return parseRegistry(body)
+	case Harbor:
+		return parseHarbor(body)
+	case Github:
+		return parseGithub(r.Header.Get("X-GitHub-Event"), body)
+	}
+	return nil, fmt.Errorf("unknown webhook source %s", sourceName)
+}
+
+// message builds the same digest and tag references gcr publishes to pubsub
+func message(repository string, tag string, digest string) (pubsub.Message, error) {
+	repo, err := name.NewRepository(repository)
+	if err != nil {
+		return pubsub.Message{}, err
+	}
+	return pubsub.Message{
+		Digest: repo.Digest(digest).String(),
+		Tag:    repo.Tag(tag).String(),
+	}, nil
+}
+
+// https://docs.docker.com/registry/notifications/
+type registryNotification struct {
+	Events []struct {
+		Action string `json:"action"`
+		Target struct {
+			MediaType  string `json:"mediaType"`
+			Digest     string `json:"digest"`
+			Repository string `json:"repository"`
+			URL        string `json:"url"`
+			Tag        string `json:"tag"`
+		} `json:"target"`
+		Request struct {
+			Host string `json:"host"`
+		} `json:"request"`
+	} `json:"events"`
+}
+
+func parseRegistry(body []byte) ([]pubsub.Message, error) {
+	var notification registryNotification
+	if err := json.Unmarshal(body, &notification); err != nil {
+		return nil, err
+	}
+
+	var messages []pubsub.Message
+	for _, event := range notification.Events {
+		target := event.Target
+		// blob pushes and pushes by digest alone can't match an app's image tag
+		if event.Action != "push" || target.Tag == "" || !strings.Contains(target.MediaType, "manifest") {
+			continue
+		}
+
+		host := event.Request.Host
+		if target.URL != "" {
+			parsed, err := url.Parse(target.URL)
+			if err == nil && parsed.Host != "" {
+				host = parsed.Host
+			}
+		}
+		if host == "" {
+			return nil, fmt.Errorf("registry host missing for %s", target.Repository)
+		}
+
+		m, err := message(host+"/"+target.Repository, target.Tag, target.Digest)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+	return messages, nil
+}
+
+// https://goharbor.io/docs/main/working-with-projects/project-configuration/configure-webhooks/
+type harborNotification struct {
+	Type      string `json:"type"`
+	EventData struct {
+		Resources []struct {
+			Digest      string `json:"digest"`
+			Tag         string `json:"tag"`
+			ResourceURL string `json:"resource_url"`
+		} `json:"resources"`
+	} `json:"event_data"`
+}
+
+func parseHarbor(body []byte) ([]pubsub.Message, error) {
+	var notification harborNotification
+	if err := json.Unmarshal(body, &notification); err != nil {
+		return nil, err
+	}
+
+	if notification.Type != "PUSH_ARTIFACT" && notification.Type != "pushImage" {
+		return nil, nil
+	}
+
+	var messages []pubsub.Message
+	for _, resource := range notification.EventData.Resources {
+		if resource.Tag == "" {
+			continue
+		}
+		ref, err := name.ParseReference(resource.ResourceURL)
+		if err != nil {
+			return nil, err
+		}
+		m, err := message(ref.Context().String(), resource.Tag, resource.Digest)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+	return messages, nil
+}
+
+// https://docs.github.com/en/webhooks/webhook-events-and-payloads#package
+// https://docs.github.com/en/webhooks/webhook-events-and-payloads#registry_package
+// Both events describe the package the same way, package events under "package" and registry_package events under "registry_package".
+type githubPackageEvent struct {
+	Action          string        `json:"action"`
+	Package         githubPackage `json:"package"`
+	RegistryPackage githubPackage `json:"registry_package"`
+}
+
+type githubPackage struct {
+	PackageType    string `json:"package_type"`
+	PackageVersion struct {
+		Version           string `json:"version"`
+		PackageURL        string `json:"package_url"`
+		ContainerMetadata struct {
+			Tag struct {
+				Name   string `json:"name"`
+				Digest string `json:"digest"`
+			} `json:"tag"`
+		} `json:"container_metadata"`
+	} `json:"package_version"`
+}
+
+func parseGithub(eventType string, body []byte) ([]pubsub.Message, error) {
+	if eventType != "package" && eventType != "registry_package" {
+		return nil, nil
+	}
+
+	var event githubPackageEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, err
+	}
+
+	pkg := event.Package
+	if eventType == "registry_package" {
+		pkg = event.RegistryPackage
+	}
+	if event.Action != "published" || !strings.EqualFold(pkg.PackageType, "container") {
+		return nil, nil
+	}
+
+	tag := pkg.PackageVersion.ContainerMetadata.Tag
+	if tag.Name == "" {
+		return nil, nil
+	}
+
+	digest := tag.Digest
+	if digest == "" {
+		digest = pkg.PackageVersion.Version
+	}
+
+	ref, err := name.ParseReference(pkg.PackageVersion.PackageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := message(ref.Context().String(), tag.Name, digest)
+	if err != nil {
+		return nil, err
+	}
+	return []pubsub.Message{m}, nil
+}
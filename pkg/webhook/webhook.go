@@ -0,0 +1,156 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/freshly/tuber/pkg/pubsub"
+	"github.com/freshly/tuber/pkg/report"
+	"go.uber.org/zap"
+)
+
+const maxBodySize = 1 << 20
+
+// Sources tuber knows how to parse image push notifications from
+const (
+	Registry = "registry"
+	Harbor   = "harbor"
+	Github   = "github"
+)
+
+// SourceNames lists every supported source
+var SourceNames = []string{Registry, Harbor, Github}
+
+// Source configures how requests from one webhook source are authenticated.
+// A source with neither a secret nor a token is disabled.
+type Source struct {
+	// Secret verifies a hex hmac-sha256 of the request body, sent in SignatureHeader
+	Secret          string
+	SignatureHeader string
+	// Token is compared against the Authorization header, for registries that can't sign requests (registry, harbor)
+	Token string
+}
+
+func (s Source) enabled() bool {
+	return s.Secret != "" || s.Token != ""
+}
+
+// DefaultSignatureHeader is the header a source's signature is read from when one isn't configured
+func DefaultSignatureHeader(source string) string {
+	if source == Github {
+		return "X-Hub-Signature-256"
+	}
+	return "X-Tuber-Signature"
+}
+
+// Handler accepts image push notifications over http, normalises them to pubsub messages,
+// and hands them to the same processor the gcr subscription uses
+type Handler struct {
+	logger    *zap.Logger
+	processor pubsub.MessageProcessor
+	sources   map[string]Source
+	prefix    string
+}
+
+// NewHandler constructs a Handler serving each source at prefix + source name
+func NewHandler(logger *zap.Logger, processor pubsub.MessageProcessor, sources map[string]Source, prefix string) *Handler {
+	return &Handler{
+		logger:    logger.With(zap.String("context", "webhook")),
+		processor: processor,
+		sources:   sources,
+		prefix:    prefix,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sourceName := strings.Trim(strings.TrimPrefix(r.URL.Path, h.prefix), "/")
+	source, ok := h.sources[sourceName]
+	if !ok || !source.enabled() {
+		http.Error(w, "webhook source not configured", http.StatusNotFound)
+		return
+	}
+
+	logger := h.logger.With(zap.String("source", sourceName))
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, "unreadable body", http.StatusBadRequest)
+		return
+	}
+
+	if err = authenticate(sourceName, source, r, body); err != nil {
+		logger.Warn("webhook authentication failed", zap.Error(err))
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	messages, err := parse(sourceName, r, body)
+	if err != nil {
+		logger.Warn("failed to parse webhook", zap.Error(err))
+		report.Error(err, report.Scope{"context": "webhook parse", "source": sourceName})
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(messages) == 0 {
+		logger.Debug("webhook had no image pushes")
+	}
+
+	accepter, durable := h.processor.(pubsub.MessageAccepter)
+	for _, message := range messages {
+		logger.Debug("webhook image push received", zap.String("tag", message.Tag), zap.String("digest", message.Digest))
+		if !durable {
+			go h.processor.Process(message)
+			continue
+		}
+		// a failed accept gets a 5xx, so the sender retries
+		if err = accepter.Accept(message); err != nil {
+			logger.Error("failed to accept webhook message", zap.Error(err))
+			report.Error(err, report.Scope{"context": "webhook accept", "source": sourceName})
+			http.Error(w, "failed to store event", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func authenticate(sourceName string, source Source, r *http.Request, body []byte) error {
+	if source.Token != "" {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(source.Token)) != 1 {
+			return fmt.Errorf("authorization header mismatch")
+		}
+	}
+
+	if source.Secret != "" {
+		header := source.SignatureHeader
+		if header == "" {
+			header = DefaultSignatureHeader(sourceName)
+		}
+
+		signature, err := hex.DecodeString(strings.TrimPrefix(r.Header.Get(header), "sha256="))
+		if err != nil || len(signature) == 0 {
+			return fmt.Errorf("missing or malformed %s", header)
+		}
+
+		mac := hmac.New(sha256.New, []byte(source.Secret))
+		mac.Write(body)
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return fmt.Errorf("signature mismatch")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,239 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/freshly/tuber/pkg/pubsub"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+const digest = "sha256:17f4431497a07da98bc16e599ef9d38afb9817049b6e98b71b7e321b946a24d4"
+
+const registryPayload = `{"events": [
+	{"action": "push", "target": {"mediaType": "application/vnd.docker.distribution.manifest.v2+json", "digest": "` + digest + `",
+		"repository": "freshly/potatoes", "url": "https://registry.example.com/v2/freshly/potatoes/manifests/` + digest + `", "tag": "master"},
+		"request": {"host": "registry.example.com"}},
+	{"action": "push", "target": {"mediaType": "application/octet-stream", "digest": "` + digest + `", "repository": "freshly/potatoes"},
+		"request": {"host": "registry.example.com"}},
+	{"action": "pull", "target": {"mediaType": "application/vnd.docker.distribution.manifest.v2+json", "digest": "` + digest + `",
+		"repository": "freshly/potatoes", "tag": "master"}, "request": {"host": "registry.example.com"}}
+]}`
+
+const harborPayload = `{"type": "PUSH_ARTIFACT", "event_data": {"resources": [
+	{"digest": "` + digest + `", "tag": "master", "resource_url": "harbor.example.com/freshly/potatoes:master"}
+]}}`
+
+const githubPayload = `{"action": "published", "package": {"package_type": "CONTAINER", "package_version": {
+	"version": "` + digest + `", "package_url": "ghcr.io/freshly/potatoes:master",
+	"container_metadata": {"tag": {"name": "master", "digest": "` + digest + `"}}
+}}}`
+
+// githubRegistryPayload is a registry_package event as github sends it, trimmed of the sender, repository and organization
+const githubRegistryPayload = `{
+	"action": "published",
+	"registry_package": {
+		"id": 1234567,
+		"name": "potatoes",
+		"namespace": "freshly",
+		"ecosystem": "CONTAINER",
+		"package_type": "CONTAINER",
+		"html_url": "https://github.com/orgs/freshly/packages/container/package/potatoes",
+		"created_at": "2021-06-01T15:04:05Z",
+		"updated_at": "2021-06-01T15:04:05Z",
+		"owner": {"login": "freshly", "type": "Organization"},
+		"package_version": {
+			"id": 7654321,
+			"version": "` + digest + `",
+			"name": "` + digest + `",
+			"description": "",
+			"summary": "",
+			"manifest": "",
+			"html_url": "https://github.com/orgs/freshly/packages/container/potatoes/7654321",
+			"target_commitish": "master",
+			"target_oid": "0123456789abcdef0123456789abcdef01234567",
+			"created_at": "0001-01-01T00:00:00Z",
+			"updated_at": "0001-01-01T00:00:00Z",
+			"metadata": [],
+			"container_metadata": {
+				"tag": {"name": "master", "digest": "` + digest + `"},
+				"labels": {"description": "", "source": "", "revision": "", "image_url": "", "licenses": "", "all_labels": {}},
+				"manifest": {"digest": "` + digest + `", "media_type": "application/vnd.oci.image.manifest.v1+json", "uri": "", "size": 1234, "config": {}, "layers": []}
+			},
+			"package_files": [],
+			"package_url": "ghcr.io/freshly/potatoes:master",
+			"author": {"login": "freshly-ci", "type": "User"},
+			"source_url": "ghcr.io/freshly/potatoes:master",
+			"installation_command": "docker pull ghcr.io/freshly/potatoes:master"
+		},
+		"registry": {"about_url": "https://docs.github.com/packages/learn-github-packages/introduction-to-github-packages", "name": "GitHub CONTAINER registry", "type": "CONTAINER", "url": "https://ghcr.io/freshly", "vendor": "GitHub Inc"}
+	}
+}`
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   string
+		event    string
+		body     string
+		expected []pubsub.Message
+	}{
+		{
+			name:   "registry push",
+			source: Registry,
+			body:   registryPayload,
+			expected: []pubsub.Message{{
+				Digest: "registry.example.com/freshly/potatoes@" + digest,
+				Tag:    "registry.example.com/freshly/potatoes:master",
+			}},
+		},
+		{
+			name:   "harbor push",
+			source: Harbor,
+			body:   harborPayload,
+			expected: []pubsub.Message{{
+				Digest: "harbor.example.com/freshly/potatoes@" + digest,
+				Tag:    "harbor.example.com/freshly/potatoes:master",
+			}},
+		},
+		{
+			name:   "harbor delete",
+			source: Harbor,
+			body:   strings.Replace(harborPayload, "PUSH_ARTIFACT", "DELETE_ARTIFACT", 1),
+		},
+		{
+			name:   "github package published",
+			source: Github,
+			event:  "package",
+			body:   githubPayload,
+			expected: []pubsub.Message{{
+				Digest: "ghcr.io/freshly/potatoes@" + digest,
+				Tag:    "ghcr.io/freshly/potatoes:master",
+			}},
+		},
+		{
+			name:   "github registry package published",
+			source: Github,
+			event:  "registry_package",
+			body:   githubRegistryPayload,
+			expected: []pubsub.Message{{
+				Digest: "ghcr.io/freshly/potatoes@" + digest,
+				Tag:    "ghcr.io/freshly/potatoes:master",
+			}},
+		},
+		{
+			name:   "github ping",
+			source: Github,
+			event:  "ping",
+			body:   `{"zen": "hi"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			r.Header.Set("X-GitHub-Event", tc.event)
+
+			actual, err := parse(tc.source, r, []byte(tc.body))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+type recordingProcessor struct {
+	wg       sync.WaitGroup
+	messages []pubsub.Message
+}
+
+func (p *recordingProcessor) Process(message pubsub.Message) {
+	p.messages = append(p.messages, message)
+	p.wg.Done()
+}
+
+func sign(secret string, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestHandler(t *testing.T) {
+	sources := map[string]Source{
+		Github: {Secret: "shh"},
+		Harbor: {Token: "letmein"},
+	}
+
+	testCases := []struct {
+		name     string
+		path     string
+		headers  map[string]string
+		body     string
+		expected int
+	}{
+		{
+			name:     "valid github signature",
+			path:     "/tuber/webhooks/github",
+			headers:  map[string]string{"X-GitHub-Event": "package", "X-Hub-Signature-256": sign("shh", githubPayload)},
+			body:     githubPayload,
+			expected: http.StatusAccepted,
+		},
+		{
+			name:     "invalid github signature",
+			path:     "/tuber/webhooks/github",
+			headers:  map[string]string{"X-GitHub-Event": "package", "X-Hub-Signature-256": sign("wrong", githubPayload)},
+			body:     githubPayload,
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name:     "valid harbor token",
+			path:     "/tuber/webhooks/harbor",
+			headers:  map[string]string{"Authorization": "letmein"},
+			body:     harborPayload,
+			expected: http.StatusAccepted,
+		},
+		{
+			name:     "missing harbor token",
+			path:     "/tuber/webhooks/harbor",
+			body:     harborPayload,
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name:     "unconfigured source",
+			path:     "/tuber/webhooks/registry",
+			body:     registryPayload,
+			expected: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			processor := &recordingProcessor{}
+			if tc.expected == http.StatusAccepted {
+				processor.wg.Add(1)
+			}
+			handler := NewHandler(zap.NewNop(), processor, sources, "/tuber/webhooks/")
+
+			r := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+			for k, v := range tc.headers {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expected, w.Code)
+			processor.wg.Wait()
+			if tc.expected == http.StatusAccepted {
+				assert.Len(t, processor.messages, 1)
+			} else {
+				assert.Empty(t, processor.messages)
+			}
+		})
+	}
+}